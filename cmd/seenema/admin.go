@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/tmdb"
+)
+
+// runPersons implements `seenema persons list|add|set-password`
+func runPersons(args []string) error {
+	switch name, args := subcommand(args); name {
+	case "list":
+		return runPersonsList(args)
+	case "add":
+		return runPersonsAdd(args)
+	case "set-password":
+		return runPersonsSetPassword(args)
+	default:
+		fmt.Fprintln(os.Stderr, "usage: seenema persons list|add|set-password [arguments]")
+		return unknownSubcommand("persons", name)
+	}
+}
+
+func runPersonsList(args []string) error {
+	flags := flag.NewFlagSet("persons list", flag.ContinueOnError)
+	all := flags.Bool("all", false, "include archived persons")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	personRepo := repository.NewPersonRepository(pool)
+	var persons []*model.Person
+	if *all {
+		persons, err = personRepo.ListAll(ctx)
+	} else {
+		persons, err = personRepo.GetAll(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(persons)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INITIAL\tNAME\tID\tPASSWORD\tSTATUS")
+	for _, p := range persons {
+		password := "not set"
+		if p.HasPassword {
+			password = "set"
+		}
+		status := "active"
+		if p.IsArchived() {
+			status = "archived"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", p.Initial, p.Name, p.ID, password, status)
+	}
+	return tw.Flush()
+}
+
+func runPersonsAdd(args []string) error {
+	flags := flag.NewFlagSet("persons add", flag.ContinueOnError)
+	initial := flags.String("initial", "", "single character shown on rating badges (required)")
+	name := flags.String("name", "", "display name (required)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	input := model.CreatePersonInput{Name: strings.TrimSpace(*name)}
+	var ok bool
+	if input.Initial, ok = model.NormalizeInitial(*initial); !ok {
+		return errors.New("-initial must be a single character")
+	}
+	if input.Name == "" {
+		return errors.New("-name is required")
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	personRepo := repository.NewPersonRepository(pool)
+	existing, err := personRepo.GetByInitial(ctx, input.Initial)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("initial %s is already used by %s", input.Initial, existing.Name)
+	}
+
+	person, err := personRepo.Create(ctx, input)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("added %s (%s) with ID %s\n", person.Name, person.Initial, person.ID)
+	return nil
+}
+
+// runPersonsSetPassword sets a person's login password, read from the first
+// line of stdin or generated, and signs them out everywhere. It's how people
+// get their first password.
+func runPersonsSetPassword(args []string) error {
+	flags := flag.NewFlagSet("persons set-password", flag.ContinueOnError)
+	initial := flags.String("initial", "", "initial of the person (required)")
+	generate := flags.Bool("generate", false, "generate a password and print it instead of reading one from stdin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	normalized, ok := model.NormalizeInitial(*initial)
+	if !ok {
+		return errors.New("-initial must be a single character")
+	}
+
+	var password string
+	if *generate {
+		token, err := auth.NewToken()
+		if err != nil {
+			return err
+		}
+		password = token[:20]
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < auth.MinPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", auth.MinPasswordLength)
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	personRepo := repository.NewPersonRepository(pool)
+	person, err := personRepo.GetByInitial(ctx, normalized)
+	if err != nil {
+		return err
+	}
+	if person == nil {
+		return fmt.Errorf("no person with initial %s", normalized)
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := personRepo.SetPasswordHash(ctx, person.ID, hash); err != nil {
+		return err
+	}
+	revoked, err := repository.NewSessionRepository(pool).DeleteByPerson(ctx, person.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("set the password for %s and signed out %d sessions\n", person.Name, revoked)
+	if *generate {
+		fmt.Printf("password: %s\n", password)
+	}
+	return nil
+}
+
+// runGroups implements `seenema groups list`
+func runGroups(args []string) error {
+	name, args := subcommand(args)
+	if name != "list" {
+		fmt.Fprintln(os.Stderr, "usage: seenema groups list [arguments]")
+		return unknownSubcommand("groups", name)
+	}
+
+	flags := flag.NewFlagSet("groups list", flag.ContinueOnError)
+	all := flags.Bool("all", false, "include archived groups")
+	asJSON := flags.Bool("json", false, "print JSON instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	groups, err := repository.NewGroupRepository(pool).List(ctx, *all)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(groups)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NUMBER\tNAME\tDATES\tWATCHED\tSTATUS")
+	for _, g := range groups {
+		status := ""
+		switch {
+		case g.IsCurrent:
+			status = "current"
+		case g.IsArchived():
+			status = "archived"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d/%d\t%s\n", g.Number, g.DisplayName(), g.DateRange(), g.WatchedCount, g.EntryCount, status)
+	}
+	return tw.Flush()
+}
+
+// runEntries implements `seenema entries add`
+func runEntries(args []string) error {
+	name, args := subcommand(args)
+	if name != "add" {
+		fmt.Fprintln(os.Stderr, "usage: seenema entries add --tmdb-id ID [arguments]")
+		return unknownSubcommand("entries", name)
+	}
+
+	flags := flag.NewFlagSet("entries add", flag.ContinueOnError)
+	tmdbID := flags.Int("tmdb-id", 0, "TMDB ID of the movie to add (required)")
+	groupNumber := flags.Int("group", 0, "group to add it to (default the current group)")
+	pickedBy := flags.String("picked-by", "", "initial of the person who picked it")
+	notes := flags.String("notes", "", "notes on the entry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *tmdbID < 1 {
+		return errors.New("--tmdb-id is required")
+	}
+	if *groupNumber < 0 {
+		return errors.New("--group must be at least 1")
+	}
+
+	ctx := context.Background()
+	cfg, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	movieRepo := repository.NewMovieRepository(pool)
+	entryRepo := repository.NewEntryRepository(pool)
+	personRepo := repository.NewPersonRepository(pool)
+
+	input := model.CreateEntryInput{GroupNumber: *groupNumber}
+	if input.GroupNumber == 0 {
+		if input.GroupNumber, err = repository.NewGroupRepository(pool).GetCurrent(ctx); err != nil {
+			return err
+		}
+	}
+	if *notes != "" {
+		input.Notes = notes
+	}
+	if *pickedBy != "" {
+		initial, ok := model.NormalizeInitial(*pickedBy)
+		if !ok {
+			return errors.New("--picked-by must be a single initial")
+		}
+		person, err := personRepo.GetByInitial(ctx, initial)
+		if err != nil {
+			return err
+		}
+		if person == nil || person.IsArchived() {
+			return fmt.Errorf("no active person with initial %s", initial)
+		}
+		input.PickedByPersonID = &person.ID
+	}
+
+	movie, _, err := library.FindOrImportTMDBMovie(ctx, movieRepo, tmdb.NewClient(cfg.TMDBAPIKey), *tmdbID)
+	if err != nil {
+		if errors.Is(err, library.ErrTMDBMovieNotFound) {
+			return fmt.Errorf("TMDB has no movie with ID %d", *tmdbID)
+		}
+		return err
+	}
+	input.MovieID = movie.ID
+
+	existing, err := entryRepo.GetByMovieAndGroup(ctx, movie.ID, input.GroupNumber)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%s is already in group %d as entry %s", movieLabel(movie), input.GroupNumber, existing.ID)
+	}
+
+	entry, err := entryRepo.Create(ctx, input)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("added %s to group %d at position %d as entry %s\n", movieLabel(movie), entry.GroupNumber, entry.Position, entry.ID)
+	return nil
+}
+
+// subcommand splits a command's arguments into its subcommand and the rest
+func subcommand(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+// unknownSubcommand is the error for a missing or unrecognised subcommand,
+// after its usage has been printed
+func unknownSubcommand(command, name string) error {
+	switch name {
+	case "-h", "--help", "help":
+		return flag.ErrHelp
+	case "":
+		return fmt.Errorf("%s needs a subcommand", command)
+	default:
+		return fmt.Errorf("unknown %s command %q", command, name)
+	}
+}
+
+// movieLabel names a movie with its year for command output
+func movieLabel(movie *model.Movie) string {
+	if movie.ReleaseYear != nil {
+		return movie.Title + " (" + strconv.Itoa(*movie.ReleaseYear) + ")"
+	}
+	return movie.Title
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/drywaters/seenema/internal/backup"
+	"github.com/drywaters/seenema/internal/repository"
+)
+
+// runBackup implements `seenema backup`, writing a consistent snapshot of
+// the database to a zip archive
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	output := flags.String("o", "seenema-backup-"+time.Now().Format("2006-01-02")+".zip", "write the archive to this file, or - for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	repo := repository.NewBackupRepository(pool)
+
+	if *output == "-" {
+		buffered := bufio.NewWriter(os.Stdout)
+		if _, err := backup.Write(ctx, buffered, repo); err != nil {
+			return err
+		}
+		if err := buffered.Flush(); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		return nil
+	}
+
+	// Write beside the destination and rename, so a failed backup never
+	// replaces a good one
+	tmp := *output + ".partial"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	manifest, err := backup.Write(ctx, buffered, repo)
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := os.Rename(tmp, *output); err != nil {
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	rows := 0
+	for _, table := range manifest.Tables {
+		rows += table.Rows
+	}
+	fmt.Fprintf(os.Stderr, "backed up %d rows from %d tables at schema version %d to %s\n", rows, len(manifest.Tables), manifest.SchemaVersion, *output)
+	return nil
+}
+
+// runRestore implements `seenema restore`, loading an archive written by
+// `seenema backup` into the database
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: seenema restore <backup.zip>")
+		fmt.Fprintln(flags.Output(), "Restores into a migrated database, keeping IDs. Rows already matching the backup are left alone, so running it twice is safe.")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("restore needs exactly one backup file")
+	}
+
+	archive, err := zip.OpenReader(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer archive.Close()
+
+	// Check the archive before touching the database
+	if _, err := backup.ReadManifest(&archive.Reader); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	manifest, results, err := backup.Restore(ctx, &archive.Reader, repository.NewBackupRepository(pool))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "restored backup from %s (schema version %d)\n", manifest.CreatedAt.Local().Format("2006-01-02 15:04"), manifest.SchemaVersion)
+	for _, result := range results {
+		fmt.Fprintf(os.Stderr, "  %-22s %7d rows, %7d added or changed\n", result.Name, result.Rows, result.Changed)
+	}
+	return nil
+}
@@ -2,123 +2,96 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/signal"
-	"path/filepath"
-	"syscall"
-	"time"
 
-	"github.com/drywaters/seenema/internal/assets"
 	"github.com/drywaters/seenema/internal/config"
-	"github.com/drywaters/seenema/internal/repository"
-	"github.com/drywaters/seenema/internal/server"
-	"github.com/drywaters/seenema/internal/tmdb"
-	"github.com/drywaters/seenema/internal/ui/layout"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// command is a subcommand of the seenema binary
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists the subcommands in the order usage shows them
+var commands = []command{
+	{"serve", "run the web server (the default with no command)", runServe},
+	{"migrate", "apply, roll back or list database migrations: up, down or status", runMigrate},
+	{"persons", "list or add the people who rate movies, or set their passwords: list, add or set-password", runPersons},
+	{"groups", "list the groups movies are organised into", runGroups},
+	{"entries", "add a TMDB movie to a group: add --tmdb-id", runEntries},
+	{"export", "write the collection as CSV or JSON", runExport},
+	{"backup", "write a backup archive of the database", runBackup},
+	{"restore", "load a backup archive into the database", runRestore},
+}
+
 func main() {
-	if err := run(); err != nil {
-		slog.Error("application error", "error", err)
-		os.Exit(1)
+	name, args := "serve", []string(nil)
+	if len(os.Args) > 1 {
+		name, args = os.Args[1], os.Args[2:]
 	}
-}
 
-func run() error {
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
 	}
 
-	// Set up logging
-	logLevel := slog.LevelInfo
-	logLevels := map[string]slog.Level{
-		"debug": slog.LevelDebug,
-		"info":  slog.LevelInfo,
-		"warn":  slog.LevelWarn,
-		"error": slog.LevelError,
+	var run func(args []string) error
+	for _, c := range commands {
+		if c.name == name {
+			run = c.run
+		}
 	}
-	if level, ok := logLevels[cfg.LogLevel]; ok {
-		logLevel = level
+	if run == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
 	}
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
-	slog.SetDefault(logger)
-
-	slog.Info("starting seenema", "port", cfg.Port)
 
-	// Connect to database
-	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	if err := run(args); err != nil {
+		// -h on a subcommand has already printed its usage
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		slog.Error("application error", "error", err)
+		os.Exit(1)
 	}
-	defer pool.Close()
+}
 
-	// Verify database connection
-	if err := pool.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+// usage lists the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: seenema [command] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
 	}
-	slog.Info("connected to database")
-
-	// Initialize repositories
-	movieRepo := repository.NewMovieRepository(pool)
-	entryRepo := repository.NewEntryRepository(pool)
-	personRepo := repository.NewPersonRepository(pool)
-	ratingRepo := repository.NewRatingRepository(pool)
-
-	// Initialize TMDB client
-	tmdbClient := tmdb.NewClient(cfg.TMDBAPIKey)
-	slog.Info("TMDB client initialized")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run seenema <command> -h for a command's arguments.")
+}
 
-	assetsVersion, err := assets.Version(
-		filepath.Join("static", "styles.css"),
-		filepath.Join("static", "dragdrop.js"),
-		filepath.Join("static", "rating.js"),
-		filepath.Join("static", "htmx.min.js"),
-	)
+// connectDatabase opens a pool for the one-off commands, returning the
+// configuration it was opened with
+func connectDatabase(ctx context.Context) (*config.Config, *pgxpool.Pool, error) {
+	cfg, err := config.Load()
 	if err != nil {
-		slog.Warn("asset version unavailable", "error", err)
-	} else {
-		layout.SetAssetsVersion(assetsVersion)
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Create server
-	srv := server.New(cfg, movieRepo, entryRepo, personRepo, ratingRepo, tmdbClient)
-
-	// Start HTTP server
-	httpServer := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      srv.Router(),
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-
-	// Graceful shutdown
-	shutdownChan := make(chan os.Signal, 1)
-	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		slog.Info("server listening", "addr", httpServer.Addr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			slog.Error("server error", "error", err)
-		}
-	}()
-
-	<-shutdownChan
-	slog.Info("shutting down...")
-
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("server shutdown error: %w", err)
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-
-	slog.Info("server stopped")
-	return nil
+	return cfg, pool, nil
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/drywaters/seenema/internal/migrate"
+	"github.com/drywaters/seenema/migrations"
+)
+
+// runMigrate implements `seenema migrate`, applying the migrations built
+// into the binary
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: seenema migrate up|down|status")
+		fmt.Fprintln(flags.Output(), "  up      apply every pending migration")
+		fmt.Fprintln(flags.Output(), "  down    roll back the newest applied migration")
+		fmt.Fprintln(flags.Output(), "  status  list migrations and when each was applied")
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("migrate needs one of up, down or status")
+	}
+
+	ctx := context.Background()
+	_, pool, err := connectDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := migrate.New(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %s\n", migration.Source)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Printf("database is up to date at version %d\n", migrator.Latest())
+		}
+	case "down":
+		migration, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Println("no migrations to roll back")
+			return nil
+		}
+		fmt.Printf("rolled back %s\n", migration.Source)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "APPLIED AT\tMIGRATION")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", appliedAt, status.Migration.Source)
+		}
+		return tw.Flush()
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate command %q", flags.Arg(0))
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/drywaters/seenema/internal/assets"
+	"github.com/drywaters/seenema/internal/config"
+	"github.com/drywaters/seenema/internal/jobs"
+	"github.com/drywaters/seenema/internal/migrate"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/server"
+	"github.com/drywaters/seenema/internal/tmdb"
+	"github.com/drywaters/seenema/internal/ui/layout"
+	"github.com/drywaters/seenema/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runServe implements `seenema serve`, running the web server until it's
+// interrupted
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Set up logging
+	logLevel := slog.LevelInfo
+	logLevels := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	if level, ok := logLevels[cfg.LogLevel]; ok {
+		logLevel = level
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+	slog.SetDefault(logger)
+
+	slog.Info("starting seenema", "port", cfg.Port)
+
+	// Connect to database
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	// Verify database connection
+	if err := pool.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	slog.Info("connected to database")
+
+	if err := migrateDatabase(ctx, pool, cfg.AutoMigrate); err != nil {
+		return err
+	}
+
+	// Initialize repositories
+	movieRepo := repository.NewMovieRepository(pool)
+	entryRepo := repository.NewEntryRepository(pool)
+	groupRepo := repository.NewGroupRepository(pool)
+	personRepo := repository.NewPersonRepository(pool)
+	ratingRepo := repository.NewRatingRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	apiTokenRepo := repository.NewAPITokenRepository(pool)
+	statsRepo := repository.NewStatsRepository(pool)
+	trashRepo := repository.NewTrashRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	viewingRepo := repository.NewViewingRepository(pool)
+	watchlistRepo := repository.NewWatchlistRepository(pool)
+	exportRepo := repository.NewExportRepository(pool)
+
+	// Initialize TMDB client
+	tmdbClient := tmdb.NewClient(cfg.TMDBAPIKey)
+	slog.Info("TMDB client initialized")
+
+	assetsVersion, err := assets.Version(
+		filepath.Join("static", "styles.css"),
+		filepath.Join("static", "dragdrop.js"),
+		filepath.Join("static", "rating.js"),
+		filepath.Join("static", "htmx.min.js"),
+	)
+	if err != nil {
+		slog.Warn("asset version unavailable", "error", err)
+	} else {
+		layout.SetAssetsVersion(assetsVersion)
+	}
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	go jobs.Every(jobsCtx, "sweep expired sessions", time.Hour, func(ctx context.Context) error {
+		count, err := sessionRepo.DeleteExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			slog.Info("swept expired sessions", "count", count)
+		}
+		return nil
+	})
+
+	if cfg.TrashRetention > 0 {
+		go jobs.Every(jobsCtx, "purge trash", time.Hour, func(ctx context.Context) error {
+			count, err := trashRepo.Purge(ctx, time.Now().Add(-cfg.TrashRetention))
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				slog.Info("purged trash", "count", count)
+			}
+			return nil
+		})
+	}
+
+	// Create server
+	srv := server.New(cfg, movieRepo, entryRepo, groupRepo, personRepo, ratingRepo, sessionRepo, apiTokenRepo, statsRepo, trashRepo, auditRepo, viewingRepo, watchlistRepo, exportRepo, tmdbClient)
+
+	// Start HTTP server
+	httpServer := &http.Server{
+		Addr:         ":" + cfg.Port,
+		Handler:      srv.Router(),
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Graceful shutdown
+	shutdownChan := make(chan os.Signal, 1)
+	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		slog.Info("server listening", "addr", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("server error", "error", err)
+		}
+	}()
+
+	<-shutdownChan
+	slog.Info("shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown error: %w", err)
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
+
+// migrateDatabase applies pending migrations, or with autoMigrate off only
+// warns about them. Either way it refuses to serve a database migrated by a
+// newer release, whose schema this binary's queries may not match.
+func migrateDatabase(ctx context.Context, pool *pgxpool.Pool, autoMigrate bool) error {
+	migrator, err := migrate.New(pool, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	if !autoMigrate {
+		if err := migrator.CheckVersion(ctx); err != nil {
+			slog.Error("refusing to start: the database was migrated by a newer release of seenema", "error", err)
+			return err
+		}
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get schema version: %w", err)
+		}
+		if version < migrator.Latest() {
+			slog.Warn("database has pending migrations; run seenema migrate up", "version", version, "latest", migrator.Latest())
+		}
+		return nil
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		slog.Info("applied migration", "migration", migration.Source)
+	}
+	if errors.Is(err, migrate.ErrSchemaTooNew) {
+		slog.Error("refusing to start: the database was migrated by a newer release of seenema", "error", err)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	slog.Info("database schema is up to date", "version", migrator.Latest())
+	return nil
+}
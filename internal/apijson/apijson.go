@@ -0,0 +1,47 @@
+// Package apijson writes the JSON API's responses. The handlers and the
+// middleware in front of them share it, so every API error has the same body
+// and the OpenAPI spec describes it from one type.
+package apijson
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorResponse is the body of every JSON API error
+type ErrorResponse struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes a JSON API error
+type ErrorDetail struct {
+	Code    string `json:"code"`    // Machine-readable, e.g. "not_found"
+	Message string `json:"message"` // Human-readable
+}
+
+// Error codes used in JSON API error bodies
+const (
+	CodeBadRequest           = "bad_request"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeConflict             = "conflict"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeInternal             = "internal_error"
+)
+
+// Write writes v as a JSON response with the given status code
+func Write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode JSON response", "error", err)
+	}
+}
+
+// WriteError writes a JSON API error body with the given status code
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	Write(w, status, ErrorResponse{Error: ErrorDetail{Code: code, Message: message}})
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionCookieName is the name of the browser session cookie
+const SessionCookieName = "seenema_session"
+
+// MinPasswordLength is the shortest password accepted for a person login
+const MinPasswordLength = 8
+
+type contextKey string
+
+const (
+	personContextKey    contextKey = "person"
+	sessionContextKey   contextKey = "session"
+	apiTokenContextKey  contextKey = "api_token"
+	householdContextKey contextKey = "household_token"
+)
+
+// WithPerson returns a copy of ctx carrying the authenticated person
+func WithPerson(ctx context.Context, person *model.Person) context.Context {
+	return context.WithValue(ctx, personContextKey, person)
+}
+
+// PersonFromContext returns the authenticated person, or nil when the request
+// was authenticated without a person (e.g. the master API token)
+func PersonFromContext(ctx context.Context) *model.Person {
+	person, _ := ctx.Value(personContextKey).(*model.Person)
+	return person
+}
+
+// WithSessionID returns a copy of ctx carrying the current browser session ID
+func WithSessionID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, sessionContextKey, id)
+}
+
+// SessionIDFromContext returns the current browser session ID, or uuid.Nil
+// when the request was not authenticated with a session cookie
+func SessionIDFromContext(ctx context.Context) uuid.UUID {
+	id, _ := ctx.Value(sessionContextKey).(uuid.UUID)
+	return id
+}
+
+// WithAPIToken returns a copy of ctx carrying the scoped API token used for the request
+func WithAPIToken(ctx context.Context, token *model.APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenContextKey, token)
+}
+
+// APITokenFromContext returns the scoped API token used for the request, or nil
+// for browser sessions and the master API token (which has every scope)
+func APITokenFromContext(ctx context.Context) *model.APIToken {
+	token, _ := ctx.Value(apiTokenContextKey).(*model.APIToken)
+	return token
+}
+
+// WithHouseholdToken returns a copy of ctx marking the request as made with the master API token
+func WithHouseholdToken(ctx context.Context) context.Context {
+	return context.WithValue(ctx, householdContextKey, true)
+}
+
+// IsHouseholdToken reports whether the request was made with the master API token
+func IsHouseholdToken(ctx context.Context) bool {
+	household, _ := ctx.Value(householdContextKey).(bool)
+	return household
+}
+
+// HashPassword returns a bcrypt hash of the password
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches the bcrypt hash
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewToken returns a random URL-safe token with 256 bits of entropy
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 digest stored in place of a raw token
+func HashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// ConstantTimeEqual performs a constant-time comparison to prevent timing attacks.
+func ConstantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle counts failed attempts per key, such as a person or an address,
+// and blocks a key once it has failed too often within a window. Counts are
+// kept in memory, so they reset when the server restarts.
+type Throttle struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+// NewThrottle creates a Throttle allowing limit failures per key within window
+func NewThrottle(limit int, window time.Duration) *Throttle {
+	return &Throttle{
+		limit:    limit,
+		window:   window,
+		now:      time.Now,
+		failures: make(map[string][]time.Time),
+	}
+}
+
+// Allowed reports whether key may make another attempt
+func (t *Throttle) Allowed(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.recent(key)) < t.limit
+}
+
+// Fail records a failed attempt for key
+func (t *Throttle) Fail(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failures[key] = append(t.recent(key), t.now())
+}
+
+// Reset forgets key's failures, after it succeeds
+func (t *Throttle) Reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, key)
+}
+
+// recent drops key's failures that have left the window and returns the rest.
+// The caller must hold t.mu.
+func (t *Throttle) recent(key string) []time.Time {
+	failures := t.failures[key]
+	cutoff := t.now().Add(-t.window)
+	kept := failures[:0]
+	for _, at := range failures {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	if len(kept) == 0 {
+		delete(t.failures, key)
+		return nil
+	}
+	t.failures[key] = kept
+	return kept
+}
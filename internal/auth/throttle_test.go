@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := NewThrottle(3, 10*time.Minute)
+	throttle.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if !throttle.Allowed("a") {
+			t.Fatalf("attempt %d blocked, want allowed", i+1)
+		}
+		throttle.Fail("a")
+	}
+	if throttle.Allowed("a") {
+		t.Fatal("fourth attempt allowed, want blocked")
+	}
+	if !throttle.Allowed("b") {
+		t.Fatal("other key blocked, want allowed")
+	}
+
+	now = now.Add(10 * time.Minute)
+	if !throttle.Allowed("a") {
+		t.Fatal("attempt after the window blocked, want allowed")
+	}
+
+	throttle.Fail("a")
+	throttle.Fail("a")
+	throttle.Reset("a")
+	throttle.Fail("a")
+	throttle.Fail("a")
+	if !throttle.Allowed("a") {
+		t.Fatal("attempt after reset blocked, want allowed")
+	}
+}
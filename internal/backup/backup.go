@@ -0,0 +1,217 @@
+// Package backup writes and restores a versioned archive of the collection:
+// a zip holding one JSON-lines file per table and a manifest describing them
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/drywaters/seenema/internal/repository"
+)
+
+// Archive format identifiers, checked before anything is restored
+const (
+	FormatName    = "seenema-backup"
+	FormatVersion = 1
+)
+
+const manifestFile = "manifest.json"
+
+// maxLineBytes caps a single backed-up row, which for movies includes the
+// full TMDB metadata
+const maxLineBytes = 16 << 20
+
+// ErrNotBackup is returned when an archive has no seenema manifest
+var ErrNotBackup = errors.New("not a seenema backup")
+
+// Manifest describes an archive's contents
+type Manifest struct {
+	Format        string      `json:"format"`
+	Version       int         `json:"version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	SchemaVersion int64       `json:"schema_version"`
+	Tables        []TableFile `json:"tables"`
+}
+
+// TableFile is one table's file within an archive
+type TableFile struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Rows int    `json:"rows"`
+}
+
+// TableResult is what restoring one table did
+type TableResult struct {
+	Name    string
+	Rows    int
+	Changed int64
+}
+
+// Write streams a consistent snapshot of every backup table to w as a zip
+// archive, with the manifest written last
+func Write(ctx context.Context, w io.Writer, repo *repository.BackupRepository) (*Manifest, error) {
+	manifest := &Manifest{
+		Format:    FormatName,
+		Version:   FormatVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	zw := zip.NewWriter(w)
+	err := repo.Snapshot(ctx, func(snapshot *repository.BackupSnapshot) error {
+		version, err := snapshot.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		manifest.SchemaVersion = version
+
+		for _, name := range repository.BackupTableNames() {
+			table := TableFile{Name: name, File: name + ".jsonl"}
+			file, err := zw.CreateHeader(&zip.FileHeader{Name: table.File, Method: zip.Deflate, Modified: manifest.CreatedAt})
+			if err != nil {
+				return fmt.Errorf("create %s: %w", table.File, err)
+			}
+
+			buffered := bufio.NewWriter(file)
+			err = snapshot.EachRow(ctx, name, func(row json.RawMessage) error {
+				table.Rows++
+				if _, err := buffered.Write(row); err != nil {
+					return err
+				}
+				return buffered.WriteByte('\n')
+			})
+			if err != nil {
+				return fmt.Errorf("back up %s: %w", name, err)
+			}
+			if err := buffered.Flush(); err != nil {
+				return fmt.Errorf("write %s: %w", table.File, err)
+			}
+			manifest.Tables = append(manifest.Tables, table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := zw.CreateHeader(&zip.FileHeader{Name: manifestFile, Method: zip.Deflate, Modified: manifest.CreatedAt})
+	if err != nil {
+		return nil, fmt.Errorf("create manifest: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("finish archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// ReadManifest returns an archive's manifest, checking it is a backup this
+// binary can restore
+func ReadManifest(archive *zip.Reader) (*Manifest, error) {
+	file, err := archive.Open(manifestFile)
+	if err != nil {
+		return nil, ErrNotBackup
+	}
+	defer file.Close()
+
+	var manifest Manifest
+	if err := json.NewDecoder(file).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	if manifest.Format != FormatName {
+		return nil, ErrNotBackup
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("backup format version %d is not supported; this binary reads up to version %d", manifest.Version, FormatVersion)
+	}
+	return &manifest, nil
+}
+
+// Restore writes an archive's rows into the database in one transaction,
+// keeping their IDs. Rows that already match are left alone, so restoring
+// into a database that has some or all of the backup is safe. The database
+// must be migrated at least as far as the one the backup was taken from.
+func Restore(ctx context.Context, archive *zip.Reader, repo *repository.BackupRepository) (*Manifest, []TableResult, error) {
+	manifest, err := ReadManifest(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make(map[string]TableFile, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		files[table.Name] = table
+	}
+
+	var results []TableResult
+	err = repo.Restore(ctx, func(restorer *repository.BackupRestorer) error {
+		version, err := restorer.SchemaVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if version < manifest.SchemaVersion {
+			return fmt.Errorf("backup was taken at schema version %d but the database is at %d: run the migrations first", manifest.SchemaVersion, version)
+		}
+
+		// Tables are restored in dependency order, whatever order the
+		// manifest lists them in. Tables newer than the backup are skipped.
+		for _, name := range repository.BackupTableNames() {
+			table, ok := files[name]
+			if !ok {
+				continue
+			}
+			rows, err := readRows(archive, table)
+			if err != nil {
+				return err
+			}
+			changed, err := restorer.RestoreTable(ctx, name, rows)
+			if err != nil {
+				return err
+			}
+			results = append(results, TableResult{Name: name, Rows: len(rows), Changed: changed})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest, results, nil
+}
+
+// readRows reads a table's JSON lines, checking the count against the manifest
+func readRows(archive *zip.Reader, table TableFile) ([]json.RawMessage, error) {
+	file, err := archive.Open(table.File)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", table.File, err)
+	}
+	defer file.Close()
+
+	rows := make([]json.RawMessage, 0, table.Rows)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, fmt.Errorf("%s line %d is not valid JSON", table.File, len(rows)+1)
+		}
+		rows = append(rows, json.RawMessage(append([]byte(nil), line...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", table.File, err)
+	}
+	if len(rows) != table.Rows {
+		return nil, fmt.Errorf("%s has %d rows but the manifest lists %d; the archive may be truncated", table.File, len(rows), table.Rows)
+	}
+	return rows, nil
+}
@@ -4,7 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/model"
 )
 
 // Config holds all application configuration
@@ -15,6 +19,15 @@ type Config struct {
 	TMDBAPIKey    string
 	LogLevel      string
 	SecureCookies bool
+
+	// AutoMigrate applies pending database migrations when the server starts
+	AutoMigrate bool
+
+	// PickerRotation decides whose turn it is to pick the next movie
+	PickerRotation model.PickerRotation
+
+	// TrashRetention is how long deleted movies, entries and ratings can be restored; 0 keeps them forever
+	TrashRetention time.Duration
 }
 
 // Load reads configuration from environment variables.
@@ -47,6 +60,33 @@ func Load() (*Config, error) {
 	}
 	cfg.SecureCookies = secureCookiesStr != "false"
 
+	// Migrations run at startup by default, set AUTO_MIGRATE=false to apply them with `seenema migrate up` instead
+	autoMigrateStr, err := getEnv("AUTO_MIGRATE", "true")
+	if err != nil {
+		return nil, err
+	}
+	cfg.AutoMigrate = autoMigrateStr != "false"
+
+	// Strict round-robin by default, set PICKER_ROTATION=weighted to favour whoever has picked least in the group
+	rotationStr, err := getEnv("PICKER_ROTATION", string(model.PickerRotationRoundRobin))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PickerRotation, err = model.ParsePickerRotation(rotationStr); err != nil {
+		return nil, fmt.Errorf("PICKER_ROTATION: %w", err)
+	}
+
+	// Deleted items are purged after 30 days by default, set TRASH_RETENTION_DAYS=0 to keep them forever
+	retentionStr, err := getEnv("TRASH_RETENTION_DAYS", "30")
+	if err != nil {
+		return nil, err
+	}
+	retentionDays, err := strconv.Atoi(retentionStr)
+	if err != nil || retentionDays < 0 {
+		return nil, fmt.Errorf("TRASH_RETENTION_DAYS: must be a whole number of days, got %q", retentionStr)
+	}
+	cfg.TrashRetention = time.Duration(retentionDays) * 24 * time.Hour
+
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
@@ -0,0 +1,178 @@
+// Package export writes the whole collection as CSV or JSON for spreadsheets
+// and other tools
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+)
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// dateLayout is how watched dates are written
+const dateLayout = "2006-01-02"
+
+// IsValidFormat returns true if format is one Write understands
+func IsValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON
+}
+
+// Write streams every entry to w as CSV or JSON, with a score for each of persons
+func Write(ctx context.Context, w io.Writer, format string, repo *repository.ExportRepository, persons []*model.Person) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(ctx, w, repo, persons)
+	case FormatJSON:
+		return writeJSON(ctx, w, repo, persons)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// writeCSV writes a header row, then one row per entry with a score column per person
+func writeCSV(ctx context.Context, w io.Writer, repo *repository.ExportRepository, persons []*model.Person) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"entry_id", "title", "year", "tmdb_id", "imdb_id", "group", "group_name", "position", "watched_at", "picked_by", "notes"}
+	for _, p := range persons {
+		header = append(header, p.Name)
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	err := repo.Each(ctx, func(row *model.ExportRow) error {
+		record := []string{
+			row.EntryID.String(),
+			row.Title,
+			optionalInt(row.ReleaseYear),
+			optionalInt(row.TMDBId),
+			optionalString(row.IMDBId),
+			strconv.Itoa(row.GroupNumber),
+			optionalString(row.GroupName),
+			strconv.Itoa(row.Position),
+			"",
+			optionalString(row.PickedBy),
+			optionalString(row.Notes),
+		}
+		if row.WatchedAt != nil {
+			record[8] = row.WatchedAt.Format(dateLayout)
+		}
+		for _, p := range persons {
+			if score, ok := row.Scores[p.ID]; ok {
+				record = append(record, formatScore(score))
+			} else {
+				record = append(record, "")
+			}
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
+
+// jsonRow is an ExportRow as written to a JSON export
+type jsonRow struct {
+	EntryID     string             `json:"entry_id"`
+	Title       string             `json:"title"`
+	ReleaseYear *int               `json:"year"`
+	TMDBId      *int               `json:"tmdb_id"`
+	IMDBId      *string            `json:"imdb_id"`
+	GroupNumber int                `json:"group"`
+	GroupName   *string            `json:"group_name"`
+	Position    int                `json:"position"`
+	WatchedAt   *string            `json:"watched_at"`
+	PickedBy    *string            `json:"picked_by"`
+	Notes       *string            `json:"notes"`
+	Scores      map[string]float64 `json:"scores"` // By person name
+}
+
+// writeJSON writes a JSON array with one object per entry, one element at a time
+func writeJSON(ctx context.Context, w io.Writer, repo *repository.ExportRepository, persons []*model.Person) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+
+	separator := "\n"
+	err := repo.Each(ctx, func(row *model.ExportRow) error {
+		out := jsonRow{
+			EntryID:     row.EntryID.String(),
+			Title:       row.Title,
+			ReleaseYear: row.ReleaseYear,
+			TMDBId:      row.TMDBId,
+			IMDBId:      row.IMDBId,
+			GroupNumber: row.GroupNumber,
+			GroupName:   row.GroupName,
+			Position:    row.Position,
+			PickedBy:    row.PickedBy,
+			Notes:       row.Notes,
+			Scores:      map[string]float64{},
+		}
+		if row.WatchedAt != nil {
+			watched := row.WatchedAt.Format(dateLayout)
+			out.WatchedAt = &watched
+		}
+		for _, p := range persons {
+			if score, ok := row.Scores[p.ID]; ok {
+				out.Scores[p.Name] = score
+			}
+		}
+
+		data, err := json.Marshal(out)
+		if err != nil {
+			return fmt.Errorf("encode export row: %w", err)
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		separator = ",\n"
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, "\n]\n"); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+	return nil
+}
+
+func optionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+func optionalString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/tmdb"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// APIHandler serves the versioned JSON API under /api/v1.
+// Every response is JSON; errors use apijson.ErrorResponse.
+type APIHandler struct {
+	movieRepo     *repository.MovieRepository
+	entryRepo     *repository.EntryRepository
+	groupRepo     *repository.GroupRepository
+	personRepo    *repository.PersonRepository
+	ratingRepo    *repository.RatingRepository
+	trashRepo     *repository.TrashRepository
+	viewingRepo   *repository.ViewingRepository
+	watchlistRepo *repository.WatchlistRepository
+	tmdbClient    *tmdb.Client
+
+	pickerRotation model.PickerRotation
+	trashRetention time.Duration
+}
+
+// NewAPIHandler creates a new APIHandler
+func NewAPIHandler(
+	movieRepo *repository.MovieRepository,
+	entryRepo *repository.EntryRepository,
+	groupRepo *repository.GroupRepository,
+	personRepo *repository.PersonRepository,
+	ratingRepo *repository.RatingRepository,
+	trashRepo *repository.TrashRepository,
+	viewingRepo *repository.ViewingRepository,
+	watchlistRepo *repository.WatchlistRepository,
+	tmdbClient *tmdb.Client,
+	pickerRotation model.PickerRotation,
+	trashRetention time.Duration,
+) *APIHandler {
+	return &APIHandler{
+		movieRepo:      movieRepo,
+		entryRepo:      entryRepo,
+		groupRepo:      groupRepo,
+		personRepo:     personRepo,
+		ratingRepo:     ratingRepo,
+		trashRepo:      trashRepo,
+		viewingRepo:    viewingRepo,
+		watchlistRepo:  watchlistRepo,
+		tmdbClient:     tmdbClient,
+		pickerRotation: pickerRotation,
+		trashRetention: trashRetention,
+	}
+}
+
+// NotFound is the JSON 404 for unknown API routes
+func (h *APIHandler) NotFound(w http.ResponseWriter, r *http.Request) {
+	apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "No such API endpoint")
+}
+
+// MethodNotAllowed is the JSON 405 for known API routes with the wrong method
+func (h *APIHandler) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	apijson.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+}
+
+// uuidParam parses a UUID URL parameter, writing a 400 if it is malformed
+func uuidParam(w http.ResponseWriter, r *http.Request, name, label string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Invalid "+label)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// groupParam parses the {num} group URL parameter, writing a 400 if it is malformed
+func groupParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	groupNum, err := strconv.Atoi(chi.URLParam(r, "num"))
+	if err != nil || groupNum < 1 {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Invalid group number")
+		return 0, false
+	}
+	return groupNum, true
+}
+
+// readBody decodes a JSON request body into v, writing a 400 on failure.
+// An empty body is allowed when optional is true.
+func readBody(w http.ResponseWriter, r *http.Request, v any, optional bool) bool {
+	err := decodeJSON(r, v)
+	if err == nil || (optional && errors.Is(err, io.EOF)) {
+		return true
+	}
+	apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Invalid JSON body: "+err.Error())
+	return false
+}
@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/google/uuid"
+)
+
+// CreateEntryRequest is the body for adding a movie to a group.
+// Exactly one of movie_id or tmdb_id must be set; a tmdb_id not yet in the
+// library is imported first. assign_next_picker credits the pick to whoever's
+// turn it is and can't be combined with picked_by_person_id.
+type CreateEntryRequest struct {
+	MovieID          *uuid.UUID `json:"movie_id"`
+	TMDBId           *int       `json:"tmdb_id"`
+	GroupNumber      *int       `json:"group_number"` // nil = current group
+	Notes            *string    `json:"notes"`
+	PickedByPersonID *uuid.UUID `json:"picked_by_person_id"`
+	AssignNextPicker bool       `json:"assign_next_picker"`
+}
+
+// UpdateEntryRequest is the body for changing an entry. Omitted fields are
+// left unchanged; picked_by_person_id may be null to clear the picker.
+type UpdateEntryRequest struct {
+	GroupNumber      *int            `json:"group_number"`
+	Notes            *string         `json:"notes"`
+	PickedByPersonID json.RawMessage `json:"picked_by_person_id"`
+}
+
+// MoveEntryRequest is the body for moving or copying an entry to a group
+type MoveEntryRequest struct {
+	GroupNumber int  `json:"group_number"`
+	Position    *int `json:"position"` // 1-based; nil = end of the group, or unchanged for a move within the same group
+}
+
+// SetWatchedRequest is the optional body for marking an entry watched
+type SetWatchedRequest struct {
+	WatchedAt string `json:"watched_at"` // YYYY-MM-DD, defaults to today
+}
+
+// GetEntry returns a single entry with its movie and ratings
+func (h *APIHandler) GetEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// CreateEntry adds a movie to a group
+func (h *APIHandler) CreateEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateEntryRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+	if (req.MovieID == nil) == (req.TMDBId == nil) {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Exactly one of movie_id or tmdb_id is required")
+		return
+	}
+
+	input := model.CreateEntryInput{
+		Notes:            req.Notes,
+		PickedByPersonID: req.PickedByPersonID,
+	}
+
+	if req.GroupNumber != nil {
+		if *req.GroupNumber < 1 {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "group_number must be at least 1")
+			return
+		}
+		input.GroupNumber = *req.GroupNumber
+	} else {
+		currentGroup, err := h.groupRepo.GetCurrent(ctx)
+		if err != nil {
+			writeInternalError(w, "failed to get current group", err)
+			return
+		}
+		input.GroupNumber = currentGroup
+	}
+
+	if req.PickedByPersonID != nil && req.AssignNextPicker {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "picked_by_person_id and assign_next_picker can't both be set")
+		return
+	}
+	if req.PickedByPersonID != nil && !h.personExists(w, r, *req.PickedByPersonID) {
+		return
+	}
+
+	if req.TMDBId != nil {
+		movie, _, err := library.FindOrImportTMDBMovie(ctx, h.movieRepo, h.tmdbClient, *req.TMDBId)
+		if err != nil {
+			if errors.Is(err, library.ErrTMDBMovieNotFound) {
+				apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "No TMDB movie with that tmdb_id")
+				return
+			}
+			writeInternalError(w, "failed to import TMDB movie", err)
+			return
+		}
+		input.MovieID = movie.ID
+	} else {
+		movie, err := h.movieRepo.GetByID(ctx, *req.MovieID)
+		if err != nil {
+			writeInternalError(w, "failed to get movie", err)
+			return
+		}
+		if movie == nil {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "No movie with that movie_id")
+			return
+		}
+		input.MovieID = movie.ID
+	}
+
+	if req.AssignNextPicker {
+		turn, err := nextPicker(ctx, h.entryRepo, h.personRepo, h.pickerRotation, input.GroupNumber)
+		if err != nil {
+			writeInternalError(w, "failed to work out next picker", err)
+			return
+		}
+		if turn.Person != nil {
+			input.PickedByPersonID = &turn.Person.ID
+		}
+	}
+
+	created, err := h.entryRepo.Create(ctx, input)
+	if err != nil {
+		if status, code, message, ok := placementError(err); ok {
+			apijson.WriteError(w, status, code, message)
+			return
+		}
+		writeInternalError(w, "failed to create entry", err)
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, created.ID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusCreated, entry)
+}
+
+// UpdateEntry changes an entry's group, notes or picker
+func (h *APIHandler) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	var req UpdateEntryRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	input := model.UpdateEntryInput{
+		GroupNumber: req.GroupNumber,
+		Notes:       req.Notes,
+	}
+	if input.GroupNumber != nil && *input.GroupNumber < 1 {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "group_number must be at least 1")
+		return
+	}
+
+	// The repository treats uuid.Nil as "clear the picker"
+	if len(req.PickedByPersonID) > 0 {
+		if bytes.Equal(req.PickedByPersonID, []byte("null")) {
+			nilID := uuid.Nil
+			input.PickedByPersonID = &nilID
+		} else {
+			var pickedByID uuid.UUID
+			if err := json.Unmarshal(req.PickedByPersonID, &pickedByID); err != nil || pickedByID == uuid.Nil {
+				apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Invalid picked_by_person_id")
+				return
+			}
+			if !h.personExists(w, r, pickedByID) {
+				return
+			}
+			input.PickedByPersonID = &pickedByID
+		}
+	}
+
+	if _, ok := h.loadEntry(w, r, entryID); !ok {
+		return
+	}
+
+	if err := h.entryRepo.Update(ctx, entryID, input); err != nil {
+		if status, code, message, ok := placementError(err); ok {
+			apijson.WriteError(w, status, code, message)
+			return
+		}
+		writeInternalError(w, "failed to update entry", err)
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// MoveEntry moves an entry to another group or position, renumbering both groups
+func (h *APIHandler) MoveEntry(w http.ResponseWriter, r *http.Request) {
+	h.placeEntry(w, r, false)
+}
+
+// CopyEntry adds an entry's movie to another group, without its watched date or ratings
+func (h *APIHandler) CopyEntry(w http.ResponseWriter, r *http.Request) {
+	h.placeEntry(w, r, true)
+}
+
+func (h *APIHandler) placeEntry(w http.ResponseWriter, r *http.Request, duplicate bool) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	var req MoveEntryRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+	if err := validateMoveEntryRequest(req); err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+
+	place, status := h.entryRepo.MoveEntry, http.StatusOK
+	if duplicate {
+		place, status = h.entryRepo.CopyEntry, http.StatusCreated
+	}
+
+	entry, err := place(r.Context(), entryID, req.GroupNumber, req.Position)
+	if err != nil {
+		if status, code, message, ok := placementError(err); ok {
+			apijson.WriteError(w, status, code, message)
+			return
+		}
+		writeInternalError(w, "failed to place entry", err)
+		return
+	}
+	if entry == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Entry not found")
+		return
+	}
+
+	apijson.Write(w, status, entry)
+}
+
+// placementError maps an error from adding, moving or copying an entry to the
+// status, API error code and message for the client. ok is false for
+// unexpected errors.
+func placementError(err error) (status int, code, message string, ok bool) {
+	switch {
+	case errors.Is(err, repository.ErrUnknownGroup):
+		return http.StatusBadRequest, apijson.CodeBadRequest, "No such group; use an existing group or the next new one", true
+	case errors.Is(err, repository.ErrAlreadyInGroup), isUniqueViolation(err):
+		return http.StatusConflict, apijson.CodeConflict, "Movie is already in that group", true
+	case errors.Is(err, repository.ErrEntryMoved):
+		return http.StatusConflict, apijson.CodeConflict, "Entry was moved by someone else; reload and try again", true
+	}
+	return 0, "", "", false
+}
+
+// validateMoveEntryRequest checks the target group and position
+func validateMoveEntryRequest(req MoveEntryRequest) error {
+	if req.GroupNumber < 1 {
+		return errors.New("group_number must be at least 1")
+	}
+	if req.Position != nil && *req.Position < 1 {
+		return errors.New("position must be at least 1")
+	}
+	return nil
+}
+
+// DeleteEntry removes an entry and its ratings
+func (h *APIHandler) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.loadEntry(w, r, entryID); !ok {
+		return
+	}
+
+	if err := h.entryRepo.Delete(r.Context(), entryID); err != nil {
+		writeInternalError(w, "failed to delete entry", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetWatched records a viewing with everyone present, today unless
+// watched_at is given. Nothing changes if there is already a viewing that day.
+func (h *APIHandler) SetWatched(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	var req SetWatchedRequest
+	if !readBody(w, r, &req, true) {
+		return
+	}
+
+	watchedOn, ok := parseWatchedDate(w, req.WatchedAt, "watched_at")
+	if !ok {
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	day := watchedOn.Format("2006-01-02")
+	if !slices.ContainsFunc(entry.Viewings, func(v *model.Viewing) bool { return v.WatchedOn.Format("2006-01-02") == day }) {
+		attendeeIDs, ok := h.activePersonIDs(w, r)
+		if !ok {
+			return
+		}
+		_, err := h.viewingRepo.Create(r.Context(), model.CreateViewingInput{
+			EntryID:     entryID,
+			WatchedOn:   watchedOn,
+			AttendeeIDs: attendeeIDs,
+		})
+		if err != nil {
+			writeInternalError(w, "failed to mark watched", err)
+			return
+		}
+	}
+
+	entry, ok = h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// ClearWatched removes the latest viewing, undoing the last mark as watched.
+// Earlier viewings are kept; the entry is unwatched once none are left.
+func (h *APIHandler) ClearWatched(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.loadEntry(w, r, entryID); !ok {
+		return
+	}
+
+	if _, err := h.viewingRepo.DeleteLatest(r.Context(), entryID); err != nil {
+		writeInternalError(w, "failed to remove latest viewing", err)
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// loadEntry fetches an entry, writing a 404 or 500 if it cannot be returned
+func (h *APIHandler) loadEntry(w http.ResponseWriter, r *http.Request, entryID uuid.UUID) (*model.Entry, bool) {
+	entry, err := h.entryRepo.GetByID(r.Context(), entryID)
+	if err != nil {
+		writeInternalError(w, "failed to get entry", err)
+		return nil, false
+	}
+	if entry == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Entry not found")
+		return nil, false
+	}
+	return entry, true
+}
+
+// personExists checks a person referenced in a request body, writing a 400 if unknown
+func (h *APIHandler) personExists(w http.ResponseWriter, r *http.Request, personID uuid.UUID) bool {
+	person, err := h.personRepo.GetByID(r.Context(), personID)
+	if err != nil {
+		writeInternalError(w, "failed to get person", err)
+		return false
+	}
+	if person == nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "No person with ID "+personID.String())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// ReorderGroupRequest is the body for setting the order of a group's entries
+type ReorderGroupRequest struct {
+	EntryIDs []uuid.UUID `json:"entry_ids"` // Every entry in the group, first = position 1
+}
+
+// CreateGroupRequest is the body for creating a group
+type CreateGroupRequest struct {
+	GroupNumber *int    `json:"group_number"` // Defaults to the next number after the highest group
+	Name        *string `json:"name"`
+	Theme       *string `json:"theme"`
+	Description *string `json:"description"`
+	StartsOn    string  `json:"starts_on"` // YYYY-MM-DD
+	EndsOn      string  `json:"ends_on"`   // YYYY-MM-DD
+	Current     bool    `json:"current"`   // Make it the group new entries go into
+}
+
+// UpdateGroupRequest is the body for changing a group. Omitted fields are left
+// unchanged; an empty string clears a text field or date.
+type UpdateGroupRequest struct {
+	Name        *string `json:"name"`
+	Theme       *string `json:"theme"`
+	Description *string `json:"description"`
+	StartsOn    *string `json:"starts_on"` // YYYY-MM-DD
+	EndsOn      *string `json:"ends_on"`   // YYYY-MM-DD
+	Current     *bool   `json:"current"`   // Only true is accepted; make another group current instead
+	Archived    *bool   `json:"archived"`
+}
+
+// ListGroups returns active groups with their entry and watched counts, plus
+// archived ones when include_archived=true
+func (h *APIHandler) ListGroups(w http.ResponseWriter, r *http.Request) {
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+
+	groups, err := h.groupRepo.List(r.Context(), includeArchived)
+	if err != nil {
+		writeInternalError(w, "failed to list groups", err)
+		return
+	}
+	if groups == nil {
+		groups = []*model.Group{}
+	}
+
+	apijson.Write(w, http.StatusOK, groups)
+}
+
+// GetGroup returns a single group
+func (h *APIHandler) GetGroup(w http.ResponseWriter, r *http.Request) {
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := h.loadGroup(w, r, groupNum)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, group)
+}
+
+// CreateGroup adds a group, which may be empty
+func (h *APIHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req CreateGroupRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	if req.GroupNumber != nil && *req.GroupNumber < 1 {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "group_number must be at least 1")
+		return
+	}
+
+	input := model.GroupInput{}
+	if req.Name != nil {
+		input.Name = optionalText(*req.Name)
+	}
+	if req.Theme != nil {
+		input.Theme = optionalText(*req.Theme)
+	}
+	if req.Description != nil {
+		input.Description = optionalText(*req.Description)
+	}
+
+	var err error
+	if input.StartsOn, err = parseOptionalDate(req.StartsOn, "starts_on"); err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+	if input.EndsOn, err = parseOptionalDate(req.EndsOn, "ends_on"); err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+	if err := validateGroupInput(input); err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+
+	group, err := h.groupRepo.Create(r.Context(), req.GroupNumber, input, req.Current)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "A group with that number already exists")
+			return
+		}
+		writeInternalError(w, "failed to create group", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusCreated, group)
+}
+
+// UpdateGroup changes a group's details, makes it current, or archives or restores it
+func (h *APIHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateGroupRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	if req.Current != nil && !*req.Current {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "current can only be set to true; make another group current instead")
+		return
+	}
+	if req.Current != nil && req.Archived != nil && *req.Archived {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "An archived group can't be current")
+		return
+	}
+
+	group, ok := h.loadGroup(w, r, groupNum)
+	if !ok {
+		return
+	}
+
+	// Merge the request over the group's current details
+	input := model.GroupInput{
+		Name:        group.Name,
+		Theme:       group.Theme,
+		Description: group.Description,
+		StartsOn:    group.StartsOn,
+		EndsOn:      group.EndsOn,
+	}
+	if req.Name != nil {
+		input.Name = optionalText(*req.Name)
+	}
+	if req.Theme != nil {
+		input.Theme = optionalText(*req.Theme)
+	}
+	if req.Description != nil {
+		input.Description = optionalText(*req.Description)
+	}
+
+	var err error
+	if req.StartsOn != nil {
+		if input.StartsOn, err = parseOptionalDate(*req.StartsOn, "starts_on"); err != nil {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+			return
+		}
+	}
+	if req.EndsOn != nil {
+		if input.EndsOn, err = parseOptionalDate(*req.EndsOn, "ends_on"); err != nil {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+			return
+		}
+	}
+	if err := validateGroupInput(input); err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.groupRepo.Update(ctx, groupNum, input); err != nil {
+		writeInternalError(w, "failed to update group", err)
+		return
+	}
+
+	if req.Archived != nil && *req.Archived != group.IsArchived() {
+		if *req.Archived {
+			err = h.groupRepo.Archive(ctx, groupNum)
+		} else {
+			err = h.groupRepo.Unarchive(ctx, groupNum)
+		}
+		if err != nil {
+			writeInternalError(w, "failed to change group archive state", err)
+			return
+		}
+	}
+	if req.Current != nil && !group.IsCurrent {
+		if err := h.groupRepo.SetCurrent(ctx, groupNum); err != nil {
+			writeInternalError(w, "failed to set current group", err)
+			return
+		}
+	}
+
+	if group, ok = h.loadGroup(w, r, groupNum); !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, group)
+}
+
+// ListGroupEntries returns a group's entries in display order
+func (h *APIHandler) ListGroupEntries(w http.ResponseWriter, r *http.Request) {
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.entryRepo.ListByGroup(r.Context(), groupNum)
+	if err != nil {
+		writeInternalError(w, "failed to list entries", err)
+		return
+	}
+	if entries == nil {
+		entries = []*model.Entry{}
+	}
+
+	apijson.Write(w, http.StatusOK, entries)
+}
+
+// ListGroupPicks returns who picked each entry in a group, oldest first
+func (h *APIHandler) ListGroupPicks(w http.ResponseWriter, r *http.Request) {
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	picks, err := h.entryRepo.ListPicks(r.Context(), groupNum)
+	if err != nil {
+		writeInternalError(w, "failed to list picks", err)
+		return
+	}
+	if picks == nil {
+		picks = []*model.Pick{}
+	}
+
+	apijson.Write(w, http.StatusOK, picks)
+}
+
+// GetNextPicker suggests whose turn it is to pick in a group
+func (h *APIHandler) GetNextPicker(w http.ResponseWriter, r *http.Request) {
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	turn, err := nextPicker(r.Context(), h.entryRepo, h.personRepo, h.pickerRotation, groupNum)
+	if err != nil {
+		writeInternalError(w, "failed to work out next picker", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, turn)
+}
+
+// ReorderGroup sets the display order of a group's entries
+func (h *APIHandler) ReorderGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	groupNum, ok := groupParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReorderGroupRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	entries, err := h.entryRepo.ListByGroup(ctx, groupNum)
+	if err != nil {
+		writeInternalError(w, "failed to list entries", err)
+		return
+	}
+	inGroup := make(map[uuid.UUID]bool, len(entries))
+	for _, entry := range entries {
+		inGroup[entry.ID] = true
+	}
+	if len(req.EntryIDs) != len(entries) {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "entry_ids must list every entry in the group exactly once")
+		return
+	}
+	for _, id := range req.EntryIDs {
+		if !inGroup[id] {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "entry_ids must list every entry in the group exactly once")
+			return
+		}
+		delete(inGroup, id)
+	}
+
+	if err := h.entryRepo.ReorderEntries(ctx, groupNum, req.EntryIDs); err != nil {
+		writeInternalError(w, "failed to reorder entries", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadGroup fetches a group, writing a 404 or 500 if it cannot be returned
+func (h *APIHandler) loadGroup(w http.ResponseWriter, r *http.Request, groupNum int) (*model.Group, bool) {
+	group, err := h.groupRepo.GetByNumber(r.Context(), groupNum)
+	if err != nil {
+		writeInternalError(w, "failed to get group", err)
+		return nil, false
+	}
+	if group == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Group not found")
+		return nil, false
+	}
+	return group, true
+}
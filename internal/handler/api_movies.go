@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/tmdb"
+)
+
+// ListMovies returns every movie in the library, ordered by title
+func (h *APIHandler) ListMovies(w http.ResponseWriter, r *http.Request) {
+	movies, err := h.movieRepo.List(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to list movies", err)
+		return
+	}
+	if movies == nil {
+		movies = []*model.Movie{}
+	}
+
+	apijson.Write(w, http.StatusOK, movies)
+}
+
+// GetMovie returns a single library movie
+func (h *APIHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, ok := uuidParam(w, r, "id", "movie ID")
+	if !ok {
+		return
+	}
+
+	movie, err := h.movieRepo.GetByID(r.Context(), movieID)
+	if err != nil {
+		writeInternalError(w, "failed to get movie", err)
+		return
+	}
+	if movie == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Movie not found")
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, movie)
+}
+
+// DeleteMovie moves a movie to the trash along with every entry of it
+func (h *APIHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, ok := uuidParam(w, r, "id", "movie ID")
+	if !ok {
+		return
+	}
+
+	movie, err := h.movieRepo.GetByID(r.Context(), movieID)
+	if err != nil {
+		writeInternalError(w, "failed to get movie", err)
+		return
+	}
+	if movie == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Movie not found")
+		return
+	}
+
+	if err := h.movieRepo.Delete(r.Context(), movieID); err != nil {
+		writeInternalError(w, "failed to delete movie", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SearchMovies searches TMDB for movies matching the q query parameter
+func (h *APIHandler) SearchMovies(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Query parameter q is required")
+		return
+	}
+
+	results, err := h.tmdbClient.Search(r.Context(), query)
+	if err != nil {
+		writeInternalError(w, "TMDB search failed", err)
+		return
+	}
+	if results.Results == nil {
+		results.Results = []tmdb.SearchResult{}
+	}
+
+	apijson.Write(w, http.StatusOK, results.Results)
+}
+
+// ImportMovieRequest is the body for importing a movie from TMDB
+type ImportMovieRequest struct {
+	TMDBId int `json:"tmdb_id"`
+}
+
+// ImportMovie adds a movie to the library from TMDB.
+// Responds 201 when the movie is new and 200 when it was already in the library.
+func (h *APIHandler) ImportMovie(w http.ResponseWriter, r *http.Request) {
+	var req ImportMovieRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+	if req.TMDBId <= 0 {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "tmdb_id is required")
+		return
+	}
+
+	movie, created, err := library.FindOrImportTMDBMovie(r.Context(), h.movieRepo, h.tmdbClient, req.TMDBId)
+	if err != nil {
+		if errors.Is(err, library.ErrTMDBMovieNotFound) {
+			apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "No TMDB movie with that ID")
+			return
+		}
+		writeInternalError(w, "failed to import TMDB movie", err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	apijson.Write(w, status, movie)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// UpdatePersonRequest is the body for changing a person. Omitted fields are left unchanged.
+type UpdatePersonRequest struct {
+	Initial  *string `json:"initial"`
+	Name     *string `json:"name"`
+	Archived *bool   `json:"archived"`
+}
+
+// ListPersons returns active persons in display order, plus archived ones
+// when include_archived=true
+func (h *APIHandler) ListPersons(w http.ResponseWriter, r *http.Request) {
+	list := h.personRepo.GetAll
+	if r.URL.Query().Get("include_archived") == "true" {
+		list = h.personRepo.ListAll
+	}
+
+	persons, err := list(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to list persons", err)
+		return
+	}
+	if persons == nil {
+		persons = []*model.Person{}
+	}
+
+	apijson.Write(w, http.StatusOK, persons)
+}
+
+// GetPerson returns a single person
+func (h *APIHandler) GetPerson(w http.ResponseWriter, r *http.Request) {
+	personID, ok := uuidParam(w, r, "id", "person ID")
+	if !ok {
+		return
+	}
+
+	person, ok := h.loadPerson(w, r, personID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, person)
+}
+
+// CreatePerson adds a family member
+func (h *APIHandler) CreatePerson(w http.ResponseWriter, r *http.Request) {
+	var input model.CreatePersonInput
+	if !readBody(w, r, &input, false) {
+		return
+	}
+
+	initial, ok := model.NormalizeInitial(input.Initial)
+	if !ok {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "initial must be a single character")
+		return
+	}
+	input.Initial = initial
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "name is required")
+		return
+	}
+
+	person, err := h.personRepo.Create(r.Context(), input)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "Initial is already in use")
+			return
+		}
+		writeInternalError(w, "failed to create person", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusCreated, person)
+}
+
+// UpdatePerson renames, re-initials, archives or restores a person
+func (h *APIHandler) UpdatePerson(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	personID, ok := uuidParam(w, r, "id", "person ID")
+	if !ok {
+		return
+	}
+
+	var req UpdatePersonRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	input := model.UpdatePersonInput{}
+	if req.Initial != nil {
+		initial, ok := model.NormalizeInitial(*req.Initial)
+		if !ok {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "initial must be a single character")
+			return
+		}
+		input.Initial = &initial
+	}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "name must not be empty")
+			return
+		}
+		input.Name = &name
+	}
+
+	person, err := h.personRepo.Update(ctx, personID, input)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "Initial is already in use")
+			return
+		}
+		writeInternalError(w, "failed to update person", err)
+		return
+	}
+	if person == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Person not found")
+		return
+	}
+
+	if req.Archived != nil && *req.Archived != person.IsArchived() {
+		if *req.Archived {
+			err = h.personRepo.Archive(ctx, personID)
+		} else {
+			err = h.personRepo.Unarchive(ctx, personID)
+		}
+		if err != nil {
+			writeInternalError(w, "failed to change person archive state", err)
+			return
+		}
+		if person, ok = h.loadPerson(w, r, personID); !ok {
+			return
+		}
+	}
+
+	apijson.Write(w, http.StatusOK, person)
+}
+
+// loadPerson fetches a person, writing a 404 or 500 if they cannot be returned
+func (h *APIHandler) loadPerson(w http.ResponseWriter, r *http.Request, personID uuid.UUID) (*model.Person, bool) {
+	person, err := h.personRepo.GetByID(r.Context(), personID)
+	if err != nil {
+		writeInternalError(w, "failed to get person", err)
+		return nil, false
+	}
+	if person == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Person not found")
+		return nil, false
+	}
+	return person, true
+}
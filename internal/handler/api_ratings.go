@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// SetRatingRequest is the body for rating an entry
+type SetRatingRequest struct {
+	Score     *float64   `json:"score"`                // 0.0 - 10.0
+	ViewingID *uuid.UUID `json:"viewing_id,omitempty"` // One of the entry's viewings; omit to keep the current one
+}
+
+// ListRatings returns every rating for an entry
+func (h *APIHandler) ListRatings(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	if _, ok := h.loadEntry(w, r, entryID); !ok {
+		return
+	}
+
+	ratings, err := h.ratingRepo.GetByEntryID(r.Context(), entryID)
+	if err != nil {
+		writeInternalError(w, "failed to get ratings", err)
+		return
+	}
+	if ratings == nil {
+		ratings = []*model.Rating{}
+	}
+
+	apijson.Write(w, http.StatusOK, ratings)
+}
+
+// SetRating creates or updates a person's rating for an entry
+func (h *APIHandler) SetRating(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	personID, ok := uuidParam(w, r, "personId", "person ID")
+	if !ok {
+		return
+	}
+	if !canRateAs(r, personID) {
+		apijson.WriteError(w, http.StatusForbidden, apijson.CodeForbidden, "You can only change your own rating")
+		return
+	}
+
+	var req SetRatingRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+	if req.Score == nil || *req.Score < 0.0 || *req.Score > 10.0 {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "score is required (0.0-10.0)")
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+	if req.ViewingID != nil && entry.GetViewingByID(*req.ViewingID) == nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "viewing_id is not a viewing of this entry")
+		return
+	}
+	person, ok := h.loadPerson(w, r, personID)
+	if !ok {
+		return
+	}
+
+	rating, err := h.ratingRepo.Upsert(ctx, model.UpsertRatingInput{
+		PersonID:  personID,
+		EntryID:   entryID,
+		Score:     *req.Score,
+		ViewingID: req.ViewingID,
+	})
+	if err != nil {
+		writeInternalError(w, "failed to save rating", err)
+		return
+	}
+	rating.Person = person
+
+	apijson.Write(w, http.StatusOK, rating)
+}
+
+// DeleteRating removes a person's rating for an entry
+func (h *APIHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	personID, ok := uuidParam(w, r, "personId", "person ID")
+	if !ok {
+		return
+	}
+	if !canRateAs(r, personID) {
+		apijson.WriteError(w, http.StatusForbidden, apijson.CodeForbidden, "You can only change your own rating")
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+	if entry.GetRatingByPersonID(personID) == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Rating not found")
+		return
+	}
+
+	if err := h.ratingRepo.Delete(r.Context(), personID, entryID); err != nil {
+		writeInternalError(w, "failed to delete rating", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
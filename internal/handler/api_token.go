@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// apiTokenPrefix marks Seenema tokens so they are easy to recognise in scripts and secret scanners
+const apiTokenPrefix = "snm_"
+
+// APITokenHandler handles minting and revoking scoped API tokens
+type APITokenHandler struct {
+	apiTokenRepo *repository.APITokenRepository
+}
+
+// NewAPITokenHandler creates a new APITokenHandler
+func NewAPITokenHandler(apiTokenRepo *repository.APITokenRepository) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenRepo: apiTokenRepo,
+	}
+}
+
+// TokensPage renders the API token management page
+func (h *APITokenHandler) TokensPage(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.apiTokenRepo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list api tokens", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.APITokensPage(tokens).Render(r.Context(), w)
+}
+
+// CreateAPITokenResponse is returned once when a token is minted; the secret cannot be retrieved later
+type CreateAPITokenResponse struct {
+	Token  *model.APIToken `json:"token"`
+	Secret string          `json:"secret"`
+}
+
+// Create mints a new named token with the requested scopes
+func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	scopes := r.Form["scopes"]
+	if len(scopes) == 0 {
+		http.Error(w, "At least one scope is required", http.StatusBadRequest)
+		return
+	}
+	for _, scope := range scopes {
+		if !model.IsValidScope(scope) {
+			http.Error(w, "Unknown scope: "+scope, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if daysStr := r.FormValue("expires_in_days"); daysStr != "" && daysStr != "0" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			http.Error(w, "Invalid expiry", http.StatusBadRequest)
+			return
+		}
+		expiry := time.Now().AddDate(0, 0, days)
+		expiresAt = &expiry
+	}
+
+	secret, err := auth.NewToken()
+	if err != nil {
+		slog.Error("failed to generate api token", "error", err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+	secret = apiTokenPrefix + secret
+
+	input := model.CreateAPITokenInput{
+		Name:      name,
+		TokenHash: auth.HashToken(secret),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if person := auth.PersonFromContext(ctx); person != nil {
+		input.CreatedByPersonID = &person.ID
+	}
+
+	token, err := h.apiTokenRepo.Create(ctx, input)
+	if err != nil {
+		slog.Error("failed to create api token", "error", err)
+		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		apijson.Write(w, http.StatusCreated, CreateAPITokenResponse{Token: token, Secret: secret})
+		return
+	}
+
+	tokens, err := h.apiTokenRepo.List(ctx)
+	if err != nil {
+		slog.Error("failed to list api tokens", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Token created!", "type": "success"}}`)
+	partials.APITokenList(tokens, token.ID, secret).Render(ctx, w)
+}
+
+// Revoke deletes a token so it can no longer be used
+func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tokenID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.apiTokenRepo.Delete(ctx, tokenID)
+	if err != nil {
+		slog.Error("failed to revoke api token", "error", err)
+		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	tokens, err := h.apiTokenRepo.List(ctx)
+	if err != nil {
+		slog.Error("failed to list api tokens", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Token revoked!", "type": "success"}}`)
+	partials.APITokenList(tokens, uuid.Nil, "").Render(ctx, w)
+}
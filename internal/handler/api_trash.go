@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+)
+
+// ListTrash returns deleted movies, entries and ratings that can still be
+// restored, most recently deleted first
+func (h *APIHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	items, err := h.trashRepo.List(r.Context(), h.trashRetention)
+	if err != nil {
+		writeInternalError(w, "failed to list trash", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, items)
+}
+
+// RestoreEntry brings back a deleted entry with its ratings
+func (h *APIHandler) RestoreEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	found, err := h.trashRepo.RestoreEntry(r.Context(), entryID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "The movie is already in that group again")
+			return
+		}
+		writeInternalError(w, "failed to restore entry", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Entry not found in trash")
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// RestoreMovie brings back a deleted movie with the entries deleted along with it
+func (h *APIHandler) RestoreMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, ok := uuidParam(w, r, "id", "movie ID")
+	if !ok {
+		return
+	}
+
+	found, err := h.trashRepo.RestoreMovie(r.Context(), movieID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "The movie is already in one of its groups again")
+			return
+		}
+		writeInternalError(w, "failed to restore movie", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Movie not found in trash")
+		return
+	}
+
+	movie, err := h.movieRepo.GetByID(r.Context(), movieID)
+	if err != nil {
+		writeInternalError(w, "failed to get movie", err)
+		return
+	}
+	apijson.Write(w, http.StatusOK, movie)
+}
+
+// RestoreRating brings back a person's deleted rating of an entry
+func (h *APIHandler) RestoreRating(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	personID, ok := uuidParam(w, r, "personId", "person ID")
+	if !ok {
+		return
+	}
+	if !canRateAs(r, personID) {
+		apijson.WriteError(w, http.StatusForbidden, apijson.CodeForbidden, "You can only change your own rating")
+		return
+	}
+
+	found, err := h.trashRepo.RestoreRating(r.Context(), personID, entryID)
+	if err != nil {
+		writeInternalError(w, "failed to restore rating", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Rating not found in trash")
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+	apijson.Write(w, http.StatusOK, entry.GetRatingByPersonID(personID))
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// CreateViewingRequest is the body for recording a viewing. Omitting
+// attendee_ids records everyone active as present.
+type CreateViewingRequest struct {
+	WatchedOn   string       `json:"watched_on"` // YYYY-MM-DD, defaults to today
+	Location    *string      `json:"location"`
+	Note        *string      `json:"note"`
+	AttendeeIDs *[]uuid.UUID `json:"attendee_ids"`
+}
+
+// SetAttendanceRequest is the body for marking a person present or absent
+type SetAttendanceRequest struct {
+	Present bool `json:"present"`
+}
+
+// ListViewings returns an entry's viewings, oldest first
+func (h *APIHandler) ListViewings(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, entry.Viewings)
+}
+
+// CreateViewing records that an entry was watched
+func (h *APIHandler) CreateViewing(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+
+	var req CreateViewingRequest
+	if !readBody(w, r, &req, true) {
+		return
+	}
+
+	watchedOn, ok := parseWatchedDate(w, req.WatchedOn, "watched_on")
+	if !ok {
+		return
+	}
+	input := model.CreateViewingInput{
+		EntryID:   entryID,
+		WatchedOn: watchedOn,
+		Location:  trimmedOrNil(req.Location),
+		Note:      trimmedOrNil(req.Note),
+	}
+	if req.AttendeeIDs != nil {
+		input.AttendeeIDs = *req.AttendeeIDs
+	} else {
+		var ok bool
+		if input.AttendeeIDs, ok = h.activePersonIDs(w, r); !ok {
+			return
+		}
+	}
+
+	viewing, err := h.viewingRepo.Create(r.Context(), input)
+	if err != nil {
+		writeInternalError(w, "failed to add viewing", err)
+		return
+	}
+	if viewing == nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Entry not found")
+		return
+	}
+
+	apijson.Write(w, http.StatusCreated, viewing)
+}
+
+// DeleteViewing removes one viewing of an entry
+func (h *APIHandler) DeleteViewing(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	viewingID, ok := uuidParam(w, r, "viewingId", "viewing ID")
+	if !ok {
+		return
+	}
+
+	found, err := h.viewingRepo.Delete(r.Context(), entryID, viewingID)
+	if err != nil {
+		writeInternalError(w, "failed to delete viewing", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Viewing not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetAttendance marks a person present at or absent from an entry's viewings
+func (h *APIHandler) SetAttendance(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	personID, ok := uuidParam(w, r, "personId", "person ID")
+	if !ok {
+		return
+	}
+
+	var req SetAttendanceRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+
+	if _, ok := h.loadEntry(w, r, entryID); !ok {
+		return
+	}
+	if _, ok := h.loadPerson(w, r, personID); !ok {
+		return
+	}
+
+	found, err := h.viewingRepo.SetAttendance(r.Context(), entryID, personID, req.Present)
+	if err != nil {
+		writeInternalError(w, "failed to set attendance", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "Entry has no viewings yet")
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, entryID)
+	if !ok {
+		return
+	}
+	apijson.Write(w, http.StatusOK, entry)
+}
+
+// activePersonIDs lists everyone who isn't archived, writing a 500 on failure
+func (h *APIHandler) activePersonIDs(w http.ResponseWriter, r *http.Request) ([]uuid.UUID, bool) {
+	persons, err := h.personRepo.GetAll(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to get persons", err)
+		return nil, false
+	}
+	return personIDs(persons), true
+}
+
+// parseWatchedDate parses an optional YYYY-MM-DD date that defaults to today,
+// writing a 400 if it is malformed
+func parseWatchedDate(w http.ResponseWriter, value, name string) (time.Time, bool) {
+	if value == "" {
+		return time.Now(), true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, name+" must be a YYYY-MM-DD date")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// trimmedOrNil trims s, returning nil if nothing is left
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
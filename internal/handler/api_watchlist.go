@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// SuggestMovieRequest is the body for putting a movie on the watchlist.
+// Exactly one of movie_id or tmdb_id must be set; a tmdb_id not yet in the
+// library is imported first. A signed-in person is always the suggester.
+type SuggestMovieRequest struct {
+	MovieID             *uuid.UUID `json:"movie_id"`
+	TMDBId              *int       `json:"tmdb_id"`
+	SuggestedByPersonID *uuid.UUID `json:"suggested_by_person_id"`
+	Reason              *string    `json:"reason"`
+}
+
+// PromoteSuggestionRequest is the optional body for adding a suggestion to a group
+type PromoteSuggestionRequest struct {
+	GroupNumber *int `json:"group_number"` // nil = current group
+}
+
+// ListWatchlist returns the suggestions on the watchlist, most upvoted first
+func (h *APIHandler) ListWatchlist(w http.ResponseWriter, r *http.Request) {
+	suggestions, err := h.watchlistRepo.List(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to list watchlist", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, suggestions)
+}
+
+// SuggestMovie puts a movie on the watchlist
+func (h *APIHandler) SuggestMovie(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req SuggestMovieRequest
+	if !readBody(w, r, &req, false) {
+		return
+	}
+	if (req.MovieID == nil) == (req.TMDBId == nil) {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Exactly one of movie_id or tmdb_id is required")
+		return
+	}
+
+	input := model.CreateSuggestionInput{
+		SuggestedByPersonID: req.SuggestedByPersonID,
+		Reason:              trimmedOrNil(req.Reason),
+	}
+	if current := auth.PersonFromContext(ctx); current != nil {
+		input.SuggestedByPersonID = &current.ID
+	} else if req.SuggestedByPersonID != nil && !h.personExists(w, r, *req.SuggestedByPersonID) {
+		return
+	}
+
+	if req.TMDBId != nil {
+		movie, _, err := library.FindOrImportTMDBMovie(ctx, h.movieRepo, h.tmdbClient, *req.TMDBId)
+		if err != nil {
+			if errors.Is(err, library.ErrTMDBMovieNotFound) {
+				apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "No TMDB movie with that tmdb_id")
+				return
+			}
+			writeInternalError(w, "failed to import TMDB movie", err)
+			return
+		}
+		input.MovieID = movie.ID
+	} else {
+		movie, err := h.movieRepo.GetByID(ctx, *req.MovieID)
+		if err != nil {
+			writeInternalError(w, "failed to get movie", err)
+			return
+		}
+		if movie == nil {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "No movie with that movie_id")
+			return
+		}
+		input.MovieID = movie.ID
+	}
+
+	suggestion, err := h.watchlistRepo.Create(ctx, input)
+	if err != nil {
+		if isUniqueViolation(err) {
+			apijson.WriteError(w, http.StatusConflict, apijson.CodeConflict, "Movie is already on the watchlist")
+			return
+		}
+		writeInternalError(w, "failed to suggest movie", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusCreated, suggestion)
+}
+
+// WithdrawSuggestion takes a suggestion off the watchlist
+func (h *APIHandler) WithdrawSuggestion(w http.ResponseWriter, r *http.Request) {
+	suggestionID, ok := uuidParam(w, r, "id", "suggestion ID")
+	if !ok {
+		return
+	}
+
+	found, err := h.watchlistRepo.Delete(r.Context(), suggestionID)
+	if err != nil {
+		writeInternalError(w, "failed to withdraw suggestion", err)
+		return
+	}
+	if !found {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Suggestion not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VoteForSuggestion upvotes a suggestion for a person
+func (h *APIHandler) VoteForSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.setSuggestionVote(w, r, true)
+}
+
+// UnvoteSuggestion withdraws a person's upvote
+func (h *APIHandler) UnvoteSuggestion(w http.ResponseWriter, r *http.Request) {
+	h.setSuggestionVote(w, r, false)
+}
+
+func (h *APIHandler) setSuggestionVote(w http.ResponseWriter, r *http.Request, vote bool) {
+	ctx := r.Context()
+
+	suggestionID, ok := uuidParam(w, r, "id", "suggestion ID")
+	if !ok {
+		return
+	}
+	personID, ok := uuidParam(w, r, "personId", "person ID")
+	if !ok {
+		return
+	}
+	if !canRateAs(r, personID) {
+		apijson.WriteError(w, http.StatusForbidden, apijson.CodeForbidden, "You can only vote for yourself")
+		return
+	}
+
+	person, ok := h.loadPerson(w, r, personID)
+	if !ok {
+		return
+	}
+	suggestion, ok := h.loadSuggestion(w, r, suggestionID)
+	if !ok {
+		return
+	}
+	if suggestion.IsSuggestedBy(person.ID) {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Suggesters can't upvote their own suggestion")
+		return
+	}
+
+	var err error
+	if vote {
+		if person.IsArchived() {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "Archived persons can't vote")
+			return
+		}
+		_, err = h.watchlistRepo.Vote(ctx, suggestionID, personID)
+	} else {
+		_, err = h.watchlistRepo.Unvote(ctx, suggestionID, personID)
+	}
+	if err != nil {
+		writeInternalError(w, "failed to save vote", err)
+		return
+	}
+
+	suggestion, ok = h.loadSuggestion(w, r, suggestionID)
+	if !ok {
+		return
+	}
+	apijson.Write(w, http.StatusOK, suggestion)
+}
+
+// PromoteSuggestion adds a suggested movie to a group and takes it off the watchlist
+func (h *APIHandler) PromoteSuggestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	suggestionID, ok := uuidParam(w, r, "id", "suggestion ID")
+	if !ok {
+		return
+	}
+
+	var req PromoteSuggestionRequest
+	if !readBody(w, r, &req, true) {
+		return
+	}
+
+	var groupNumber int
+	if req.GroupNumber != nil {
+		if *req.GroupNumber < 1 {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "group_number must be at least 1")
+			return
+		}
+		groupNumber = *req.GroupNumber
+	} else {
+		currentGroup, err := h.groupRepo.GetCurrent(ctx)
+		if err != nil {
+			writeInternalError(w, "failed to get current group", err)
+			return
+		}
+		groupNumber = currentGroup
+	}
+
+	suggestion, ok := h.loadSuggestion(w, r, suggestionID)
+	if !ok {
+		return
+	}
+
+	created, err := promoteSuggestion(r, h.entryRepo, h.watchlistRepo, suggestion, groupNumber)
+	if err != nil {
+		if status, code, message, ok := placementError(err); ok {
+			apijson.WriteError(w, status, code, message)
+			return
+		}
+		writeInternalError(w, "failed to promote suggestion", err)
+		return
+	}
+
+	entry, ok := h.loadEntry(w, r, created.ID)
+	if !ok {
+		return
+	}
+	apijson.Write(w, http.StatusCreated, entry)
+}
+
+// loadSuggestion fetches a suggestion still on the watchlist, writing a 404 if there is none
+func (h *APIHandler) loadSuggestion(w http.ResponseWriter, r *http.Request, suggestionID uuid.UUID) (*model.Suggestion, bool) {
+	suggestion, err := h.watchlistRepo.GetByID(r.Context(), suggestionID)
+	if err != nil {
+		writeInternalError(w, "failed to get suggestion", err)
+		return nil, false
+	}
+	if suggestion == nil || suggestion.PromotedAt != nil {
+		apijson.WriteError(w, http.StatusNotFound, apijson.CodeNotFound, "Suggestion not found")
+		return nil, false
+	}
+	return suggestion, true
+}
@@ -1,73 +1,135 @@
 package handler
 
 import (
-	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
 	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/google/uuid"
 )
 
-const cookieName = "seenema_session"
+const sessionTTL = 90 * 24 * time.Hour // 90 days
+
+// Failed sign-ins allowed per person and per address before further attempts
+// are refused for the rest of the window
+const (
+	loginAttemptLimit  = 10
+	loginAttemptWindow = 15 * time.Minute
+)
 
 // AuthHandler handles authentication
 type AuthHandler struct {
-	apiToken      string
+	personRepo    *repository.PersonRepository
+	sessionRepo   *repository.SessionRepository
 	secureCookies bool
+	loginThrottle *auth.Throttle
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(apiToken string, secureCookies bool) *AuthHandler {
+func NewAuthHandler(personRepo *repository.PersonRepository, sessionRepo *repository.SessionRepository, secureCookies bool) *AuthHandler {
 	return &AuthHandler{
-		apiToken:      apiToken,
+		personRepo:    personRepo,
+		sessionRepo:   sessionRepo,
 		secureCookies: secureCookies,
+		loginThrottle: auth.NewThrottle(loginAttemptLimit, loginAttemptWindow),
 	}
 }
 
 // LoginPage renders the login page
 func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
-	// If already authenticated via valid cookie, redirect to home
-	if cookie, err := r.Cookie(cookieName); err == nil {
-		if constantTimeEqual(cookie.Value, h.apiToken) {
+	ctx := r.Context()
+
+	// If already authenticated via valid session, redirect to home
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
+		session, err := h.sessionRepo.GetByTokenHash(ctx, auth.HashToken(cookie.Value))
+		if err != nil {
+			slog.Error("failed to look up session", "error", err)
+		} else if session != nil {
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
 		}
 	}
 
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	errorType := r.URL.Query().Get("error")
 	redirectURL := r.URL.Query().Get("redirect")
-	pages.LoginPage(errorType, redirectURL).Render(r.Context(), w)
+	pages.LoginPage(persons, errorType, redirectURL).Render(ctx, w)
 }
 
 // Login handles the login form submission
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	if err := r.ParseForm(); err != nil {
 		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
 		return
 	}
 
-	apiKey := r.FormValue("api_key")
-	if apiKey == "" {
-		http.Redirect(w, r, "/login?error=missing_key", http.StatusSeeOther)
+	personID, err := uuid.Parse(r.FormValue("person_id"))
+	password := r.FormValue("password")
+	if err != nil || password == "" {
+		http.Redirect(w, r, "/login?error=missing_credentials", http.StatusSeeOther)
 		return
 	}
 
-	// Validate the API token with constant-time comparison
-	if !constantTimeEqual(apiKey, h.apiToken) {
-		http.Redirect(w, r, "/login?error=invalid_key", http.StatusSeeOther)
+	// Guessing is throttled both per person and per address
+	throttleKeys := []string{"person:" + personID.String(), "addr:" + clientAddress(r)}
+	for _, key := range throttleKeys {
+		if !h.loginThrottle.Allowed(key) {
+			slog.Warn("login throttled", "key", key)
+			http.Redirect(w, r, "/login?error=too_many_attempts", http.StatusSeeOther)
+			return
+		}
+	}
+
+	person, err := h.personRepo.GetByID(ctx, personID)
+	if err != nil {
+		slog.Error("failed to get person", "error", err)
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+		return
+	}
+	if person == nil || person.IsArchived() {
+		http.Redirect(w, r, "/login?error=invalid_credentials", http.StatusSeeOther)
+		return
+	}
+	if !person.HasPassword {
+		http.Redirect(w, r, "/login?error=no_password", http.StatusSeeOther)
 		return
 	}
+	if !h.checkPassword(r, person, password) {
+		for _, key := range throttleKeys {
+			h.loginThrottle.Fail(key)
+		}
+		http.Redirect(w, r, "/login?error=invalid_credentials", http.StatusSeeOther)
+		return
+	}
+	h.loginThrottle.Reset(throttleKeys[0])
 
-	// Set the session cookie with the token value
-	http.SetCookie(w, &http.Cookie{
-		Name:     cookieName,
-		Value:    h.apiToken,
-		Path:     "/",
-		MaxAge:   90 * 24 * 60 * 60, // 90 days
-		HttpOnly: true,
-		Secure:   h.secureCookies,
-		SameSite: http.SameSiteLaxMode,
-	})
+	// Signing in again replaces the session this browser already had
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
+		if err := h.sessionRepo.DeleteByTokenHash(ctx, auth.HashToken(cookie.Value)); err != nil {
+			slog.Error("failed to delete previous session", "error", err)
+		}
+	}
+
+	if err := h.startSession(w, r, person); err != nil {
+		slog.Error("failed to create session", "error", err, "person_id", person.ID)
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+		return
+	}
 
 	// Redirect to the original URL if provided, otherwise home
 	redirectURL := r.FormValue("redirect")
@@ -77,6 +139,57 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 }
 
+// clientAddress returns the request's client IP, without the port
+func clientAddress(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// checkPassword verifies a person's password. A person without one can't
+// sign in until it's set with `seenema persons set-password`.
+func (h *AuthHandler) checkPassword(r *http.Request, person *model.Person, password string) bool {
+	hash, err := h.personRepo.GetPasswordHash(r.Context(), person.ID)
+	if err != nil {
+		slog.Error("failed to get password hash", "error", err, "person_id", person.ID)
+		return false
+	}
+	if hash == nil {
+		return false
+	}
+	return auth.CheckPassword(*hash, password)
+}
+
+// startSession creates a server-side session and sets its cookie
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, person *model.Person) error {
+	token, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.sessionRepo.Create(r.Context(), model.CreateSessionInput{
+		PersonID:  person.ID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(sessionTTL),
+		UserAgent: r.UserAgent(),
+		IPAddress: r.RemoteAddr,
+	}); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
 // isValidRedirect checks that the redirect URL is safe (relative path only)
 func isValidRedirect(rawURL string) bool {
 	parsed, err := url.Parse(rawURL)
@@ -87,11 +200,21 @@ func isValidRedirect(rawURL string) bool {
 	return parsed.Scheme == "" && parsed.Host == "" && len(parsed.Path) > 0 && parsed.Path[0] == '/'
 }
 
-// Logout clears the session cookie
+// Logout ends the current session and clears the session cookie
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// Clear the cookie
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
+		if err := h.sessionRepo.DeleteByTokenHash(r.Context(), auth.HashToken(cookie.Value)); err != nil {
+			slog.Error("failed to delete session", "error", err)
+		}
+	}
+
+	h.clearSessionCookie(w)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func (h *AuthHandler) clearSessionCookie(w http.ResponseWriter) {
 	http.SetCookie(w, &http.Cookie{
-		Name:     cookieName,
+		Name:     auth.SessionCookieName,
 		Value:    "",
 		Path:     "/",
 		MaxAge:   -1,
@@ -99,12 +222,61 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		Secure:   h.secureCookies,
 		SameSite: http.SameSiteLaxMode,
 	})
-	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
-// constantTimeEqual performs a constant-time comparison to prevent timing attacks.
-func constantTimeEqual(a, b string) bool {
-	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
-}
+// ChangePassword sets the signed-in person's password
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	person := auth.PersonFromContext(ctx)
+	if person == nil {
+		http.Error(w, "Sign in as a family member to set a password", http.StatusForbidden)
+		return
+	}
 
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	if person.HasPassword && !h.checkPassword(r, person, r.FormValue("current_password")) {
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Current password is incorrect", "type": "error"}}`)
+		http.Error(w, "Current password is incorrect", http.StatusForbidden)
+		return
+	}
+
+	newPassword := r.FormValue("new_password")
+	if newPassword != r.FormValue("confirm_password") {
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "Passwords do not match", "type": "error"}}`)
+		http.Error(w, "Passwords do not match", http.StatusBadRequest)
+		return
+	}
+
+	if len(newPassword) < auth.MinPasswordLength {
+		message := fmt.Sprintf("Password must be at least %d characters", auth.MinPasswordLength)
+		w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "error"}}`)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	hash, err := auth.HashPassword(newPassword)
+	if err != nil {
+		slog.Error("failed to hash password", "error", err)
+		http.Error(w, "Failed to set password", http.StatusInternalServerError)
+		return
+	}
 
+	if err := h.personRepo.SetPasswordHash(ctx, person.ID, hash); err != nil {
+		slog.Error("failed to set password", "error", err, "person_id", person.ID)
+		http.Error(w, "Failed to set password", http.StatusInternalServerError)
+		return
+	}
+
+	// Sign out every other device, in case the old password leaked
+	if _, err := h.sessionRepo.DeleteOthers(ctx, person.ID, auth.SessionIDFromContext(ctx)); err != nil {
+		slog.Error("failed to revoke other sessions", "error", err, "person_id", person.ID)
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Password saved! Other devices have been signed out.", "type": "success"}}`)
+	w.WriteHeader(http.StatusOK)
+}
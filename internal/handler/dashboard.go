@@ -4,7 +4,6 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
-	"sort"
 
 	"github.com/drywaters/seenema/internal/model"
 	"github.com/drywaters/seenema/internal/repository"
@@ -13,83 +12,107 @@ import (
 
 // DashboardHandler handles the main dashboard
 type DashboardHandler struct {
-	entryRepo  *repository.EntryRepository
-	personRepo *repository.PersonRepository
+	entryRepo      *repository.EntryRepository
+	groupRepo      *repository.GroupRepository
+	personRepo     *repository.PersonRepository
+	pickerRotation model.PickerRotation
 }
 
 // NewDashboardHandler creates a new DashboardHandler
-func NewDashboardHandler(entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository) *DashboardHandler {
+func NewDashboardHandler(entryRepo *repository.EntryRepository, groupRepo *repository.GroupRepository, personRepo *repository.PersonRepository, pickerRotation model.PickerRotation) *DashboardHandler {
 	return &DashboardHandler{
-		entryRepo:  entryRepo,
-		personRepo: personRepo,
+		entryRepo:      entryRepo,
+		groupRepo:      groupRepo,
+		personRepo:     personRepo,
+		pickerRotation: pickerRotation,
 	}
 }
 
-// DashboardPage renders the main dashboard with all groups
+// dashboardEagerGroups is how many of the newest groups the dashboard renders up front;
+// older groups are fetched one at a time as the user scrolls
+const dashboardEagerGroups = 3
+
+// DashboardPage renders the main dashboard with the newest groups
 func (h *DashboardHandler) DashboardPage(w http.ResponseWriter, r *http.Request) {
-	groupDataList, persons, currentGroup, err := h.getDashboardData(r.Context())
+	data, err := h.getDashboardData(r.Context())
 	if err != nil {
 		slog.Error("failed to get dashboard data", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	pages.DashboardPage(groupDataList, persons, currentGroup).Render(r.Context(), w)
+	pages.DashboardPage(data).Render(r.Context(), w)
 }
 
 // DashboardContent renders just the inner content for HTMX partial updates
 func (h *DashboardHandler) DashboardContent(w http.ResponseWriter, r *http.Request) {
-	groupDataList, persons, currentGroup, err := h.getDashboardData(r.Context())
+	data, err := h.getDashboardData(r.Context())
 	if err != nil {
 		slog.Error("failed to get dashboard data", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	pages.DashboardContent(groupDataList, persons, currentGroup).Render(r.Context(), w)
+	pages.DashboardContent(data).Render(r.Context(), w)
 }
 
-// getDashboardData retrieves all data needed for the dashboard
-func (h *DashboardHandler) getDashboardData(ctx context.Context) ([]pages.GroupData, []*model.Person, int, error) {
-	// Get all group numbers
-	groups, err := h.entryRepo.ListGroups(ctx)
+// getDashboardData retrieves the data needed for the dashboard.
+// The number of queries is fixed, however many groups exist.
+func (h *DashboardHandler) getDashboardData(ctx context.Context) (pages.DashboardData, error) {
+	data := pages.DashboardData{CurrentGroup: 1, NewGroup: 1}
+
+	// Every active group's name and size, for the "Add to" select. Archived
+	// groups are left out but still count towards the next group number.
+	var err error
+	if data.Summaries, err = h.groupRepo.ListChoices(ctx); err != nil {
+		return data, err
+	}
+	if data.NewGroup, err = h.groupRepo.NextNumber(ctx); err != nil {
+		return data, err
+	}
+
+	data.CurrentGroup, err = h.groupRepo.GetCurrent(ctx)
 	if err != nil {
-		return nil, nil, 0, err
+		return data, err
 	}
 
 	// Get persons for rating display
-	persons, err := h.personRepo.GetAll(ctx)
+	data.Persons, err = h.personRepo.GetAll(ctx)
 	if err != nil {
-		return nil, nil, 0, err
+		return data, err
 	}
 
-	// Get current group for adding movies
-	currentGroup, err := h.entryRepo.GetCurrentGroup(ctx)
+	// Load the newest groups in full; older ones follow as the user scrolls
+	eager, err := h.groupRepo.ListNewest(ctx, dashboardEagerGroups)
 	if err != nil {
-		slog.Error("failed to get current group", "error", err)
-		currentGroup = 1
+		return data, err
 	}
-
-	// Build group data with entries
-	groupDataList := make([]pages.GroupData, 0, len(groups))
-	for _, groupNum := range groups {
-		entries, err := h.entryRepo.ListByGroup(ctx, groupNum)
-		if err != nil {
-			slog.Error("failed to list entries for group", "group", groupNum, "error", err)
-			continue
-		}
-		groupDataList = append(groupDataList, pages.GroupData{
-			Number:  groupNum,
-			Entries: entries,
+	eagerNums := make([]int, 0, len(eager))
+	for _, group := range eager {
+		eagerNums = append(eagerNums, group.Number)
+	}
+	if len(data.Summaries) > len(eager) {
+		older := data.Summaries[len(eager)].Number
+		data.OlderGroup = &older
+	}
+	entriesByGroup, err := h.entryRepo.ListByGroups(ctx, eagerNums)
+	if err != nil {
+		return data, err
+	}
+	for _, group := range eager {
+		data.Groups = append(data.Groups, pages.GroupData{
+			Group:   group,
+			Entries: entriesByGroup[group.Number],
 		})
 	}
 
-	// Sort groups by group number (descending), so higher group numbers appear first
-	sort.Slice(groupDataList, func(i, j int) bool {
-		return groupDataList[i].Number > groupDataList[j].Number
-	})
+	// Suggest whose turn it is to pick in the current group
+	stats, err := h.entryRepo.PickerStats(ctx, data.CurrentGroup)
+	if err != nil {
+		slog.Error("failed to work out next picker", "error", err)
+	} else {
+		data.NextPicker = model.NextPicker(h.pickerRotation, data.Persons, stats, data.CurrentGroup)
+	}
 
-	return groupDataList, persons, currentGroup, nil
+	return data, nil
 }
-
-
@@ -5,7 +5,6 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/drywaters/seenema/internal/model"
 	"github.com/drywaters/seenema/internal/repository"
@@ -17,13 +16,15 @@ import (
 // EntryHandler handles entry-related requests
 type EntryHandler struct {
 	entryRepo  *repository.EntryRepository
+	groupRepo  *repository.GroupRepository
 	personRepo *repository.PersonRepository
 }
 
 // NewEntryHandler creates a new EntryHandler
-func NewEntryHandler(entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository) *EntryHandler {
+func NewEntryHandler(entryRepo *repository.EntryRepository, groupRepo *repository.GroupRepository, personRepo *repository.PersonRepository) *EntryHandler {
 	return &EntryHandler{
 		entryRepo:  entryRepo,
+		groupRepo:  groupRepo,
 		personRepo: personRepo,
 	}
 }
@@ -75,6 +76,10 @@ func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	err = h.entryRepo.Update(ctx, entryID, input)
 	if err != nil {
+		if status, _, message, ok := placementError(err); ok {
+			http.Error(w, message, status)
+			return
+		}
 		slog.Error("failed to update entry", "error", err)
 		http.Error(w, "Failed to update entry", http.StatusInternalServerError)
 		return
@@ -101,109 +106,32 @@ func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Entry deleted!", "type": "success"}, "refreshGroups": true}`)
+	// The entry goes to the trash, so the toast can offer to put it back
+	undoPath := "/api/entries/" + entryID.String() + "/restore"
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Entry deleted!", "type": "success", "undo": "`+undoPath+`"}, "refreshGroups": true}`)
 	w.WriteHeader(http.StatusOK)
 }
 
-// MarkWatched marks an entry as watched
-func (h *EntryHandler) MarkWatched(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	entryIDStr := chi.URLParam(r, "id")
-	entryID, err := uuid.Parse(entryIDStr)
-	if err != nil {
-		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
-		return
-	}
-
-	// Use today's date as the watched date
-	watchedAt := time.Now()
-
-	// Check if a specific date was provided
-	if dateStr := r.FormValue("watched_at"); dateStr != "" {
-		parsedDate, err := time.Parse("2006-01-02", dateStr)
-		if err == nil {
-			watchedAt = parsedDate
-		}
-	}
-
-	if err := h.entryRepo.SetWatchedDate(ctx, entryID, watchedAt); err != nil {
-		slog.Error("failed to mark watched", "error", err)
-		http.Error(w, "Failed to mark as watched", http.StatusInternalServerError)
-		return
-	}
-
-	// Return updated entry partial
-	entry, err := h.entryRepo.GetByID(ctx, entryID)
-	if err != nil {
-		slog.Error("failed to get entry", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	if entry == nil {
-		http.Error(w, "Entry not found", http.StatusNotFound)
-		return
-	}
-
-	persons, err := h.personRepo.GetAll(ctx)
-	if err != nil {
-		slog.Error("failed to get persons", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Marked as watched!", "type": "success"}}`)
-	partials.WatchedStatus(entry, persons).Render(ctx, w)
-}
-
-// ClearWatched clears the watched date
-func (h *EntryHandler) ClearWatched(w http.ResponseWriter, r *http.Request) {
+// GroupPartial renders a single group section, followed by a loader for the
+// next older group when more=true
+func (h *EntryHandler) GroupPartial(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	entryIDStr := chi.URLParam(r, "id")
-	entryID, err := uuid.Parse(entryIDStr)
-	if err != nil {
-		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
-		return
-	}
-
-	if err := h.entryRepo.ClearWatchedDate(ctx, entryID); err != nil {
-		slog.Error("failed to clear watched", "error", err)
-		http.Error(w, "Failed to clear watched status", http.StatusInternalServerError)
-		return
-	}
-
-	// Return updated entry partial
-	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	groupNumStr := chi.URLParam(r, "num")
+	groupNum, err := strconv.Atoi(groupNumStr)
 	if err != nil {
-		slog.Error("failed to get entry", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-	if entry == nil {
-		http.Error(w, "Entry not found", http.StatusNotFound)
+		http.Error(w, "Invalid group number", http.StatusBadRequest)
 		return
 	}
 
-	persons, err := h.personRepo.GetAll(ctx)
+	group, err := h.groupRepo.GetByNumber(ctx, groupNum)
 	if err != nil {
-		slog.Error("failed to get persons", "error", err)
+		slog.Error("failed to get group", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-
-	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Cleared watched status!", "type": "success"}}`)
-	partials.WatchedStatus(entry, persons).Render(ctx, w)
-}
-
-// GroupPartial renders a single group section
-func (h *EntryHandler) GroupPartial(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	groupNumStr := chi.URLParam(r, "num")
-	groupNum, err := strconv.Atoi(groupNumStr)
-	if err != nil {
-		http.Error(w, "Invalid group number", http.StatusBadRequest)
+	if group == nil {
+		http.Error(w, "Group not found", http.StatusNotFound)
 		return
 	}
 
@@ -221,7 +149,19 @@ func (h *EntryHandler) GroupPartial(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	partials.GroupSection(groupNum, entries, persons).Render(ctx, w)
+	partials.GroupSection(group, entries, persons).Render(ctx, w)
+
+	// Infinite scroll on the dashboard: queue up the next older group
+	if r.URL.Query().Get("more") == "true" {
+		older, err := h.groupRepo.GetOlder(ctx, groupNum)
+		if err != nil {
+			slog.Error("failed to get older group", "error", err)
+			return
+		}
+		if older != nil {
+			partials.GroupLoader(*older).Render(ctx, w)
+		}
+	}
 }
 
 // ReorderRequest represents the JSON body for reordering entries
@@ -266,3 +206,56 @@ func (h *EntryHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// Move moves an entry to another group or position (drag and drop across groups)
+func (h *EntryHandler) Move(w http.ResponseWriter, r *http.Request) {
+	h.place(w, r, false)
+}
+
+// Copy adds an entry's movie to another group (drag and drop with a modifier key)
+func (h *EntryHandler) Copy(w http.ResponseWriter, r *http.Request) {
+	h.place(w, r, true)
+}
+
+func (h *EntryHandler) place(w http.ResponseWriter, r *http.Request, duplicate bool) {
+	ctx := r.Context()
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	var req MoveEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	if err := validateMoveEntryRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	place, message := h.entryRepo.MoveEntry, "Movie moved!"
+	if duplicate {
+		place, message = h.entryRepo.CopyEntry, "Movie copied!"
+	}
+
+	entry, err := place(ctx, entryID, req.GroupNumber, req.Position)
+	if err != nil {
+		if status, _, message, ok := placementError(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		slog.Error("failed to place entry", "error", err, "entry_id", entryID, "group", req.GroupNumber, "copy", duplicate)
+		http.Error(w, "Failed to move entry", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	w.WriteHeader(http.StatusOK)
+}
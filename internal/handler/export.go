@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/seenema/internal/export"
+	"github.com/drywaters/seenema/internal/repository"
+)
+
+// ExportHandler handles downloading the whole collection
+type ExportHandler struct {
+	exportRepo *repository.ExportRepository
+	personRepo *repository.PersonRepository
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(exportRepo *repository.ExportRepository, personRepo *repository.PersonRepository) *ExportHandler {
+	return &ExportHandler{
+		exportRepo: exportRepo,
+		personRepo: personRepo,
+	}
+}
+
+// ExportCSV streams every entry as a CSV download
+func (h *ExportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, export.FormatCSV, "text/csv; charset=utf-8")
+}
+
+// ExportJSON streams every entry as a JSON download
+func (h *ExportHandler) ExportJSON(w http.ResponseWriter, r *http.Request) {
+	h.export(w, r, export.FormatJSON, "application/json; charset=utf-8")
+}
+
+func (h *ExportHandler) export(w http.ResponseWriter, r *http.Request, format, contentType string) {
+	persons, err := h.personRepo.ListAll(r.Context())
+	if err != nil {
+		slog.Error("failed to list persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// A large collection can take longer than the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("failed to lift write deadline for export", "error", err)
+	}
+
+	filename := "seenema-" + time.Now().Format("2006-01-02") + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// Headers are already sent once rows stream, so a failure can only be logged
+	if err := export.Write(r.Context(), w, format, h.exportRepo, persons); err != nil {
+		slog.Error("failed to export collection", "error", err, "format", format)
+	}
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+)
+
+// GroupHandler handles watch group management
+type GroupHandler struct {
+	groupRepo *repository.GroupRepository
+}
+
+// NewGroupHandler creates a new GroupHandler
+func NewGroupHandler(groupRepo *repository.GroupRepository) *GroupHandler {
+	return &GroupHandler{
+		groupRepo: groupRepo,
+	}
+}
+
+// GroupsPage renders the group management page, including archived groups
+func (h *GroupHandler) GroupsPage(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.groupRepo.List(r.Context(), true)
+	if err != nil {
+		slog.Error("failed to list groups", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.GroupsPage(groups).Render(r.Context(), w)
+}
+
+// Create adds a new group after the highest existing one
+func (h *GroupHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	input, err := groupInputFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.groupRepo.Create(r.Context(), nil, input, r.FormValue("current") == "true"); err != nil {
+		slog.Error("failed to create group", "error", err)
+		http.Error(w, "Failed to create group", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderList(w, r, "Group added!")
+}
+
+// Update replaces a group's name, theme, description and dates
+func (h *GroupHandler) Update(w http.ResponseWriter, r *http.Request) {
+	groupNum, err := strconv.Atoi(chi.URLParam(r, "num"))
+	if err != nil {
+		http.Error(w, "Invalid group number", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	input, err := groupInputFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group, err := h.groupRepo.Update(r.Context(), groupNum, input)
+	if err != nil {
+		slog.Error("failed to update group", "error", err, "group", groupNum)
+		http.Error(w, "Failed to update group", http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return
+	}
+
+	h.renderList(w, r, "Group updated!")
+}
+
+// SetCurrent makes a group the default for new movies
+func (h *GroupHandler) SetCurrent(w http.ResponseWriter, r *http.Request) {
+	group, ok := h.loadGroup(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.groupRepo.SetCurrent(r.Context(), group.Number); err != nil {
+		slog.Error("failed to set current group", "error", err, "group", group.Number)
+		http.Error(w, "Failed to update group", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderList(w, r, "Current group changed!")
+}
+
+// Archive hides a group from the dashboard without touching its entries
+func (h *GroupHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Unarchive restores an archived group
+func (h *GroupHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *GroupHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	group, ok := h.loadGroup(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	message := "Group archived!"
+	if archived {
+		err = h.groupRepo.Archive(r.Context(), group.Number)
+	} else {
+		err = h.groupRepo.Unarchive(r.Context(), group.Number)
+		message = "Group restored!"
+	}
+	if err != nil {
+		slog.Error("failed to change group archive state", "error", err, "group", group.Number, "archived", archived)
+		http.Error(w, "Failed to update group", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderList(w, r, message)
+}
+
+// loadGroup fetches the group named by the num URL parameter, writing an error if it cannot be returned
+func (h *GroupHandler) loadGroup(w http.ResponseWriter, r *http.Request) (*model.Group, bool) {
+	groupNum, err := strconv.Atoi(chi.URLParam(r, "num"))
+	if err != nil {
+		http.Error(w, "Invalid group number", http.StatusBadRequest)
+		return nil, false
+	}
+
+	group, err := h.groupRepo.GetByNumber(r.Context(), groupNum)
+	if err != nil {
+		slog.Error("failed to get group", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, false
+	}
+	if group == nil {
+		http.Error(w, "Group not found", http.StatusNotFound)
+		return nil, false
+	}
+	return group, true
+}
+
+// renderList renders the group list with a success toast
+func (h *GroupHandler) renderList(w http.ResponseWriter, r *http.Request, message string) {
+	groups, err := h.groupRepo.List(r.Context(), true)
+	if err != nil {
+		slog.Error("failed to list groups", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	partials.GroupList(groups).Render(r.Context(), w)
+}
+
+// groupInputFromForm reads a group's details from a parsed form
+func groupInputFromForm(r *http.Request) (model.GroupInput, error) {
+	input := model.GroupInput{
+		Name:        optionalText(r.FormValue("name")),
+		Theme:       optionalText(r.FormValue("theme")),
+		Description: optionalText(r.FormValue("description")),
+	}
+
+	var err error
+	if input.StartsOn, err = parseOptionalDate(r.FormValue("starts_on"), "starts_on"); err != nil {
+		return input, err
+	}
+	if input.EndsOn, err = parseOptionalDate(r.FormValue("ends_on"), "ends_on"); err != nil {
+		return input, err
+	}
+	return input, validateGroupInput(input)
+}
+
+// validateGroupInput checks that a group's dates are in order
+func validateGroupInput(input model.GroupInput) error {
+	if input.StartsOn != nil && input.EndsOn != nil && input.EndsOn.Before(*input.StartsOn) {
+		return errors.New("ends_on must not be before starts_on")
+	}
+	return nil
+}
+
+// optionalText trims s, returning nil if nothing is left
+func optionalText(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning nil for an empty string
+func parseOptionalDate(s, field string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, errors.New(field + " must be a date in YYYY-MM-DD format")
+	}
+	return &date, nil
+}
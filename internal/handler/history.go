@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// HistoryHandler handles the audit history of entries
+type HistoryHandler struct {
+	auditRepo  *repository.AuditRepository
+	personRepo *repository.PersonRepository
+}
+
+// NewHistoryHandler creates a new HistoryHandler
+func NewHistoryHandler(auditRepo *repository.AuditRepository, personRepo *repository.PersonRepository) *HistoryHandler {
+	return &HistoryHandler{
+		auditRepo:  auditRepo,
+		personRepo: personRepo,
+	}
+}
+
+// parseAuditFilter reads the history's type, action and actor query parameters
+func parseAuditFilter(r *http.Request) (model.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := model.AuditFilter{
+		EntityType: query.Get("type"),
+		Action:     query.Get("action"),
+	}
+
+	switch filter.EntityType {
+	case "", model.AuditEntityEntry, model.AuditEntityRating, model.AuditEntityViewing:
+	default:
+		return filter, errors.New("type must be entry, rating or viewing")
+	}
+	if filter.Action != "" && !slices.Contains(model.AuditActions, filter.Action) {
+		return filter, errors.New("action is not a known audit action")
+	}
+
+	if actorStr := query.Get("actor"); actorStr != "" {
+		actor, err := uuid.Parse(actorStr)
+		if err != nil {
+			return filter, errors.New("actor must be a person ID")
+		}
+		filter.ActorPersonID = &actor
+	}
+
+	return filter, nil
+}
+
+// EntryHistory renders the filterable change history on the movie detail page
+func (h *HistoryHandler) EntryHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.auditRepo.ListForEntry(ctx, entryID, filter)
+	if err != nil {
+		slog.Error("failed to list entry history", "error", err, "entry_id", entryID)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	persons, err := h.personRepo.ListAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	partials.EntryHistory(entryID, events, filter, persons).Render(ctx, w)
+}
+
+// History returns an entry's change history as JSON. It stays available
+// after the entry is deleted, so unknown IDs get an empty list.
+func (h *HistoryHandler) History(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := uuidParam(w, r, "id", "entry ID")
+	if !ok {
+		return
+	}
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.auditRepo.ListForEntry(r.Context(), entryID, filter)
+	if err != nil {
+		writeInternalError(w, "failed to list entry history", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, events)
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drywaters/seenema/internal/importer"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/tmdb"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// maxImportBytes caps the size of an uploaded export
+const maxImportBytes = 10 << 20
+
+// ImportHandler handles importing ratings exported from Letterboxd and IMDb
+type ImportHandler struct {
+	movieRepo   *repository.MovieRepository
+	entryRepo   *repository.EntryRepository
+	ratingRepo  *repository.RatingRepository
+	viewingRepo *repository.ViewingRepository
+	groupRepo   *repository.GroupRepository
+	personRepo  *repository.PersonRepository
+	tmdbClient  *tmdb.Client
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(movieRepo *repository.MovieRepository, entryRepo *repository.EntryRepository, ratingRepo *repository.RatingRepository, viewingRepo *repository.ViewingRepository, groupRepo *repository.GroupRepository, personRepo *repository.PersonRepository, tmdbClient *tmdb.Client) *ImportHandler {
+	return &ImportHandler{
+		movieRepo:   movieRepo,
+		entryRepo:   entryRepo,
+		ratingRepo:  ratingRepo,
+		viewingRepo: viewingRepo,
+		groupRepo:   groupRepo,
+		personRepo:  personRepo,
+		tmdbClient:  tmdbClient,
+	}
+}
+
+// ImportPage renders the upload form
+func (h *ImportHandler) ImportPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	groups, err := h.groupRepo.List(ctx, false)
+	if err != nil {
+		slog.Error("failed to list groups", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	currentGroup, err := h.groupRepo.GetCurrent(ctx)
+	if err != nil {
+		slog.Error("failed to get current group", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.ImportPage(persons, groups, currentGroup).Render(ctx, w)
+}
+
+// Import reads an uploaded export, matches its rows on TMDB and, unless
+// dry_run is set, adds each matched movie to the group with the person's
+// rating and a viewing on the row's date. Importing the same file twice
+// reuses the entries and viewings the first run created.
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	if err := r.ParseMultipartForm(maxImportBytes); err != nil {
+		http.Error(w, "Invalid upload: the file must be a CSV under 10 MB", http.StatusBadRequest)
+		return
+	}
+
+	personID, err := uuid.Parse(r.FormValue("person_id"))
+	if err != nil {
+		http.Error(w, "Choose whose ratings these are", http.StatusBadRequest)
+		return
+	}
+	if !canRateAs(r, personID) {
+		http.Error(w, "You can only import your own ratings", http.StatusForbidden)
+		return
+	}
+	person, err := h.personRepo.GetByID(ctx, personID)
+	if err != nil {
+		slog.Error("failed to get person", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if person == nil || person.IsArchived() {
+		http.Error(w, "Person not found", http.StatusBadRequest)
+		return
+	}
+
+	groupNumber, err := strconv.Atoi(r.FormValue("group_number"))
+	if err != nil || groupNumber < 1 {
+		http.Error(w, "Invalid group number", http.StatusBadRequest)
+		return
+	}
+	group, err := h.groupRepo.GetByNumber(ctx, groupNumber)
+	if err != nil {
+		slog.Error("failed to get group", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if group == nil {
+		http.Error(w, "Group not found", http.StatusBadRequest)
+		return
+	}
+
+	upload, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Choose a CSV file to import", http.StatusBadRequest)
+		return
+	}
+	defer upload.Close()
+
+	file, err := importer.Parse(upload)
+	if err != nil {
+		http.Error(w, "Couldn't read the file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Matching a long history on TMDB can outlast the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.Warn("failed to lift write deadline for import", "error", err)
+	}
+
+	matches, err := importer.MatchAll(ctx, h.tmdbClient, file.Rows)
+	if err != nil {
+		slog.Error("failed to match import on TMDB", "error", err)
+		http.Error(w, "Failed to look movies up on TMDB", http.StatusBadGateway)
+		return
+	}
+
+	result := partials.ImportResult{
+		Source:      file.Source,
+		Person:      person,
+		GroupNumber: groupNumber,
+		DryRun:      r.FormValue("dry_run") == "true",
+		Matches:     matches,
+	}
+	if result.DryRun {
+		partials.ImportResults(result).Render(ctx, w)
+		return
+	}
+
+	for _, match := range matches {
+		if !match.IsMatched() {
+			continue
+		}
+		if err := h.importMatch(r, match, personID, groupNumber, &result); err != nil {
+			slog.Error("failed to import row", "error", err, "line", match.Row.Line, "tmdb_id", match.Movie.ID)
+			http.Error(w, "Import stopped at line "+strconv.Itoa(match.Row.Line)+"; earlier rows were imported and importing again picks up where it left off", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Import complete!", "type": "success"}}`)
+	partials.ImportResults(result).Render(ctx, w)
+}
+
+// importMatch adds one matched row's movie, entry, viewing and rating,
+// reusing whichever already exist
+func (h *ImportHandler) importMatch(r *http.Request, match importer.Match, personID uuid.UUID, groupNumber int, result *partials.ImportResult) error {
+	ctx := r.Context()
+
+	movie, created, err := library.FindOrImportTMDBMovie(ctx, h.movieRepo, h.tmdbClient, match.Movie.ID)
+	if err != nil {
+		return err
+	}
+	if created {
+		result.MoviesAdded++
+	}
+
+	entry, err := h.entryRepo.GetByMovieAndGroup(ctx, movie.ID, groupNumber)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		entry, err = h.entryRepo.Create(ctx, model.CreateEntryInput{MovieID: movie.ID, GroupNumber: groupNumber})
+		if err != nil {
+			return err
+		}
+		result.EntriesAdded++
+	}
+
+	var viewingID *uuid.UUID
+	if match.Row.WatchedOn != nil {
+		viewing, err := h.importViewing(r, entry.ID, personID, *match.Row.WatchedOn, result)
+		if err != nil {
+			return err
+		}
+		viewingID = &viewing.ID
+	}
+
+	if match.Row.Score != nil {
+		ratings, err := h.ratingRepo.GetByEntryID(ctx, entry.ID)
+		if err != nil {
+			return err
+		}
+		changed := true
+		for _, rating := range ratings {
+			if rating.PersonID == personID && rating.Score == *match.Row.Score {
+				changed = false
+			}
+		}
+		_, err = h.ratingRepo.Upsert(ctx, model.UpsertRatingInput{
+			PersonID:  personID,
+			EntryID:   entry.ID,
+			Score:     *match.Row.Score,
+			ViewingID: viewingID,
+		})
+		if err != nil {
+			return err
+		}
+		if changed {
+			result.RatingsSaved++
+		}
+	}
+	return nil
+}
+
+// importViewing returns the entry's viewing on the given day, making sure the
+// person attended it, or records a new one attended by just them
+func (h *ImportHandler) importViewing(r *http.Request, entryID, personID uuid.UUID, watchedOn time.Time, result *partials.ImportResult) (*model.Viewing, error) {
+	ctx := r.Context()
+
+	viewings, err := h.viewingRepo.ListForEntry(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, viewing := range viewings {
+		if !viewing.WatchedOn.Equal(watchedOn) {
+			continue
+		}
+		if !viewing.Attended(personID) {
+			if _, err := h.viewingRepo.AddAttendee(ctx, entryID, viewing.ID, personID); err != nil {
+				return nil, err
+			}
+		}
+		return viewing, nil
+	}
+
+	viewing, err := h.viewingRepo.Create(ctx, model.CreateViewingInput{
+		EntryID:     entryID,
+		WatchedOn:   watchedOn,
+		AttendeeIDs: []uuid.UUID{personID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if viewing == nil {
+		return nil, errors.New("entry was deleted during import")
+	}
+	result.ViewingsAdded++
+	return viewing, nil
+}
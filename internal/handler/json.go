@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+)
+
+// isJSONRequest reports whether the request body is JSON rather than form data
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// wantsJSON reports whether the client expects a JSON response instead of HTML.
+// HTMX requests always get HTML; other clients get JSON when they send or accept it.
+func wantsJSON(r *http.Request) bool {
+	if r.Header.Get("HX-Request") == "true" {
+		return false
+	}
+	return isJSONRequest(r) || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// writeInternalError logs err and writes a generic 500 JSON API error
+func writeInternalError(w http.ResponseWriter, message string, err error) {
+	slog.Error(message, "error", err)
+	apijson.WriteError(w, http.StatusInternalServerError, apijson.CodeInternal, "Internal Server Error")
+}
+
+// decodeJSON decodes a JSON request body into v, rejecting unknown fields
+func decodeJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
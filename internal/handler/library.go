@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+const (
+	// libraryPageSize is how many entries a library page holds by default
+	libraryPageSize = 48
+	// maxLibraryPageSize caps the limit parameter
+	maxLibraryPageSize = 100
+)
+
+// LibraryHandler handles browsing the whole library
+type LibraryHandler struct {
+	entryRepo  *repository.EntryRepository
+	personRepo *repository.PersonRepository
+}
+
+// NewLibraryHandler creates a new LibraryHandler
+func NewLibraryHandler(entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository) *LibraryHandler {
+	return &LibraryHandler{
+		entryRepo:  entryRepo,
+		personRepo: personRepo,
+	}
+}
+
+// parseLibraryFilter reads the library's filter, sort and paging query parameters
+func parseLibraryFilter(r *http.Request) (model.LibraryFilter, error) {
+	query := r.URL.Query()
+	filter := model.LibraryFilter{
+		Genre: query.Get("genre"),
+		Sort:  model.LibrarySortAdded,
+		After: query.Get("after"),
+		Limit: libraryPageSize,
+	}
+
+	var err error
+	if filter.Decade, err = optionalInt(query, "decade", 0); err != nil {
+		return filter, err
+	}
+	if filter.Decade != nil && *filter.Decade%10 != 0 {
+		return filter, errors.New("decade must be a year ending in 0")
+	}
+	if filter.MinRuntime, err = optionalInt(query, "min_runtime", 0); err != nil {
+		return filter, err
+	}
+	if filter.MaxRuntime, err = optionalInt(query, "max_runtime", 0); err != nil {
+		return filter, err
+	}
+	if filter.MinScore, err = optionalScore(query, "min_score"); err != nil {
+		return filter, err
+	}
+	if filter.MaxScore, err = optionalScore(query, "max_score"); err != nil {
+		return filter, err
+	}
+
+	switch query.Get("watched") {
+	case "":
+	case "true", "false":
+		watched := query.Get("watched") == "true"
+		filter.Watched = &watched
+	default:
+		return filter, errors.New("watched must be true or false")
+	}
+
+	if pickerStr := query.Get("picker"); pickerStr != "" {
+		picker, err := uuid.Parse(pickerStr)
+		if err != nil {
+			return filter, errors.New("picker must be a person ID")
+		}
+		filter.PickedByPersonID = &picker
+	}
+
+	if sortStr := query.Get("sort"); sortStr != "" {
+		if filter.Sort, err = model.ParseLibrarySort(sortStr); err != nil {
+			return filter, err
+		}
+	}
+	switch query.Get("order") {
+	case "":
+		filter.Descending = filter.Sort.DefaultDescending()
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	limit, err := optionalInt(query, "limit", 1)
+	if err != nil {
+		return filter, err
+	}
+	if limit != nil {
+		filter.Limit = min(*limit, maxLibraryPageSize)
+	}
+
+	return filter, nil
+}
+
+// optionalInt parses an integer query parameter that must be at least minValue, or nil if it is absent
+func optionalInt(query url.Values, name string, minValue int) (*int, error) {
+	s := query.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < minValue {
+		return nil, fmt.Errorf("%s must be an integer of at least %d", name, minValue)
+	}
+	return &n, nil
+}
+
+// optionalScore parses a rating query parameter, or nil if it is absent
+func optionalScore(query url.Values, name string) (*float64, error) {
+	s := query.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil || score < 1 || score > 10 {
+		return nil, fmt.Errorf("%s must be between 1 and 10", name)
+	}
+	return &score, nil
+}
+
+// nextLibraryURL links to the page after this one, keeping the request's filters
+func nextLibraryURL(r *http.Request, page *model.LibraryPage) string {
+	if page.NextCursor == "" {
+		return ""
+	}
+	query := r.URL.Query()
+	query.Set("after", page.NextCursor)
+	return "/partials/library?" + query.Encode()
+}
+
+// LibraryPage renders the library browser with its first page of entries
+func (h *LibraryHandler) LibraryPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := parseLibraryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.entryRepo.Browse(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		slog.Error("failed to browse library", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	facets, err := h.entryRepo.LibraryFacets(ctx)
+	if err != nil {
+		slog.Error("failed to get library facets", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.LibraryPage(filter, page.Entries, nextLibraryURL(r, page), facets, persons).Render(ctx, w)
+}
+
+// LibraryRows renders the next page of entries for infinite scroll
+func (h *LibraryHandler) LibraryRows(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLibraryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.entryRepo.Browse(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		slog.Error("failed to browse library", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	partials.LibraryRows(page.Entries, nextLibraryURL(r, page)).Render(r.Context(), w)
+}
+
+// Library returns a page of library entries as JSON
+func (h *LibraryHandler) Library(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLibraryFilter(r)
+	if err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.entryRepo.Browse(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "after is not a cursor for this sort")
+			return
+		}
+		writeInternalError(w, "failed to browse library", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, page)
+}
+
+// Facets returns the genres and decades the library can be filtered by
+func (h *LibraryHandler) Facets(w http.ResponseWriter, r *http.Request) {
+	facets, err := h.entryRepo.LibraryFacets(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to get library facets", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, facets)
+}
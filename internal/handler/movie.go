@@ -1,12 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/drywaters/seenema/internal/library"
 	"github.com/drywaters/seenema/internal/model"
 	"github.com/drywaters/seenema/internal/repository"
 	"github.com/drywaters/seenema/internal/tmdb"
@@ -19,19 +19,21 @@ import (
 
 // MovieHandler handles movie-related requests
 type MovieHandler struct {
-	movieRepo  *repository.MovieRepository
-	entryRepo  *repository.EntryRepository
-	personRepo *repository.PersonRepository
-	tmdbClient *tmdb.Client
+	movieRepo      *repository.MovieRepository
+	entryRepo      *repository.EntryRepository
+	personRepo     *repository.PersonRepository
+	tmdbClient     *tmdb.Client
+	pickerRotation model.PickerRotation
 }
 
 // NewMovieHandler creates a new MovieHandler
-func NewMovieHandler(movieRepo *repository.MovieRepository, entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository, tmdbClient *tmdb.Client) *MovieHandler {
+func NewMovieHandler(movieRepo *repository.MovieRepository, entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository, tmdbClient *tmdb.Client, pickerRotation model.PickerRotation) *MovieHandler {
 	return &MovieHandler{
-		movieRepo:  movieRepo,
-		entryRepo:  entryRepo,
-		personRepo: personRepo,
-		tmdbClient: tmdbClient,
+		movieRepo:      movieRepo,
+		entryRepo:      entryRepo,
+		personRepo:     personRepo,
+		tmdbClient:     tmdbClient,
+		pickerRotation: pickerRotation,
 	}
 }
 
@@ -67,13 +69,15 @@ func (h *MovieHandler) MovieDetailPage(w http.ResponseWriter, r *http.Request) {
 	pages.MovieDetailPage(entry, persons).Render(ctx, w)
 }
 
-// SearchTMDB handles TMDB movie search
+// SearchTMDB handles TMDB movie search. With for=watchlist the results offer
+// to suggest the movie rather than add it to a group.
 func (h *MovieHandler) SearchTMDB(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	query := r.URL.Query().Get("q")
+	forWatchlist := r.URL.Query().Get("for") == "watchlist"
 
 	if query == "" {
-		partials.SearchResults(nil).Render(ctx, w)
+		partials.SearchResults(nil, forWatchlist).Render(ctx, w)
 		return
 	}
 
@@ -84,7 +88,7 @@ func (h *MovieHandler) SearchTMDB(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	partials.SearchResults(results.Results).Render(ctx, w)
+	partials.SearchResults(results.Results, forWatchlist).Render(ctx, w)
 }
 
 // AddFromTMDB adds a movie from TMDB to the library
@@ -110,68 +114,41 @@ func (h *MovieHandler) AddFromTMDB(w http.ResponseWriter, r *http.Request) {
 		groupNumber = 1
 	}
 
-	// Check if movie already exists in library
-	existingMovie, err := h.movieRepo.GetByTMDBId(ctx, tmdbID)
+	movie, _, err := library.FindOrImportTMDBMovie(ctx, h.movieRepo, h.tmdbClient, tmdbID)
 	if err != nil {
-		slog.Error("failed to check existing movie", "error", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	var movie *model.Movie
-	if existingMovie != nil {
-		movie = existingMovie
-	} else {
-		// Fetch movie details from TMDB
-		details, err := h.tmdbClient.GetMovie(ctx, tmdbID)
-		if err != nil {
-			slog.Error("failed to get TMDB movie", "error", err)
-			http.Error(w, "Failed to fetch movie details", http.StatusInternalServerError)
-			return
-		}
-		if details == nil {
+		if errors.Is(err, library.ErrTMDBMovieNotFound) {
 			http.Error(w, "Movie not found", http.StatusNotFound)
 			return
 		}
+		slog.Error("failed to import TMDB movie", "error", err, "tmdb_id", tmdbID)
+		http.Error(w, "Failed to save movie", http.StatusInternalServerError)
+		return
+	}
 
-		// Build poster URL
-		var posterURL *string
-		if details.PosterPath != nil {
-			url := h.tmdbClient.PosterURL(*details.PosterPath, "w500")
-			posterURL = &url
-		}
+	input := model.CreateEntryInput{
+		MovieID:     movie.ID,
+		GroupNumber: groupNumber,
+	}
 
-		// Store metadata as JSON
-		metadataJSON, err := json.Marshal(details)
+	// Optionally credit the pick to whoever's turn it is
+	if r.FormValue("assign_picker") == "true" {
+		turn, err := nextPicker(ctx, h.entryRepo, h.personRepo, h.pickerRotation, groupNumber)
 		if err != nil {
-			slog.Error("failed to marshal TMDB metadata", "error", err, "tmdb_id", tmdbID)
-			http.Error(w, "Failed to save movie metadata", http.StatusInternalServerError)
+			slog.Error("failed to work out next picker", "error", err)
+			http.Error(w, "Failed to create entry", http.StatusInternalServerError)
 			return
 		}
-
-		// Create movie in database
-		movie, err = h.movieRepo.Create(ctx, model.CreateMovieInput{
-			Title:          details.Title,
-			ReleaseYear:    tmdb.ReleaseYear(details.ReleaseDate),
-			PosterURL:      posterURL,
-			Synopsis:       &details.Overview,
-			RuntimeMinutes: &details.Runtime,
-			TMDBId:         &tmdbID,
-			IMDBId:         details.IMDBId,
-			MetadataJSON:   metadataJSON,
-		})
-		if err != nil {
-			slog.Error("failed to create movie", "error", err)
-			http.Error(w, "Failed to save movie", http.StatusInternalServerError)
-			return
+		if turn.Person != nil {
+			input.PickedByPersonID = &turn.Person.ID
 		}
 	}
 
 	// Create entry for this movie
-	entry, err := h.entryRepo.Create(ctx, model.CreateEntryInput{
-		MovieID:     movie.ID,
-		GroupNumber: groupNumber,
-	})
+	entry, err := h.entryRepo.Create(ctx, input)
+	if errors.Is(err, repository.ErrUnknownGroup) {
+		http.Error(w, "No such group; use an existing group or the next new one", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -197,4 +174,3 @@ func (h *MovieHandler) AddFromTMDB(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Movie added!", "type": "success"}, "refreshGroups": true}`)
 	w.WriteHeader(http.StatusOK)
 }
-
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PersonHandler handles family member management
+type PersonHandler struct {
+	personRepo *repository.PersonRepository
+}
+
+// NewPersonHandler creates a new PersonHandler
+func NewPersonHandler(personRepo *repository.PersonRepository) *PersonHandler {
+	return &PersonHandler{
+		personRepo: personRepo,
+	}
+}
+
+// SettingsPage renders the settings page with the family member list
+func (h *PersonHandler) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	persons, err := h.personRepo.ListAll(r.Context())
+	if err != nil {
+		slog.Error("failed to list persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.SettingsPage(persons).Render(r.Context(), w)
+}
+
+// List returns every person, including archived ones, as JSON
+func (h *PersonHandler) List(w http.ResponseWriter, r *http.Request) {
+	persons, err := h.personRepo.ListAll(r.Context())
+	if err != nil {
+		slog.Error("failed to list persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if persons == nil {
+		persons = []*model.Person{}
+	}
+
+	apijson.Write(w, http.StatusOK, persons)
+}
+
+// Create adds a new person
+func (h *PersonHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input model.CreatePersonInput
+	if isJSONRequest(r) {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		input.Initial = r.FormValue("initial")
+		input.Name = r.FormValue("name")
+	}
+
+	initial, ok := model.NormalizeInitial(input.Initial)
+	if !ok {
+		http.Error(w, "Initial must be a single character", http.StatusBadRequest)
+		return
+	}
+	input.Initial = initial
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	person, err := h.personRepo.Create(ctx, input)
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, "Initial is already in use", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to create person", "error", err)
+		http.Error(w, "Failed to create person", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		apijson.Write(w, http.StatusCreated, person)
+		return
+	}
+	h.renderList(w, r, "Person added!")
+}
+
+// Update renames a person or changes their initial
+func (h *PersonHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	personID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+
+	var input model.UpdatePersonInput
+	if isJSONRequest(r) {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		if initial := r.FormValue("initial"); initial != "" {
+			input.Initial = &initial
+		}
+		if name := r.FormValue("name"); name != "" {
+			input.Name = &name
+		}
+	}
+
+	if input.Initial != nil {
+		initial, ok := model.NormalizeInitial(*input.Initial)
+		if !ok {
+			http.Error(w, "Initial must be a single character", http.StatusBadRequest)
+			return
+		}
+		input.Initial = &initial
+	}
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			http.Error(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+		input.Name = &name
+	}
+
+	person, err := h.personRepo.Update(ctx, personID, input)
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, "Initial is already in use", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to update person", "error", err)
+		http.Error(w, "Failed to update person", http.StatusInternalServerError)
+		return
+	}
+	if person == nil {
+		http.Error(w, "Person not found", http.StatusNotFound)
+		return
+	}
+
+	if wantsJSON(r) {
+		apijson.Write(w, http.StatusOK, person)
+		return
+	}
+	h.renderList(w, r, "Person updated!")
+}
+
+// Archive hides a person from rating grids without deleting their ratings
+func (h *PersonHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+// Unarchive restores an archived person
+func (h *PersonHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *PersonHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	ctx := r.Context()
+
+	personID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+
+	person, err := h.personRepo.GetByID(ctx, personID)
+	if err != nil {
+		slog.Error("failed to get person", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if person == nil {
+		http.Error(w, "Person not found", http.StatusNotFound)
+		return
+	}
+
+	message := "Person archived!"
+	if archived {
+		err = h.personRepo.Archive(ctx, personID)
+	} else {
+		err = h.personRepo.Unarchive(ctx, personID)
+		message = "Person restored!"
+	}
+	if err != nil {
+		slog.Error("failed to change person archive state", "error", err, "person_id", personID, "archived", archived)
+		http.Error(w, "Failed to update person", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		person, err = h.personRepo.GetByID(ctx, personID)
+		if err != nil {
+			slog.Error("failed to get person", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		apijson.Write(w, http.StatusOK, person)
+		return
+	}
+	h.renderList(w, r, message)
+}
+
+// ReorderPersonsRequest represents the JSON body for reordering persons
+type ReorderPersonsRequest struct {
+	PersonIDs []string `json:"person_ids"`
+}
+
+// Reorder updates the display order of persons
+func (h *PersonHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req ReorderPersonsRequest
+	if isJSONRequest(r) {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data", http.StatusBadRequest)
+			return
+		}
+		req.PersonIDs = r.Form["person_ids"]
+	}
+
+	personIDs := make([]uuid.UUID, 0, len(req.PersonIDs))
+	for _, idStr := range req.PersonIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			slog.Warn("invalid person id in reorder request", "person_id", idStr, "error", err)
+			http.Error(w, "Invalid person ID", http.StatusBadRequest)
+			return
+		}
+		personIDs = append(personIDs, id)
+	}
+
+	if err := h.personRepo.Reorder(ctx, personIDs); err != nil {
+		if errors.Is(err, repository.ErrPersonsMismatch) {
+			http.Error(w, "The person list has changed; reload and try again", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to reorder persons", "error", err)
+		http.Error(w, "Failed to reorder persons", http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.renderList(w, r, "Order updated!")
+}
+
+// renderList renders the settings person list with a success toast
+func (h *PersonHandler) renderList(w http.ResponseWriter, r *http.Request, message string) {
+	persons, err := h.personRepo.ListAll(r.Context())
+	if err != nil {
+		slog.Error("failed to list persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	partials.PersonList(persons).Render(r.Context(), w)
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
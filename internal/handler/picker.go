@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+)
+
+// nextPicker works out whose turn it is to pick in a group
+func nextPicker(ctx context.Context, entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository, rotation model.PickerRotation, groupNumber int) (*model.PickerTurn, error) {
+	persons, err := personRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get persons: %w", err)
+	}
+
+	stats, err := entryRepo.PickerStats(ctx, groupNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NextPicker(rotation, persons, stats, groupNumber), nil
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/drywaters/seenema/internal/auth"
 	"github.com/drywaters/seenema/internal/model"
 	"github.com/drywaters/seenema/internal/repository"
 	"github.com/drywaters/seenema/internal/ui/partials"
@@ -39,14 +40,31 @@ func (h *RatingHandler) SaveRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	personIDStr := r.FormValue("person_id")
 	entryIDStr := r.FormValue("entry_id")
 	scoreStr := r.FormValue("score")
 
-	personID, err := uuid.Parse(personIDStr)
-	if err != nil {
-		http.Error(w, "Invalid person ID", http.StatusBadRequest)
-		return
+	// Ratings are attributed to the signed-in person. Only token-authenticated
+	// clients, which have no person, may choose whom the rating is for.
+	var personID uuid.UUID
+	if current := auth.PersonFromContext(ctx); current != nil {
+		personID = current.ID
+	} else {
+		var err error
+		personID, err = uuid.Parse(r.FormValue("person_id"))
+		if err != nil {
+			http.Error(w, "Invalid person ID", http.StatusBadRequest)
+			return
+		}
+		person, err := h.personRepo.GetByID(ctx, personID)
+		if err != nil {
+			slog.Error("failed to get person", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if person == nil || person.IsArchived() {
+			http.Error(w, "Person not found", http.StatusBadRequest)
+			return
+		}
 	}
 
 	entryID, err := uuid.Parse(entryIDStr)
@@ -61,11 +79,28 @@ func (h *RatingHandler) SaveRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.ratingRepo.Upsert(ctx, model.UpsertRatingInput{
+	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	if err != nil {
+		slog.Error("failed to get entry", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	// The score is for the latest viewing the person was at
+	input := model.UpsertRatingInput{
 		PersonID: personID,
 		EntryID:  entryID,
 		Score:    score,
-	})
+	}
+	if viewing := entry.LatestViewingWith(personID); viewing != nil {
+		input.ViewingID = &viewing.ID
+	}
+
+	_, err = h.ratingRepo.Upsert(ctx, input)
 	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return
@@ -76,7 +111,7 @@ func (h *RatingHandler) SaveRating(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return updated ratings section
-	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	entry, err = h.entryRepo.GetByID(ctx, entryID)
 	if err != nil {
 		slog.Error("failed to get entry", "error", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
@@ -98,8 +133,15 @@ func (h *RatingHandler) SaveRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Rating saved!", "type": "success"}}`)
-	partials.RatingRowUpdate(entry, person).Render(ctx, w)
+	partials.RatingRowUpdate(entry, person, persons).Render(ctx, w)
 }
 
 // DeleteRating removes a rating
@@ -114,6 +156,10 @@ func (h *RatingHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid person ID", http.StatusBadRequest)
 		return
 	}
+	if !canRateAs(r, personID) {
+		http.Error(w, "You can only change your own rating", http.StatusForbidden)
+		return
+	}
 
 	entryID, err := uuid.Parse(entryIDStr)
 	if err != nil {
@@ -153,8 +199,16 @@ func (h *RatingHandler) DeleteRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Rating deleted!", "type": "success"}}`)
-	partials.RatingRowUpdate(entry, person).Render(ctx, w)
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	undoPath := "/api/ratings/" + personID.String() + "/" + entryID.String() + "/restore"
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Rating deleted!", "type": "success", "undo": "`+undoPath+`"}}`)
+	partials.RatingRowUpdate(entry, person, persons).Render(ctx, w)
 }
 
 // RatingForm renders the rating input form for a specific person/entry
@@ -175,6 +229,10 @@ func (h *RatingHandler) RatingForm(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid person ID", http.StatusBadRequest)
 		return
 	}
+	if !canRateAs(r, personID) {
+		http.Error(w, "You can only change your own rating", http.StatusForbidden)
+		return
+	}
 
 	entry, err := h.entryRepo.GetByID(ctx, entryID)
 	if err != nil {
@@ -209,3 +267,10 @@ func (h *RatingHandler) RatingForm(w http.ResponseWriter, r *http.Request) {
 
 	partials.RatingInputForm(entry.ID, person, existingScore).Render(ctx, w)
 }
+
+// canRateAs reports whether the request may change personID's rating.
+// Signed-in persons can only change their own; token clients can change any.
+func canRateAs(r *http.Request, personID uuid.UUID) bool {
+	current := auth.PersonFromContext(r.Context())
+	return current == nil || current.ID == personID
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// SearchHandler handles full-text search of the library
+type SearchHandler struct {
+	entryRepo  *repository.EntryRepository
+	groupRepo  *repository.GroupRepository
+	personRepo *repository.PersonRepository
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(entryRepo *repository.EntryRepository, groupRepo *repository.GroupRepository, personRepo *repository.PersonRepository) *SearchHandler {
+	return &SearchHandler{
+		entryRepo:  entryRepo,
+		groupRepo:  groupRepo,
+		personRepo: personRepo,
+	}
+}
+
+const (
+	// searchPageSize is how many results a search returns by default
+	searchPageSize = 50
+	// maxSearchPageSize caps the limit parameter
+	maxSearchPageSize = 100
+)
+
+// parseEntrySearch reads the q, watched, group, rated_by, limit and offset query parameters
+func parseEntrySearch(r *http.Request) (model.EntrySearch, error) {
+	query := r.URL.Query()
+	search := model.EntrySearch{
+		Query: strings.TrimSpace(query.Get("q")),
+		Limit: searchPageSize,
+	}
+
+	switch query.Get("watched") {
+	case "":
+	case "true", "false":
+		watched := query.Get("watched") == "true"
+		search.Watched = &watched
+	default:
+		return search, errors.New("watched must be true or false")
+	}
+
+	if groupStr := query.Get("group"); groupStr != "" {
+		group, err := strconv.Atoi(groupStr)
+		if err != nil || group < 1 {
+			return search, errors.New("group must be a positive integer")
+		}
+		search.GroupNumber = &group
+	}
+
+	if ratedByStr := query.Get("rated_by"); ratedByStr != "" {
+		ratedBy, err := uuid.Parse(ratedByStr)
+		if err != nil {
+			return search, errors.New("rated_by must be a person ID")
+		}
+		search.RatedByPersonID = &ratedBy
+	}
+
+	limit, err := optionalInt(query, "limit", 1)
+	if err != nil {
+		return search, err
+	}
+	if limit != nil {
+		search.Limit = min(*limit, maxSearchPageSize)
+	}
+
+	offset, err := optionalInt(query, "offset", 0)
+	if err != nil {
+		return search, err
+	}
+	if offset != nil {
+		search.Offset = *offset
+	}
+
+	return search, nil
+}
+
+// SearchPage renders the search page, with results when a query is given
+func (h *SearchHandler) SearchPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	search, err := parseEntrySearch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groups, err := h.groupRepo.List(ctx, true)
+	if err != nil {
+		slog.Error("failed to list groups", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	var results []*model.Entry
+	if search.Query != "" {
+		results, err = h.entryRepo.Search(ctx, search)
+		if err != nil {
+			slog.Error("failed to search entries", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	pages.SearchPage(search, results, groups, persons).Render(ctx, w)
+}
+
+// SearchResults renders just the results for HTMX updates
+func (h *SearchHandler) SearchResults(w http.ResponseWriter, r *http.Request) {
+	search, err := parseEntrySearch(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var results []*model.Entry
+	if search.Query != "" {
+		results, err = h.entryRepo.Search(r.Context(), search)
+		if err != nil {
+			slog.Error("failed to search entries", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	partials.LibrarySearchResults(search.Query, results).Render(r.Context(), w)
+}
+
+// Search returns matching entries as JSON, best match first
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	search, err := parseEntrySearch(r)
+	if err != nil {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, err.Error())
+		return
+	}
+	if search.Query == "" {
+		apijson.WriteError(w, http.StatusBadRequest, apijson.CodeBadRequest, "q is required")
+		return
+	}
+
+	results, err := h.entryRepo.Search(r.Context(), search)
+	if err != nil {
+		writeInternalError(w, "failed to search entries", err)
+		return
+	}
+	if results == nil {
+		results = []*model.Entry{}
+	}
+
+	apijson.Write(w, http.StatusOK, results)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SessionHandler handles listing and revoking a person's sign-in sessions
+type SessionHandler struct {
+	sessionRepo   *repository.SessionRepository
+	secureCookies bool
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(sessionRepo *repository.SessionRepository, secureCookies bool) *SessionHandler {
+	return &SessionHandler{
+		sessionRepo:   sessionRepo,
+		secureCookies: secureCookies,
+	}
+}
+
+// SessionsPage renders the signed-in person's active sessions
+func (h *SessionHandler) SessionsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	person := auth.PersonFromContext(ctx)
+	if person == nil {
+		http.Error(w, "Sign in as a family member to manage sessions", http.StatusForbidden)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListByPerson(ctx, person.ID)
+	if err != nil {
+		slog.Error("failed to list sessions", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.SessionsPage(sessions, auth.SessionIDFromContext(ctx)).Render(ctx, w)
+}
+
+// Revoke signs out one of the signed-in person's sessions
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	person := auth.PersonFromContext(ctx)
+	if person == nil {
+		http.Error(w, "Sign in as a family member to manage sessions", http.StatusForbidden)
+		return
+	}
+
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.sessionRepo.DeleteByID(ctx, person.ID, sessionID)
+	if err != nil {
+		slog.Error("failed to revoke session", "error", err)
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	// Revoking this browser's own session is the same as logging out
+	if sessionID == auth.SessionIDFromContext(ctx) {
+		h.signOut(w)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListByPerson(ctx, person.ID)
+	if err != nil {
+		slog.Error("failed to list sessions", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "Session signed out!", "type": "success"}}`)
+	partials.SessionList(sessions, auth.SessionIDFromContext(ctx)).Render(ctx, w)
+}
+
+// RevokeAll signs the person out of every device, including this one
+func (h *SessionHandler) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	person := auth.PersonFromContext(ctx)
+	if person == nil {
+		http.Error(w, "Sign in as a family member to manage sessions", http.StatusForbidden)
+		return
+	}
+
+	count, err := h.sessionRepo.DeleteByPerson(ctx, person.ID)
+	if err != nil {
+		slog.Error("failed to revoke sessions", "error", err)
+		http.Error(w, "Failed to sign out devices", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("signed out all devices", "person_id", person.ID, "sessions", count)
+
+	h.signOut(w)
+}
+
+// signOut clears the session cookie and sends the browser to the login page
+func (h *SessionHandler) signOut(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.Header().Set("HX-Redirect", "/login")
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+)
+
+// StatsHandler handles watch-history statistics
+type StatsHandler struct {
+	statsRepo *repository.StatsRepository
+}
+
+// NewStatsHandler creates a new StatsHandler
+func NewStatsHandler(statsRepo *repository.StatsRepository) *StatsHandler {
+	return &StatsHandler{
+		statsRepo: statsRepo,
+	}
+}
+
+// StatsPage renders the statistics page
+func (h *StatsHandler) StatsPage(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statsRepo.GetStats(r.Context())
+	if err != nil {
+		slog.Error("failed to get stats", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.StatsPage(stats).Render(r.Context(), w)
+}
+
+// Stats returns the statistics as JSON
+func (h *StatsHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.statsRepo.GetStats(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to get stats", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, stats)
+}
+
+// TastePage renders the taste comparison page
+func (h *StatsHandler) TastePage(w http.ResponseWriter, r *http.Request) {
+	comparison, err := h.statsRepo.GetTasteComparison(r.Context())
+	if err != nil {
+		slog.Error("failed to get taste comparison", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.TastePage(comparison).Render(r.Context(), w)
+}
+
+// Taste returns the taste comparison as JSON
+func (h *StatsHandler) Taste(w http.ResponseWriter, r *http.Request) {
+	comparison, err := h.statsRepo.GetTasteComparison(r.Context())
+	if err != nil {
+		writeInternalError(w, "failed to get taste comparison", err)
+		return
+	}
+
+	apijson.Write(w, http.StatusOK, comparison)
+}
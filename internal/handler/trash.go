@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// TrashHandler handles the trash page and restoring deleted items
+type TrashHandler struct {
+	trashRepo *repository.TrashRepository
+	retention time.Duration
+}
+
+// NewTrashHandler creates a new TrashHandler
+func NewTrashHandler(trashRepo *repository.TrashRepository, retention time.Duration) *TrashHandler {
+	return &TrashHandler{
+		trashRepo: trashRepo,
+		retention: retention,
+	}
+}
+
+// TrashPage renders the deleted movies, entries and ratings that can still be restored
+func (h *TrashHandler) TrashPage(w http.ResponseWriter, r *http.Request) {
+	items, err := h.trashRepo.List(r.Context(), h.retention)
+	if err != nil {
+		slog.Error("failed to list trash", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.TrashPage(items, h.retention).Render(r.Context(), w)
+}
+
+// RestoreEntry brings back a deleted entry with its ratings
+func (h *TrashHandler) RestoreEntry(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.trashRepo.RestoreEntry(r.Context(), entryID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, "That movie is already in the group again", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to restore entry", "error", err, "entry_id", entryID)
+		http.Error(w, "Failed to restore entry", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Entry not found in trash", http.StatusNotFound)
+		return
+	}
+
+	h.restored(w, r, "Entry restored!")
+}
+
+// RestoreMovie brings back a deleted movie with the entries deleted along with it
+func (h *TrashHandler) RestoreMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.trashRepo.RestoreMovie(r.Context(), movieID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, "That movie is already in one of its groups again", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to restore movie", "error", err, "movie_id", movieID)
+		http.Error(w, "Failed to restore movie", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Movie not found in trash", http.StatusNotFound)
+		return
+	}
+
+	h.restored(w, r, "Movie restored!")
+}
+
+// RestoreRating brings back a deleted rating
+func (h *TrashHandler) RestoreRating(w http.ResponseWriter, r *http.Request) {
+	personID, err := uuid.Parse(chi.URLParam(r, "personId"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+	if !canRateAs(r, personID) {
+		http.Error(w, "You can only change your own rating", http.StatusForbidden)
+		return
+	}
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryId"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.trashRepo.RestoreRating(r.Context(), personID, entryID)
+	if err != nil {
+		slog.Error("failed to restore rating", "error", err, "person_id", personID, "entry_id", entryID)
+		http.Error(w, "Failed to restore rating", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Rating not found in trash", http.StatusNotFound)
+		return
+	}
+
+	h.restored(w, r, "Rating restored!")
+}
+
+// restored finishes a restore. The Undo button in a delete toast posts
+// undo=true and gets the page reloaded, since it may be showing anything;
+// the trash page gets its list back with a toast.
+func (h *TrashHandler) restored(w http.ResponseWriter, r *http.Request, message string) {
+	if r.FormValue("undo") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	items, err := h.trashRepo.List(r.Context(), h.retention)
+	if err != nil {
+		slog.Error("failed to list trash", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	partials.TrashList(items).Render(r.Context(), w)
+}
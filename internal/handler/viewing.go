@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ViewingHandler handles recording when entries were watched
+type ViewingHandler struct {
+	viewingRepo *repository.ViewingRepository
+	entryRepo   *repository.EntryRepository
+	personRepo  *repository.PersonRepository
+}
+
+// NewViewingHandler creates a new ViewingHandler
+func NewViewingHandler(viewingRepo *repository.ViewingRepository, entryRepo *repository.EntryRepository, personRepo *repository.PersonRepository) *ViewingHandler {
+	return &ViewingHandler{
+		viewingRepo: viewingRepo,
+		entryRepo:   entryRepo,
+		personRepo:  personRepo,
+	}
+}
+
+// MarkWatched records a viewing with everyone present, today unless
+// watched_at is given. Nothing changes if there is already a viewing that day.
+func (h *ViewingHandler) MarkWatched(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	watchedOn := time.Now()
+	if dateStr := r.FormValue("watched_at"); dateStr != "" {
+		watchedOn, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			http.Error(w, "Invalid date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	viewings, err := h.viewingRepo.ListForEntry(ctx, entryID)
+	if err != nil {
+		slog.Error("failed to list viewings", "error", err, "entry_id", entryID)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	day := watchedOn.Format("2006-01-02")
+	for _, viewing := range viewings {
+		if viewing.WatchedOn.Format("2006-01-02") == day {
+			h.renderViewings(w, r, entryID, "Already marked as watched that day")
+			return
+		}
+	}
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.create(w, r, model.CreateViewingInput{
+		EntryID:     entryID,
+		WatchedOn:   watchedOn,
+		AttendeeIDs: personIDs(persons),
+	}, "Marked as watched!")
+}
+
+// AddViewing records a viewing from the form on the movie detail page
+func (h *ViewingHandler) AddViewing(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	watchedOn, err := time.Parse("2006-01-02", r.FormValue("watched_on"))
+	if err != nil {
+		http.Error(w, "Invalid date", http.StatusBadRequest)
+		return
+	}
+
+	input := model.CreateViewingInput{
+		EntryID:     entryID,
+		WatchedOn:   watchedOn,
+		AttendeeIDs: []uuid.UUID{},
+	}
+	if location := strings.TrimSpace(r.FormValue("location")); location != "" {
+		input.Location = &location
+	}
+	if note := strings.TrimSpace(r.FormValue("note")); note != "" {
+		input.Note = &note
+	}
+	for _, idStr := range r.Form["attendee"] {
+		personID, err := uuid.Parse(idStr)
+		if err != nil {
+			http.Error(w, "Invalid person ID", http.StatusBadRequest)
+			return
+		}
+		input.AttendeeIDs = append(input.AttendeeIDs, personID)
+	}
+
+	h.create(w, r, input, "Viewing added!")
+}
+
+// DeleteViewing removes one viewing of an entry
+func (h *ViewingHandler) DeleteViewing(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+	viewingID, err := uuid.Parse(chi.URLParam(r, "viewingId"))
+	if err != nil {
+		http.Error(w, "Invalid viewing ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.viewingRepo.Delete(r.Context(), entryID, viewingID)
+	if err != nil {
+		slog.Error("failed to delete viewing", "error", err, "viewing_id", viewingID)
+		http.Error(w, "Failed to delete viewing", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Viewing not found", http.StatusNotFound)
+		return
+	}
+
+	h.renderViewings(w, r, entryID, "Viewing removed!")
+}
+
+// ClearWatched removes the latest viewing, undoing the last mark as watched.
+// Earlier viewings are kept; the entry is unwatched once none are left.
+func (h *ViewingHandler) ClearWatched(w http.ResponseWriter, r *http.Request) {
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.viewingRepo.DeleteLatest(r.Context(), entryID); err != nil {
+		slog.Error("failed to remove latest viewing", "error", err)
+		http.Error(w, "Failed to remove the latest viewing", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderViewings(w, r, entryID, "Latest viewing removed!")
+}
+
+// SetAttendance marks a person present at or absent from an entry's viewings
+func (h *ViewingHandler) SetAttendance(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	entryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid entry ID", http.StatusBadRequest)
+		return
+	}
+	personID, err := uuid.Parse(chi.URLParam(r, "personId"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+	present := r.FormValue("present") == "true"
+
+	found, err := h.viewingRepo.SetAttendance(ctx, entryID, personID, present)
+	if err != nil {
+		slog.Error("failed to set attendance", "error", err, "entry_id", entryID, "person_id", personID)
+		http.Error(w, "Failed to update attendance", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Mark the movie as watched first", http.StatusConflict)
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	if err != nil {
+		slog.Error("failed to get entry", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	partials.AttendanceUpdate(entry, persons).Render(ctx, w)
+}
+
+// create records a viewing and renders the updated timeline
+func (h *ViewingHandler) create(w http.ResponseWriter, r *http.Request, input model.CreateViewingInput, message string) {
+	viewing, err := h.viewingRepo.Create(r.Context(), input)
+	if err != nil {
+		slog.Error("failed to add viewing", "error", err, "entry_id", input.EntryID)
+		http.Error(w, "Failed to add viewing", http.StatusInternalServerError)
+		return
+	}
+	if viewing == nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	h.renderViewings(w, r, input.EntryID, message)
+}
+
+// renderViewings responds with the entry's viewing timeline, the rating grid
+// whose attendance it decides, and a toast
+func (h *ViewingHandler) renderViewings(w http.ResponseWriter, r *http.Request, entryID uuid.UUID, message string) {
+	ctx := r.Context()
+
+	entry, err := h.entryRepo.GetByID(ctx, entryID)
+	if err != nil {
+		slog.Error("failed to get entry", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if entry == nil {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	persons, err := h.personRepo.GetAll(ctx)
+	if err != nil {
+		slog.Error("failed to get persons", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	partials.ViewingsUpdate(entry, persons).Render(ctx, w)
+}
+
+// personIDs returns the IDs of the given persons
+func personIDs(persons []*model.Person) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(persons))
+	for _, person := range persons {
+		ids = append(ids, person.ID)
+	}
+	return ids
+}
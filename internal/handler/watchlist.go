@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/library"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/tmdb"
+	"github.com/drywaters/seenema/internal/ui/pages"
+	"github.com/drywaters/seenema/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// WatchlistHandler handles the family watchlist of suggested movies
+type WatchlistHandler struct {
+	watchlistRepo *repository.WatchlistRepository
+	movieRepo     *repository.MovieRepository
+	entryRepo     *repository.EntryRepository
+	groupRepo     *repository.GroupRepository
+	personRepo    *repository.PersonRepository
+	tmdbClient    *tmdb.Client
+}
+
+// NewWatchlistHandler creates a new WatchlistHandler
+func NewWatchlistHandler(watchlistRepo *repository.WatchlistRepository, movieRepo *repository.MovieRepository, entryRepo *repository.EntryRepository, groupRepo *repository.GroupRepository, personRepo *repository.PersonRepository, tmdbClient *tmdb.Client) *WatchlistHandler {
+	return &WatchlistHandler{
+		watchlistRepo: watchlistRepo,
+		movieRepo:     movieRepo,
+		entryRepo:     entryRepo,
+		groupRepo:     groupRepo,
+		personRepo:    personRepo,
+		tmdbClient:    tmdbClient,
+	}
+}
+
+// WatchlistPage renders the watchlist with a TMDB search for suggesting movies
+func (h *WatchlistHandler) WatchlistPage(w http.ResponseWriter, r *http.Request) {
+	view, err := h.watchlistView(r)
+	if err != nil {
+		slog.Error("failed to load watchlist", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	pages.WatchlistPage(view).Render(r.Context(), w)
+}
+
+// Suggest puts a TMDB movie on the watchlist. The signed-in person is the
+// suggester; otherwise person_id names them.
+func (h *WatchlistHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	tmdbID, err := strconv.Atoi(r.FormValue("tmdb_id"))
+	if err != nil {
+		http.Error(w, "Invalid TMDB ID", http.StatusBadRequest)
+		return
+	}
+
+	input := model.CreateSuggestionInput{Reason: optionalText(r.FormValue("reason"))}
+	if current := auth.PersonFromContext(ctx); current != nil {
+		input.SuggestedByPersonID = &current.ID
+	} else if idStr := r.FormValue("person_id"); idStr != "" {
+		personID, err := uuid.Parse(idStr)
+		if err != nil {
+			http.Error(w, "Invalid person ID", http.StatusBadRequest)
+			return
+		}
+		person, err := h.personRepo.GetByID(ctx, personID)
+		if err != nil {
+			slog.Error("failed to get person", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if person == nil {
+			http.Error(w, "Person not found", http.StatusBadRequest)
+			return
+		}
+		input.SuggestedByPersonID = &person.ID
+	}
+
+	movie, _, err := library.FindOrImportTMDBMovie(ctx, h.movieRepo, h.tmdbClient, tmdbID)
+	if err != nil {
+		if errors.Is(err, library.ErrTMDBMovieNotFound) {
+			http.Error(w, "Movie not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("failed to import TMDB movie", "error", err, "tmdb_id", tmdbID)
+		http.Error(w, "Failed to save movie", http.StatusInternalServerError)
+		return
+	}
+	input.MovieID = movie.ID
+
+	if _, err := h.watchlistRepo.Create(ctx, input); err != nil {
+		if isUniqueViolation(err) {
+			http.Error(w, "That movie is already on the watchlist", http.StatusConflict)
+			return
+		}
+		slog.Error("failed to suggest movie", "error", err, "movie_id", movie.ID)
+		http.Error(w, "Failed to suggest movie", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderList(w, r, "Movie suggested!")
+}
+
+// Withdraw takes a suggestion off the watchlist
+func (h *WatchlistHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	suggestionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid suggestion ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.watchlistRepo.Delete(r.Context(), suggestionID)
+	if err != nil {
+		slog.Error("failed to withdraw suggestion", "error", err, "suggestion_id", suggestionID)
+		http.Error(w, "Failed to withdraw suggestion", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Suggestion not found", http.StatusNotFound)
+		return
+	}
+
+	h.renderList(w, r, "Suggestion withdrawn")
+}
+
+// Vote upvotes a suggestion for a person
+func (h *WatchlistHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	h.setVote(w, r, true)
+}
+
+// Unvote withdraws a person's upvote
+func (h *WatchlistHandler) Unvote(w http.ResponseWriter, r *http.Request) {
+	h.setVote(w, r, false)
+}
+
+func (h *WatchlistHandler) setVote(w http.ResponseWriter, r *http.Request, vote bool) {
+	ctx := r.Context()
+
+	suggestionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid suggestion ID", http.StatusBadRequest)
+		return
+	}
+	personID, err := uuid.Parse(chi.URLParam(r, "personId"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+	if !canRateAs(r, personID) {
+		http.Error(w, "You can only vote for yourself", http.StatusForbidden)
+		return
+	}
+
+	suggestion, err := h.watchlistRepo.GetByID(ctx, suggestionID)
+	if err != nil {
+		slog.Error("failed to get suggestion", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if suggestion == nil || suggestion.PromotedAt != nil {
+		http.Error(w, "Suggestion not found", http.StatusNotFound)
+		return
+	}
+	if suggestion.IsSuggestedBy(personID) {
+		http.Error(w, "You can't upvote your own suggestion", http.StatusBadRequest)
+		return
+	}
+
+	if vote {
+		_, err = h.watchlistRepo.Vote(ctx, suggestionID, personID)
+	} else {
+		_, err = h.watchlistRepo.Unvote(ctx, suggestionID, personID)
+	}
+	if err != nil {
+		slog.Error("failed to change vote", "error", err, "suggestion_id", suggestionID, "vote", vote)
+		http.Error(w, "Failed to save vote", http.StatusInternalServerError)
+		return
+	}
+
+	view, err := h.watchlistView(r)
+	if err != nil {
+		slog.Error("failed to load watchlist", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	partials.Watchlist(view).Render(ctx, w)
+}
+
+// Promote turns a suggestion into an entry in a group, the current one unless
+// group_number is given, and takes it off the watchlist
+func (h *WatchlistHandler) Promote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	suggestionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid suggestion ID", http.StatusBadRequest)
+		return
+	}
+
+	groupNumber, err := strconv.Atoi(r.FormValue("group_number"))
+	if err != nil {
+		if groupNumber, err = h.groupRepo.GetCurrent(ctx); err != nil {
+			slog.Error("failed to get current group", "error", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	suggestion, err := h.watchlistRepo.GetByID(ctx, suggestionID)
+	if err != nil {
+		slog.Error("failed to get suggestion", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if suggestion == nil || suggestion.PromotedAt != nil {
+		http.Error(w, "Suggestion not found", http.StatusNotFound)
+		return
+	}
+
+	entry, err := promoteSuggestion(r, h.entryRepo, h.watchlistRepo, suggestion, groupNumber)
+	if err != nil {
+		if status, _, message, ok := placementError(err); ok {
+			http.Error(w, message, status)
+			return
+		}
+		slog.Error("failed to promote suggestion", "error", err, "suggestion_id", suggestionID)
+		http.Error(w, "Failed to add movie to group", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderList(w, r, "Added to group "+strconv.Itoa(entry.GroupNumber)+"!")
+}
+
+// promoteSuggestion creates the entry for a suggestion and takes it off the watchlist
+func promoteSuggestion(r *http.Request, entryRepo *repository.EntryRepository, watchlistRepo *repository.WatchlistRepository, suggestion *model.Suggestion, groupNumber int) (*model.Entry, error) {
+	entry, err := entryRepo.Create(r.Context(), model.CreateEntryInput{
+		MovieID:     suggestion.MovieID,
+		GroupNumber: groupNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := watchlistRepo.MarkPromoted(r.Context(), suggestion.ID, entry.ID); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// renderList renders the watchlist with a success toast
+func (h *WatchlistHandler) renderList(w http.ResponseWriter, r *http.Request, message string) {
+	view, err := h.watchlistView(r)
+	if err != nil {
+		slog.Error("failed to load watchlist", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Trigger", `{"showToast": {"message": "`+message+`", "type": "success"}}`)
+	partials.Watchlist(view).Render(r.Context(), w)
+}
+
+// watchlistView loads the suggestions along with the persons who can vote and
+// the groups suggestions can be promoted to
+func (h *WatchlistHandler) watchlistView(r *http.Request) (partials.WatchlistView, error) {
+	ctx := r.Context()
+	view := partials.WatchlistView{}
+
+	var err error
+	if view.Suggestions, err = h.watchlistRepo.List(ctx); err != nil {
+		return view, err
+	}
+	if view.Persons, err = h.personRepo.GetAll(ctx); err != nil {
+		return view, err
+	}
+	groups, err := h.groupRepo.List(ctx, false)
+	if err != nil {
+		return view, err
+	}
+	view.Groups = groups
+	if view.CurrentGroup, err = h.groupRepo.GetCurrent(ctx); err != nil {
+		return view, err
+	}
+	return view, nil
+}
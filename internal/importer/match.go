@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/drywaters/seenema/internal/tmdb"
+)
+
+// matchWorkers is how many rows are looked up on TMDB at once
+const matchWorkers = 8
+
+// Match pairs a row with the TMDB movie it refers to
+type Match struct {
+	Row   Row
+	Movie *tmdb.SearchResult // nil = unmatched
+}
+
+// IsMatched returns true if the row was found on TMDB
+func (m Match) IsMatched() bool {
+	return m.Movie != nil
+}
+
+// MatchAll looks every row up on TMDB, a few at a time, keeping file order.
+// Rows with a Skip reason are left unmatched without a lookup.
+func MatchAll(ctx context.Context, client *tmdb.Client, rows []Row) ([]Match, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	matches := make([]Match, len(rows))
+	sem := make(chan struct{}, matchWorkers)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, row := range rows {
+		matches[i].Row = row
+		if row.Skip != "" {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			movie, err := matchRow(ctx, client, row)
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("match line %d: %w", row.Line, err)
+					cancel()
+				})
+				return
+			}
+			matches[i].Movie = movie
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// matchRow finds a row's movie by IMDb ID, falling back to its title and year.
+// IMDb and TMDB can disagree on the year by one, so a miss in the exact year
+// retries without it and accepts a neighbouring year.
+func matchRow(ctx context.Context, client *tmdb.Client, row Row) (*tmdb.SearchResult, error) {
+	if row.IMDbID != "" {
+		movie, err := client.FindByIMDbID(ctx, row.IMDbID)
+		if err != nil || movie != nil {
+			return movie, err
+		}
+	}
+	if row.Title == "" {
+		return nil, nil
+	}
+
+	if row.Year != nil {
+		results, err := client.SearchYear(ctx, row.Title, *row.Year)
+		if err != nil {
+			return nil, err
+		}
+		if len(results.Results) > 0 {
+			return &results.Results[0], nil
+		}
+	}
+
+	results, err := client.Search(ctx, row.Title)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results.Results {
+		if row.Year == nil {
+			return &results.Results[i], nil
+		}
+		if year := tmdb.ReleaseYear(result.ReleaseDate); year != nil && *year >= *row.Year-1 && *year <= *row.Year+1 {
+			return &results.Results[i], nil
+		}
+	}
+	return nil, nil
+}
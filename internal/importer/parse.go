@@ -0,0 +1,210 @@
+// Package importer reads rating exports from Letterboxd and IMDb and matches
+// their rows to TMDB movies
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRows caps how many rows a single import may hold
+const maxRows = 5000
+
+// Source is the service and file an export came from
+type Source string
+
+// Supported exports
+const (
+	SourceLetterboxdRatings Source = "letterboxd-ratings" // ratings.csv
+	SourceLetterboxdDiary   Source = "letterboxd-diary"   // diary.csv
+	SourceLetterboxdWatched Source = "letterboxd-watched" // watched.csv, no ratings
+	SourceIMDb              Source = "imdb"               // IMDb "Your Ratings" export
+)
+
+// Label names the source for display
+func (s Source) Label() string {
+	switch s {
+	case SourceLetterboxdRatings:
+		return "Letterboxd ratings"
+	case SourceLetterboxdDiary:
+		return "Letterboxd diary"
+	case SourceLetterboxdWatched:
+		return "Letterboxd watched list"
+	case SourceIMDb:
+		return "IMDb ratings"
+	default:
+		return string(s)
+	}
+}
+
+// ErrUnknownFormat is returned when a file's header matches no supported export
+var ErrUnknownFormat = errors.New("not a Letterboxd ratings, diary or watched export, or an IMDb ratings export")
+
+// Row is one movie from an export
+type Row struct {
+	Line      int // Line in the file; the header is line 1
+	Title     string
+	Year      *int
+	IMDbID    string     // IMDb exports only
+	Score     *float64   // On this app's 0-10 scale; Letterboxd stars are doubled
+	WatchedOn *time.Time // Diary watch date, otherwise when the movie was logged or rated
+	Skip      string     // Why the row can't be imported; empty if it can
+}
+
+// File is a parsed export
+type File struct {
+	Source Source
+	Rows   []Row
+}
+
+// Parse reads a Letterboxd or IMDb CSV export, telling which it is from the header
+func Parse(r io.Reader) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("the file is empty")
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	file := &File{}
+	switch {
+	case has(columns, "const", "your rating"):
+		file.Source = SourceIMDb
+	case has(columns, "letterboxd uri", "name", "watched date"):
+		file.Source = SourceLetterboxdDiary
+	case has(columns, "letterboxd uri", "name", "rating"):
+		file.Source = SourceLetterboxdRatings
+	case has(columns, "letterboxd uri", "name"):
+		file.Source = SourceLetterboxdWatched
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read line %d: %w", line, err)
+		}
+		if len(file.Rows) == maxRows {
+			return nil, fmt.Errorf("the file has more than %d rows", maxRows)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if file.Source == SourceIMDb {
+			file.Rows = append(file.Rows, imdbRow(line, field))
+		} else {
+			file.Rows = append(file.Rows, letterboxdRow(line, field))
+		}
+	}
+
+	return file, nil
+}
+
+// letterboxdRow reads a row of any Letterboxd export. Ratings are 0.5 to 5 stars.
+func letterboxdRow(line int, field func(string) string) Row {
+	row := Row{Line: line, Title: field("name"), Year: parseYear(field("year"))}
+
+	date := field("watched date")
+	if date == "" {
+		date = field("date")
+	}
+	row.WatchedOn = parseDate(date)
+
+	if rating := field("rating"); rating != "" {
+		stars, err := strconv.ParseFloat(rating, 64)
+		if err != nil || stars < 0 || stars > 5 {
+			row.Skip = "Invalid rating " + strconv.Quote(rating)
+		} else {
+			score := stars * 2
+			row.Score = &score
+		}
+	}
+
+	if row.Title == "" {
+		row.Skip = "No title"
+	}
+	return row
+}
+
+// imdbRow reads a row of an IMDb ratings export. Ratings are 1 to 10.
+func imdbRow(line int, field func(string) string) Row {
+	row := Row{
+		Line:      line,
+		Title:     field("title"),
+		Year:      parseYear(field("year")),
+		IMDbID:    field("const"),
+		WatchedOn: parseDate(field("date rated")),
+	}
+
+	if rating := field("your rating"); rating != "" {
+		score, err := strconv.ParseFloat(rating, 64)
+		if err != nil || score < 0 || score > 10 {
+			row.Skip = "Invalid rating " + strconv.Quote(rating)
+		} else {
+			row.Score = &score
+		}
+	}
+
+	switch titleType := field("title type"); strings.ToLower(strings.ReplaceAll(titleType, " ", "")) {
+	case "", "movie", "tvmovie", "video":
+	default:
+		row.Skip = "Not a movie (" + titleType + ")"
+	}
+
+	if row.Title == "" && row.IMDbID == "" {
+		row.Skip = "No title"
+	}
+	return row
+}
+
+// has reports whether every one of names is a column
+func has(columns map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func parseYear(s string) *int {
+	year, err := strconv.Atoi(s)
+	if err != nil || year < 1800 {
+		return nil
+	}
+	return &year
+}
+
+func parseDate(s string) *time.Time {
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
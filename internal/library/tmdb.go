@@ -0,0 +1,86 @@
+// Package library holds operations on the movie library shared by the web
+// handlers and the command line
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/repository"
+	"github.com/drywaters/seenema/internal/tmdb"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrTMDBMovieNotFound is returned when TMDB has no movie with the requested ID
+var ErrTMDBMovieNotFound = errors.New("tmdb movie not found")
+
+// FindOrImportTMDBMovie returns the library movie for a TMDB ID, fetching it
+// from TMDB and saving it first if needed. A movie in the trash is restored
+// rather than added again. created reports whether it is new to the library.
+func FindOrImportTMDBMovie(ctx context.Context, movieRepo *repository.MovieRepository, tmdbClient *tmdb.Client, tmdbID int) (movie *model.Movie, created bool, err error) {
+	// Check if movie already exists in library
+	movie, err = movieRepo.GetByTMDBId(ctx, tmdbID)
+	if err != nil {
+		return nil, false, fmt.Errorf("check existing movie: %w", err)
+	}
+	if movie != nil {
+		return movie, false, nil
+	}
+
+	movie, err = movieRepo.RestoreByTMDBId(ctx, tmdbID)
+	if err != nil {
+		return nil, false, fmt.Errorf("restore deleted movie: %w", err)
+	}
+	if movie != nil {
+		return movie, true, nil
+	}
+
+	// Fetch movie details from TMDB
+	details, err := tmdbClient.GetMovie(ctx, tmdbID)
+	if err != nil {
+		return nil, false, fmt.Errorf("get TMDB movie: %w", err)
+	}
+	if details == nil {
+		return nil, false, ErrTMDBMovieNotFound
+	}
+
+	// Build poster URL
+	var posterURL *string
+	if details.PosterPath != nil {
+		url := tmdbClient.PosterURL(*details.PosterPath, "w500")
+		posterURL = &url
+	}
+
+	// Store metadata as JSON
+	metadataJSON, err := json.Marshal(details)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal TMDB metadata: %w", err)
+	}
+
+	// Create movie in database
+	movie, err = movieRepo.Create(ctx, model.CreateMovieInput{
+		Title:          details.Title,
+		ReleaseYear:    tmdb.ReleaseYear(details.ReleaseDate),
+		PosterURL:      posterURL,
+		Synopsis:       &details.Overview,
+		RuntimeMinutes: &details.Runtime,
+		TMDBId:         &tmdbID,
+		IMDBId:         details.IMDBId,
+		MetadataJSON:   metadataJSON,
+	})
+	if err != nil {
+		// Someone else added it while we were fetching from TMDB
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if movie, getErr := movieRepo.GetByTMDBId(ctx, tmdbID); getErr == nil && movie != nil {
+				return movie, false, nil
+			}
+		}
+		return nil, false, fmt.Errorf("create movie: %w", err)
+	}
+
+	return movie, true, nil
+}
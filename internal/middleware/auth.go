@@ -1,44 +1,78 @@
 package middleware
 
 import (
-	"crypto/subtle"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/repository"
 )
 
-const cookieName = "seenema_session"
+// lastSeenInterval is how stale a session's last_seen_at may get before it is refreshed
+const lastSeenInterval = 5 * time.Minute
 
-// Auth middleware validates requests using either Bearer token or cookie.
-// Programmatic clients (iOS Shortcuts, CLI) use Authorization: Bearer <token>.
-// Browser clients use a cookie set during login.
-func Auth(apiToken string, secureCookies bool) func(http.Handler) http.Handler {
+// Auth middleware validates requests using either Bearer token or session cookie.
+// Programmatic clients (iOS Shortcuts, CLI) use Authorization: Bearer <token>,
+// with either the master API token or a named token whose scopes are
+// checked per route by RequireScope.
+// Browser clients use a session cookie set when a person signs in; the
+// session's person is stored in the request context (see auth.PersonFromContext).
+func Auth(apiToken string, sessions *repository.SessionRepository, apiTokens *repository.APITokenRepository, secureCookies bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Check Authorization header first (for programmatic access)
 			if authHeader := r.Header.Get("Authorization"); authHeader != "" {
 				if token, ok := strings.CutPrefix(authHeader, "Bearer "); ok {
-					if constantTimeEqual(token, apiToken) {
-						next.ServeHTTP(w, r)
+					// The master token has every scope
+					if auth.ConstantTimeEqual(token, apiToken) {
+						next.ServeHTTP(w, r.WithContext(auth.WithHouseholdToken(r.Context())))
+						return
+					}
+
+					named, err := apiTokens.GetByTokenHash(r.Context(), auth.HashToken(token))
+					if err != nil {
+						slog.Error("failed to look up api token", "error", err)
+						http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+						return
+					}
+					if named != nil {
+						if err := apiTokens.Touch(r.Context(), named.ID); err != nil {
+							slog.Warn("failed to update api token last used", "error", err, "token_id", named.ID)
+						}
+						next.ServeHTTP(w, r.WithContext(auth.WithAPIToken(r.Context(), named)))
 						return
 					}
 				}
 				// Invalid bearer token
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeError(w, r, http.StatusUnauthorized, apijson.CodeUnauthorized, "Unauthorized")
 				return
 			}
 
 			// Fall back to cookie check (for browser access)
-			cookie, err := r.Cookie(cookieName)
+			cookie, err := r.Cookie(auth.SessionCookieName)
 			if err != nil {
+				if isAPIRequest(r) {
+					writeError(w, r, http.StatusUnauthorized, apijson.CodeUnauthorized, "Unauthorized")
+					return
+				}
 				redirectToLogin(w, r)
 				return
 			}
 
-			if !constantTimeEqual(cookie.Value, apiToken) {
-				// Invalid cookie, clear it and redirect
+			session, err := sessions.GetByTokenHash(r.Context(), auth.HashToken(cookie.Value))
+			if err != nil {
+				slog.Error("failed to look up session", "error", err)
+				writeError(w, r, http.StatusInternalServerError, apijson.CodeInternal, "Internal Server Error")
+				return
+			}
+			if session == nil {
+				// Unknown or expired session, clear it and redirect
 				http.SetCookie(w, &http.Cookie{
-					Name:     cookieName,
+					Name:     auth.SessionCookieName,
 					Value:    "",
 					Path:     "/",
 					MaxAge:   -1,
@@ -46,20 +80,28 @@ func Auth(apiToken string, secureCookies bool) func(http.Handler) http.Handler {
 					Secure:   secureCookies,
 					SameSite: http.SameSiteLaxMode,
 				})
+				if isAPIRequest(r) {
+					writeError(w, r, http.StatusUnauthorized, apijson.CodeUnauthorized, "Unauthorized")
+					return
+				}
 				redirectToLogin(w, r)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			// Throttle last-seen writes so every request doesn't update the row
+			if time.Since(session.LastSeenAt) > lastSeenInterval {
+				if err := sessions.Touch(r.Context(), session.ID); err != nil {
+					slog.Warn("failed to update session last seen", "error", err, "session_id", session.ID)
+				}
+			}
+
+			ctx := auth.WithPerson(r.Context(), session.Person)
+			ctx = auth.WithSessionID(ctx, session.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// constantTimeEqual performs a constant-time comparison to prevent timing attacks.
-func constantTimeEqual(a, b string) bool {
-	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
-}
-
 // redirectToLogin redirects to login page, preserving the original URL
 func redirectToLogin(w http.ResponseWriter, r *http.Request) {
 	originalURL := r.URL.String()
@@ -72,5 +114,3 @@ func redirectToLogin(w http.ResponseWriter, r *http.Request) {
 
 	http.Redirect(w, r, loginURL, http.StatusSeeOther)
 }
-
-
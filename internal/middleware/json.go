@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/drywaters/seenema/internal/apijson"
+)
+
+// apiPrefix is the path prefix of the versioned JSON API
+const apiPrefix = "/api/v1"
+
+// isAPIRequest reports whether the request targets the versioned JSON API
+func isAPIRequest(r *http.Request) bool {
+	return r.URL.Path == apiPrefix || strings.HasPrefix(r.URL.Path, apiPrefix+"/")
+}
+
+// writeError writes a JSON error body for API requests and plain text otherwise
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	if !isAPIRequest(r) {
+		http.Error(w, message, status)
+		return
+	}
+	apijson.WriteError(w, status, code, message)
+}
+
+// NegotiateJSON rejects requests that cannot accept a JSON response (406)
+// or that send a body other than JSON (415).
+func NegotiateJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsJSON(r.Header.Get("Accept")) {
+			writeError(w, r, http.StatusNotAcceptable, apijson.CodeNotAcceptable, "This API only produces application/json")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			if r.ContentLength != 0 {
+				mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				if err != nil || mediaType != "application/json" {
+					writeError(w, r, http.StatusUnsupportedMediaType, apijson.CodeUnsupportedMediaType, "Request body must be application/json")
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acceptsJSON reports whether an Accept header allows a JSON response
+func acceptsJSON(accept string) bool {
+	if accept == "" {
+		return true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json", "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/auth"
+)
+
+// RequireScope rejects requests made with a scoped API token that was not granted scope.
+// Browser sessions and the master API token are allowed through.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := auth.APITokenFromContext(r.Context()); token != nil && !token.HasScope(scope) {
+				writeError(w, r, http.StatusForbidden, apijson.CodeForbidden, "Forbidden: token lacks scope "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RejectScopedTokens restricts routes (HTML pages, account and token management)
+// to browser sessions and the master API token.
+func RejectScopedTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.APITokenFromContext(r.Context()) != nil {
+			writeError(w, r, http.StatusForbidden, apijson.CodeForbidden, "Forbidden: not available to API tokens")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,432 @@
+// Package migrate applies the embedded SQL migrations, keeping its history in
+// goose's goose_db_version table so databases migrated with the goose CLI carry on
+// where they left off
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is the advisory lock held while migrating, so replicas starting
+// together take turns
+const lockKey = 4
+
+// ErrSchemaTooNew is returned when the database has migrations this binary
+// doesn't know about
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// Migration is one numbered SQL file
+type Migration struct {
+	Version int64
+	Source  string
+	up      []string
+	down    []string
+}
+
+// Status is whether a migration has been applied, and when
+type Status struct {
+	Migration *Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies and rolls back migrations
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []*Migration
+}
+
+// New creates a Migrator for the NNN_description.sql files at the root of fsys
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Latest returns the newest migration's version, or 0 if there are none
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the newest migration applied to the database, or 0 if
+// migrations have never run
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Status lists every migration with when it was applied, if it was
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// CheckVersion returns ErrSchemaTooNew if the database has been migrated past
+// the newest migration this binary has
+func (m *Migrator) CheckVersion(ctx context.Context) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return m.checkVersion(current)
+}
+
+func (m *Migrator) checkVersion(current int64) error {
+	if current > m.Latest() {
+		return fmt.Errorf("%w: the database is at version %d but this binary only has migrations up to %d; run a newer release", ErrSchemaTooNew, current, m.Latest())
+	}
+	return nil
+}
+
+// Up applies every pending migration in order, each in its own transaction,
+// and returns the ones it applied. Concurrent calls, from this process or
+// another, wait for each other.
+func (m *Migrator) Up(ctx context.Context) (done []*Migration, err error) {
+	err = m.withLock(ctx, func() error {
+		done, err = m.up(ctx)
+		return err
+	})
+	return done, err
+}
+
+func (m *Migrator) up(ctx context.Context) ([]*Migration, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var current int64
+	for v := range applied {
+		current = max(current, v)
+	}
+	if err := m.checkVersion(current); err != nil {
+		return nil, err
+	}
+
+	var pending []*Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if migration.Version < current {
+			return nil, fmt.Errorf("migration %s is older than the database's version %d but was never applied", migration.Source, current)
+		}
+		pending = append(pending, migration)
+	}
+
+	var done []*Migration
+	for _, migration := range pending {
+		err := m.run(ctx, migration, migration.up, `INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, TRUE)`)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down rolls back the newest applied migration, returning nil if none are applied
+func (m *Migrator) Down(ctx context.Context) (migration *Migration, err error) {
+	err = m.withLock(ctx, func() error {
+		migration, err = m.down(ctx)
+		return err
+	})
+	return migration, err
+}
+
+func (m *Migrator) down(ctx context.Context) (*Migration, error) {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version == current {
+			err := m.run(ctx, migration, migration.down, `DELETE FROM goose_db_version WHERE version_id = $1`)
+			if err != nil {
+				return nil, err
+			}
+			return migration, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: the database is at version %d, which this binary has no migration for", ErrSchemaTooNew, current)
+}
+
+// withLock runs fn holding the migration lock. The lock is taken on a
+// connection of its own, since each migration runs in its own transaction.
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1, 0)", lockKey); err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1, 0)", lockKey); err != nil {
+			// Closing the connection ends the session, which releases the lock
+			_ = conn.Conn().Close(context.Background())
+		}
+	}()
+
+	return fn()
+}
+
+// run executes a migration's statements and records it in one transaction
+func (m *Migrator) run(ctx context.Context, migration *Migration, statements []string, record string) error {
+	return pgx.BeginFunc(ctx, m.pool, func(tx pgx.Tx) error {
+		for i, statement := range statements {
+			if _, err := tx.Exec(ctx, statement); err != nil {
+				return fmt.Errorf("run %s statement %d: %w", migration.Source, i+1, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, record, migration.Version); err != nil {
+			return fmt.Errorf("record %s: %w", migration.Source, err)
+		}
+		return nil
+	})
+}
+
+// ensureVersionTable creates goose's history table the way goose does
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	return pgx.BeginFunc(ctx, m.pool, func(tx pgx.Tx) error {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists); err != nil {
+			return fmt.Errorf("check migrations table: %w", err)
+		}
+		if exists {
+			return nil
+		}
+
+		query := `
+			CREATE TABLE goose_db_version (
+				id          INTEGER PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+				version_id  BIGINT NOT NULL,
+				is_applied  BOOLEAN NOT NULL,
+				tstamp      TIMESTAMP NOT NULL DEFAULT NOW()
+			)`
+		if _, err := tx.Exec(ctx, query); err != nil {
+			return fmt.Errorf("create migrations table: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, TRUE)`); err != nil {
+			return fmt.Errorf("create migrations table: %w", err)
+		}
+		return nil
+	})
+}
+
+// applied returns when each applied migration ran. Older goose releases
+// recorded rollbacks as is_applied = FALSE rows rather than deleting, so the
+// newest row for a version decides.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	var exists bool
+	if err := m.pool.QueryRow(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check migrations table: %w", err)
+	}
+	if !exists {
+		return map[int64]time.Time{}, nil
+	}
+
+	rows, err := m.pool.Query(ctx, `SELECT version_id, is_applied, tstamp FROM goose_db_version WHERE version_id > 0 ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("get migration history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []historyRow
+	for rows.Next() {
+		var row historyRow
+		if err := rows.Scan(&row.version, &row.isApplied, &row.at); err != nil {
+			return nil, fmt.Errorf("scan migration history: %w", err)
+		}
+		history = append(history, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate migration history: %w", err)
+	}
+	return appliedVersions(history), nil
+}
+
+// historyRow is one row of goose_db_version
+type historyRow struct {
+	version   int64
+	isApplied bool
+	at        time.Time
+}
+
+// appliedVersions returns when each applied migration ran, given the history
+// newest first
+func appliedVersions(history []historyRow) map[int64]time.Time {
+	applied := make(map[int64]time.Time)
+	seen := make(map[int64]bool)
+	for _, row := range history {
+		if seen[row.version] {
+			continue
+		}
+		seen[row.version] = true
+		if row.isApplied {
+			applied[row.version] = row.at
+		}
+	}
+	return applied
+}
+
+// load reads and parses the migrations in fsys, ordered by version
+func load(fsys fs.FS) ([]*Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("list migrations: %w", err)
+	}
+
+	var migrations []*Migration
+	versions := make(map[int64]string)
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(path.Base(name), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s must be named NNN_description.sql", name)
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		versions[version] = name
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		up, down, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("parse migration %s: %w", name, err)
+		}
+		migrations = append(migrations, &Migration{Version: version, Source: name, up: up, down: down})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parse splits a goose SQL file into its Up and Down statements. Statements
+// end at a line ending in a semicolon unless wrapped in StatementBegin and
+// StatementEnd.
+func parse(sql string) (up, down []string, err error) {
+	var section *[]string
+	var statement strings.Builder
+	inBlock := false
+
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, "-- +goose "); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				if section != nil {
+					return nil, nil, errors.New("-- +goose Up must come first")
+				}
+				section = &up
+			case "Down":
+				if section != &up || inBlock || !isBlank(statement.String()) {
+					return nil, nil, errors.New("-- +goose Down must follow the Up statements")
+				}
+				statement.Reset()
+				section = &down
+			case "StatementBegin":
+				if section == nil || inBlock {
+					return nil, nil, errors.New("unexpected -- +goose StatementBegin")
+				}
+				statement.Reset()
+				inBlock = true
+			case "StatementEnd":
+				if !inBlock {
+					return nil, nil, errors.New("-- +goose StatementEnd without StatementBegin")
+				}
+				*section = append(*section, statement.String())
+				statement.Reset()
+				inBlock = false
+			default:
+				return nil, nil, fmt.Errorf("unsupported annotation %q", trimmed)
+			}
+			continue
+		}
+
+		if section == nil {
+			if !isBlank(line) {
+				return nil, nil, errors.New("SQL before -- +goose Up")
+			}
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteString("\n")
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			*section = append(*section, statement.String())
+			statement.Reset()
+		}
+	}
+
+	if section == nil {
+		return nil, nil, errors.New("missing -- +goose Up")
+	}
+	if inBlock {
+		return nil, nil, errors.New("missing -- +goose StatementEnd")
+	}
+	if !isBlank(statement.String()) {
+		return nil, nil, errors.New("last statement has no terminating semicolon")
+	}
+	return up, down, nil
+}
+
+// isBlank reports whether sql is only whitespace and line comments
+func isBlank(sql string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
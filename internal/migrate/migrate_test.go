@@ -0,0 +1,140 @@
+package migrate
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		wantUp   []string
+		wantDown []string
+		wantErr  string
+	}{
+		{
+			name: "statements end at semicolons",
+			sql: `-- +goose Up
+CREATE TABLE a (id INT);
+ALTER TABLE a
+    ADD COLUMN name TEXT;
+
+-- +goose Down
+DROP TABLE a;
+`,
+			wantUp:   []string{"CREATE TABLE a (id INT);\n", "ALTER TABLE a\n    ADD COLUMN name TEXT;\n"},
+			wantDown: []string{"DROP TABLE a;\n"},
+		},
+		{
+			name: "block keeps its semicolons",
+			sql: `-- +goose Up
+-- +goose StatementBegin
+UPDATE a SET id = 1;
+UPDATE a SET id = 2;
+-- +goose StatementEnd
+`,
+			wantUp: []string{"UPDATE a SET id = 1;\nUPDATE a SET id = 2;\n"},
+		},
+		{
+			name: "dollar-quoted body in a block",
+			sql: `-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION touch() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION touch();
+`,
+			wantUp:   []string{"CREATE FUNCTION touch() RETURNS TRIGGER AS $$\nBEGIN\n    NEW.updated_at = NOW();\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;\n"},
+			wantDown: []string{"DROP FUNCTION touch();\n"},
+		},
+		{
+			name:    "missing trailing semicolon",
+			sql:     "-- +goose Up\nCREATE TABLE a (id INT)\n",
+			wantErr: "no terminating semicolon",
+		},
+		{
+			name:    "missing semicolon before Down",
+			sql:     "-- +goose Up\nCREATE TABLE a (id INT)\n-- +goose Down\nDROP TABLE a;\n",
+			wantErr: "Down must follow",
+		},
+		{
+			name:    "unclosed block",
+			sql:     "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n",
+			wantErr: "missing -- +goose StatementEnd",
+		},
+		{
+			name:    "SQL before Up",
+			sql:     "SELECT 1;\n-- +goose Up\nSELECT 2;\n",
+			wantErr: "SQL before -- +goose Up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down, err := parse(tt.sql)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parse() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+			if !slices.Equal(up, tt.wantUp) {
+				t.Errorf("up = %q, want %q", up, tt.wantUp)
+			}
+			if !slices.Equal(down, tt.wantDown) {
+				t.Errorf("down = %q, want %q", down, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestAppliedVersions(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// Newest first, as applied reads it
+	history := []historyRow{
+		{version: 3, isApplied: true, at: day(5)},  // reapplied after the rollback below
+		{version: 3, isApplied: false, at: day(4)}, // rolled back
+		{version: 2, isApplied: false, at: day(3)}, // rolled back, never reapplied
+		{version: 3, isApplied: true, at: day(2)},
+		{version: 2, isApplied: true, at: day(2)},
+		{version: 1, isApplied: true, at: day(1)},
+	}
+
+	applied := appliedVersions(history)
+	want := map[int64]time.Time{1: day(1), 3: day(5)}
+	if len(applied) != len(want) {
+		t.Fatalf("applied = %v, want %v", applied, want)
+	}
+	for version, at := range want {
+		if got, ok := applied[version]; !ok || !got.Equal(at) {
+			t.Errorf("version %d applied at %v, want %v", version, got, at)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	m := &Migrator{migrations: []*Migration{{Version: 1}, {Version: 2}}}
+
+	for _, current := range []int64{0, 1, 2} {
+		if err := m.checkVersion(current); err != nil {
+			t.Errorf("checkVersion(%d) = %v, want nil", current, err)
+		}
+	}
+	if err := m.checkVersion(3); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("checkVersion(3) = %v, want ErrSchemaTooNew", err)
+	}
+}
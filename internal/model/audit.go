@@ -0,0 +1,122 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audited entity types. audit_events.entity_type is checked against this list,
+// so a new type needs a migration too.
+const (
+	AuditEntityMovie   = "movie"
+	AuditEntityEntry   = "entry"
+	AuditEntityRating  = "rating"
+	AuditEntityPerson  = "person"
+	AuditEntityViewing = "viewing"
+)
+
+// Audit actions
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionRestore   = "restore"
+	AuditActionMove      = "move"
+	AuditActionCopy      = "copy"
+	AuditActionReorder   = "reorder"
+	AuditActionWatched   = "watched"
+	AuditActionUnwatched = "unwatched"
+	AuditActionArchive   = "archive"
+	AuditActionUnarchive = "unarchive"
+)
+
+// AuditActions lists every audit action, in the order filters offer them
+var AuditActions = []string{
+	AuditActionCreate,
+	AuditActionUpdate,
+	AuditActionDelete,
+	AuditActionRestore,
+	AuditActionMove,
+	AuditActionCopy,
+	AuditActionReorder,
+	AuditActionWatched,
+	AuditActionUnwatched,
+	AuditActionArchive,
+	AuditActionUnarchive,
+}
+
+// Kinds of actor recorded on an audit event
+const (
+	AuditActorPerson         = "person"          // A signed-in person
+	AuditActorAPIToken       = "api_token"       // A named, scoped API token
+	AuditActorHouseholdToken = "household_token" // The master API token
+	AuditActorSystem         = "system"          // Background jobs and the CLI
+)
+
+// AuditEvent records one change to a movie, entry, rating, person or viewing
+type AuditEvent struct {
+	ID            int64           `json:"id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Action        string          `json:"action"`
+	EntityType    string          `json:"entity_type"`
+	EntityID      uuid.UUID       `json:"entity_id"`
+	EntryID       *uuid.UUID      `json:"entry_id,omitempty"` // Entry, rating and viewing events only
+	ActorKind     string          `json:"actor_kind"`
+	ActorName     string          `json:"actor_name"`
+	ActorPersonID *uuid.UUID      `json:"actor_person_id,omitempty"`
+	ActorTokenID  *uuid.UUID      `json:"actor_token_id,omitempty"`
+	RequestID     *string         `json:"request_id,omitempty"`
+	Before        json.RawMessage `json:"before"` // null for creates
+	After         json.RawMessage `json:"after"`
+
+	// Joined data (populated by repository)
+	RatedPerson *Person `json:"rated_person,omitempty"` // Whose rating a rating event changed
+}
+
+// AuditFilter narrows an entry's history; zero values match everything
+type AuditFilter struct {
+	EntityType    string // entry, rating or viewing
+	Action        string
+	ActorPersonID *uuid.UUID
+}
+
+// AuditChange is one field that differs between an event's snapshots
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// auditIgnoredFields are bookkeeping that says nothing about what changed
+var auditIgnoredFields = []string{"id", "created_at", "updated_at", "added_at"}
+
+// Changes lists the fields that differ between Before and After, by field name
+func (e *AuditEvent) Changes() []AuditChange {
+	var before, after map[string]any
+	_ = json.Unmarshal(e.Before, &before)
+	_ = json.Unmarshal(e.After, &after)
+
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	var changes []AuditChange
+	for _, field := range fields {
+		if slices.Contains(auditIgnoredFields, field) || reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+		changes = append(changes, AuditChange{Field: field, Before: before[field], After: after[field]})
+	}
+	return changes
+}
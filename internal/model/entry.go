@@ -1,6 +1,7 @@
 package model
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,15 +13,17 @@ type Entry struct {
 	MovieID          uuid.UUID  `json:"movie_id"`
 	GroupNumber      int        `json:"group_number"`
 	Position         int        `json:"position"`             // Position within the group (1 = first)
-	WatchedAt        *time.Time `json:"watched_at,omitempty"` // nil = not yet watched
+	WatchedAt        *time.Time `json:"watched_at,omitempty"` // First viewing; nil = not yet watched
 	AddedAt          time.Time  `json:"added_at"`
 	Notes            *string    `json:"notes,omitempty"`
 	PickedByPersonID *uuid.UUID `json:"picked_by_person_id,omitempty"`
 
 	// Joined data (populated by repository)
-	Movie          *Movie    `json:"movie,omitempty"`
-	Ratings        []*Rating `json:"ratings,omitempty"`
-	PickedByPerson *Person   `json:"picked_by_person,omitempty"`
+	Movie          *Movie      `json:"movie,omitempty"`
+	Ratings        []*Rating   `json:"ratings,omitempty"`
+	PickedByPerson *Person     `json:"picked_by_person,omitempty"`
+	Viewings       []*Viewing  `json:"viewings,omitempty"`     // Oldest first; only loaded for a single entry
+	AttendeeIDs    []uuid.UUID `json:"attendee_ids,omitempty"` // Everyone present at any viewing
 }
 
 // CreateEntryInput represents the input for creating an entry
@@ -38,22 +41,47 @@ type UpdateEntryInput struct {
 	PickedByPersonID *uuid.UUID `json:"picked_by_person_id,omitempty"`
 }
 
-// IsWatched returns true if the entry has been watched
+// IsWatched returns true if the entry has at least one viewing
 func (e *Entry) IsWatched() bool {
 	return e.WatchedAt != nil
 }
 
-// AverageRating returns the average rating for this entry, or nil if no ratings
-func (e *Entry) AverageRating() *float64 {
-	if len(e.Ratings) == 0 {
-		return nil
+// Attended reports whether the person watched this entry. Until the entry has
+// a viewing everyone is expected to watch it.
+func (e *Entry) Attended(personID uuid.UUID) bool {
+	if !e.IsWatched() {
+		return true
+	}
+	return slices.Contains(e.AttendeeIDs, personID)
+}
+
+// AttendeesAmong returns the given persons who watched this entry
+func (e *Entry) AttendeesAmong(persons []*Person) []*Person {
+	attendees := make([]*Person, 0, len(persons))
+	for _, p := range persons {
+		if e.Attended(p.ID) {
+			attendees = append(attendees, p)
+		}
 	}
+	return attendees
+}
 
+// AverageRating returns the average rating of the people who watched this
+// entry, or nil if none of them rated it
+func (e *Entry) AverageRating() *float64 {
 	var sum float64
+	var count int
 	for _, r := range e.Ratings {
-		sum += r.Score
+		if e.Attended(r.PersonID) {
+			sum += r.Score
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
 	}
-	avg := sum / float64(len(e.Ratings))
+
+	avg := sum / float64(count)
 	return &avg
 }
 
@@ -62,9 +90,30 @@ func (e *Entry) RatingCount() int {
 	return len(e.Ratings)
 }
 
-// IsFullyRated returns true if all family members have rated
-func (e *Entry) IsFullyRated() bool {
-	return len(e.Ratings) == len(FamilyInitials)
+// RatingCountFor returns the number of the given persons who watched and rated this entry
+func (e *Entry) RatingCountFor(persons []*Person) int {
+	count := 0
+	for _, p := range e.AttendeesAmong(persons) {
+		if e.GetRatingByPersonID(p.ID) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// IsFullyRated returns true if every given person who watched this entry has
+// rated it, and at least one of them did
+func (e *Entry) IsFullyRated(persons []*Person) bool {
+	attendees := e.AttendeesAmong(persons)
+	if len(attendees) == 0 {
+		return false
+	}
+	for _, p := range attendees {
+		if e.GetRatingByPersonID(p.ID) == nil {
+			return false
+		}
+	}
+	return true
 }
 
 // GetRatingByPersonID returns the rating for a specific person, or nil if not rated
@@ -77,6 +126,26 @@ func (e *Entry) GetRatingByPersonID(personID uuid.UUID) *Rating {
 	return nil
 }
 
+// GetViewingByID returns one of the entry's viewings, or nil if it has no such viewing
+func (e *Entry) GetViewingByID(viewingID uuid.UUID) *Viewing {
+	for _, v := range e.Viewings {
+		if v.ID == viewingID {
+			return v
+		}
+	}
+	return nil
+}
+
+// LatestViewingWith returns the most recent viewing the person was present at, or nil
+func (e *Entry) LatestViewingWith(personID uuid.UUID) *Viewing {
+	for i := len(e.Viewings) - 1; i >= 0; i-- {
+		if e.Viewings[i].Attended(personID) {
+			return e.Viewings[i]
+		}
+	}
+	return nil
+}
+
 // GetRatingByInitial returns the rating for a specific person by their initial
 func (e *Entry) GetRatingByInitial(initial string) *Rating {
 	for _, r := range e.Ratings {
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntryIsFullyRated(t *testing.T) {
+	d := &Person{ID: uuid.New(), Initial: "D"}
+	j := &Person{ID: uuid.New(), Initial: "J"}
+	c := &Person{ID: uuid.New(), Initial: "C"}
+	persons := []*Person{d, j, c}
+	watched := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	rated := func(people ...*Person) []*Rating {
+		ratings := make([]*Rating, len(people))
+		for i, p := range people {
+			ratings[i] = &Rating{PersonID: p.ID, Score: 7}
+		}
+		return ratings
+	}
+
+	tests := []struct {
+		name  string
+		entry *Entry
+		want  bool
+	}{
+		{
+			name:  "unwatched, everyone rated",
+			entry: &Entry{Ratings: rated(d, j, c)},
+			want:  true,
+		},
+		{
+			name:  "unwatched, someone missing",
+			entry: &Entry{Ratings: rated(d, j)},
+			want:  false,
+		},
+		{
+			name:  "every attendee rated",
+			entry: &Entry{WatchedAt: &watched, AttendeeIDs: []uuid.UUID{d.ID, j.ID}, Ratings: rated(d, j)},
+			want:  true,
+		},
+		{
+			name:  "an attendee hasn't rated",
+			entry: &Entry{WatchedAt: &watched, AttendeeIDs: []uuid.UUID{d.ID, j.ID}, Ratings: rated(d)},
+			want:  false,
+		},
+		{
+			name:  "absent person's rating doesn't stand in for an attendee's",
+			entry: &Entry{WatchedAt: &watched, AttendeeIDs: []uuid.UUID{d.ID, j.ID}, Ratings: rated(d, c)},
+			want:  false,
+		},
+		{
+			name:  "watched with no attendees among the persons",
+			entry: &Entry{WatchedAt: &watched, Ratings: rated(d)},
+			want:  false,
+		},
+		{
+			name:  "no ratings",
+			entry: &Entry{},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.IsFullyRated(persons); got != tt.want {
+				t.Errorf("IsFullyRated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
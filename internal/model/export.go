@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportRow is one entry as it appears in a collection export
+type ExportRow struct {
+	EntryID     uuid.UUID
+	Title       string
+	ReleaseYear *int
+	TMDBId      *int
+	IMDBId      *string
+	GroupNumber int
+	GroupName   *string
+	Position    int
+	WatchedAt   *time.Time // First viewing
+	PickedBy    *string    // Picker's name
+	Notes       *string
+	Scores      map[uuid.UUID]float64 // By person ID
+}
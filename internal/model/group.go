@@ -0,0 +1,58 @@
+package model
+
+import (
+	"strconv"
+	"time"
+)
+
+// Group is a numbered watch group, optionally named, themed and dated
+type Group struct {
+	Number       int        `json:"group_number"`
+	Name         *string    `json:"name,omitempty"`        // e.g. "Spooky October"
+	Theme        *string    `json:"theme,omitempty"`       // Short label, e.g. "Horror"
+	Description  *string    `json:"description,omitempty"` // Longer notes on the theme
+	StartsOn     *time.Time `json:"starts_on,omitempty"`   // Date only
+	EndsOn       *time.Time `json:"ends_on,omitempty"`     // Date only
+	IsCurrent    bool       `json:"is_current"`            // New movies go here by default
+	ArchivedAt   *time.Time `json:"archived_at,omitempty"` // nil = active
+	CreatedAt    time.Time  `json:"created_at"`
+	EntryCount   int        `json:"entry_count"`
+	WatchedCount int        `json:"watched_count"`
+}
+
+// GroupInput holds a group's editable details; nil fields are cleared
+type GroupInput struct {
+	Name        *string    `json:"name,omitempty"`
+	Theme       *string    `json:"theme,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	StartsOn    *time.Time `json:"starts_on,omitempty"`
+	EndsOn      *time.Time `json:"ends_on,omitempty"`
+}
+
+// IsArchived returns true if the group has been archived
+func (g *Group) IsArchived() bool {
+	return g.ArchivedAt != nil
+}
+
+// DisplayName returns the group's name, or "Group N" if it has none
+func (g *Group) DisplayName() string {
+	if g.Name != nil && *g.Name != "" {
+		return *g.Name
+	}
+	return "Group " + strconv.Itoa(g.Number)
+}
+
+// DateRange returns the group's dates for display, or "" if it has none
+func (g *Group) DateRange() string {
+	const layout = "Jan 2, 2006"
+	switch {
+	case g.StartsOn != nil && g.EndsOn != nil:
+		return g.StartsOn.Format(layout) + " – " + g.EndsOn.Format(layout)
+	case g.StartsOn != nil:
+		return "From " + g.StartsOn.Format(layout)
+	case g.EndsOn != nil:
+		return "Until " + g.EndsOn.Format(layout)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// LibrarySort is a field the library can be sorted by
+type LibrarySort string
+
+const (
+	LibrarySortAdded   LibrarySort = "added"
+	LibrarySortTitle   LibrarySort = "title"
+	LibrarySortYear    LibrarySort = "year"
+	LibrarySortRuntime LibrarySort = "runtime"
+	LibrarySortWatched LibrarySort = "watched"
+	LibrarySortPicker  LibrarySort = "picker" // Picker's display order
+	LibrarySortScore   LibrarySort = "score"  // Average rating
+)
+
+// LibrarySorts lists every sort, in the order offered to users
+var LibrarySorts = []LibrarySort{
+	LibrarySortAdded,
+	LibrarySortTitle,
+	LibrarySortYear,
+	LibrarySortRuntime,
+	LibrarySortWatched,
+	LibrarySortPicker,
+	LibrarySortScore,
+}
+
+// ParseLibrarySort validates a sort name
+func ParseLibrarySort(s string) (LibrarySort, error) {
+	for _, sort := range LibrarySorts {
+		if string(sort) == s {
+			return sort, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort %q", s)
+}
+
+// DefaultDescending reports whether the sort runs high-to-low unless asked otherwise
+func (s LibrarySort) DefaultDescending() bool {
+	return s != LibrarySortTitle && s != LibrarySortPicker
+}
+
+// LibraryFilter selects and orders a page of the library.
+// Nil fields don't filter; entries with no value for the sort field come last.
+type LibraryFilter struct {
+	Genre            string     // Exact TMDB genre name, "" = any
+	Decade           *int       // e.g. 1990 for 1990-1999
+	MinRuntime       *int       // Minutes
+	MaxRuntime       *int       // Minutes
+	Watched          *bool      // nil = watched or not
+	PickedByPersonID *uuid.UUID // nil = any picker
+	MinScore         *float64   // Average rating; unrated entries are left out when set
+	MaxScore         *float64
+	Sort             LibrarySort
+	Descending       bool
+	After            string // Cursor from the previous page's NextCursor, "" = first page
+	Limit            int
+}
+
+// LibraryPage is one page of library entries
+type LibraryPage struct {
+	Entries    []*Entry `json:"entries"`
+	NextCursor string   `json:"next_cursor,omitempty"` // "" on the last page
+}
+
+// LibraryFacets are the values the library can be filtered by
+type LibraryFacets struct {
+	Genres  []string `json:"genres"`
+	Decades []int    `json:"decades"`
+}
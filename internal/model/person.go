@@ -1,23 +1,46 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
 
 // Person represents a family member who can rate movies
 type Person struct {
-	ID      uuid.UUID `json:"id"`
-	Initial string    `json:"initial"` // D, J, C, A
-	Name    string    `json:"name"`    // Daniel, Jennifer, Caleb, Aiden
+	ID          uuid.UUID  `json:"id"`
+	Initial     string     `json:"initial"`               // Single letter shown on rating badges
+	Name        string     `json:"name"`                  // Display name
+	Position    int        `json:"position"`              // Display order (1 = first)
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"` // nil = active
+	CreatedAt   time.Time  `json:"created_at"`
+	HasPassword bool       `json:"has_password"` // true once the person has set a login password
 }
 
-// FamilyInitials is the ordered list of family member initials
-var FamilyInitials = []string{"D", "J", "C", "A"}
+// CreatePersonInput represents the input for creating a person
+type CreatePersonInput struct {
+	Initial string `json:"initial"`
+	Name    string `json:"name"`
+}
 
-// FamilyNames maps initials to full names
-var FamilyNames = map[string]string{
-	"D": "Daniel",
-	"J": "Jennifer",
-	"C": "Caleb",
-	"A": "Aiden",
+// UpdatePersonInput represents the input for updating a person
+type UpdatePersonInput struct {
+	Initial *string `json:"initial,omitempty"`
+	Name    *string `json:"name,omitempty"`
 }
 
+// IsArchived returns true if the person has been archived
+func (p *Person) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
 
+// NormalizeInitial trims and upper-cases an initial, which must be a single character
+func NormalizeInitial(s string) (string, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if utf8.RuneCountInString(s) != 1 {
+		return "", false
+	}
+	return s, true
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PickerRotation selects how the next picker is chosen
+type PickerRotation string
+
+const (
+	// PickerRotationRoundRobin passes the pick to the next person in display order after the last picker
+	PickerRotationRoundRobin PickerRotation = "round_robin"
+	// PickerRotationWeighted passes the pick to whoever has picked least in the group
+	PickerRotationWeighted PickerRotation = "weighted"
+)
+
+// ParsePickerRotation validates a rotation name
+func ParsePickerRotation(s string) (PickerRotation, error) {
+	switch rotation := PickerRotation(s); rotation {
+	case PickerRotationRoundRobin, PickerRotationWeighted:
+		return rotation, nil
+	default:
+		return "", fmt.Errorf("unknown picker rotation %q (want %q or %q)", s, PickerRotationRoundRobin, PickerRotationWeighted)
+	}
+}
+
+// Pick records who picked an entry
+type Pick struct {
+	EntryID     uuid.UUID `json:"entry_id"`
+	GroupNumber int       `json:"group_number"`
+	MovieTitle  string    `json:"movie_title"`
+	AddedAt     time.Time `json:"added_at"`
+	Person      *Person   `json:"person"`
+}
+
+// PickerStat is one person's pick history, as NextPicker needs it
+type PickerStat struct {
+	Person     *Person
+	GroupCount int       // Picks in the group whose turn is being worked out
+	LastPicked time.Time // Most recent pick in any group
+}
+
+// PickCount is how many picks a person has had in a group
+type PickCount struct {
+	Person *Person `json:"person"`
+	Count  int     `json:"count"`
+}
+
+// PickerTurn is the suggested next picker for a group
+type PickerTurn struct {
+	Rotation    PickerRotation `json:"rotation"`
+	GroupNumber int            `json:"group_number"`
+	Person      *Person        `json:"person"` // nil when there are no active persons
+	Counts      []PickCount    `json:"counts"` // Every active person, in display order
+}
+
+// NextPicker works out whose turn it is to pick in a group.
+// persons are the active persons in display order; stats has a row for
+// everyone who has ever picked, archived or not.
+func NextPicker(rotation PickerRotation, persons []*Person, stats []*PickerStat, groupNumber int) *PickerTurn {
+	turn := &PickerTurn{
+		Rotation:    rotation,
+		GroupNumber: groupNumber,
+		Counts:      make([]PickCount, 0, len(persons)),
+	}
+
+	counts := make(map[uuid.UUID]int, len(stats))
+	lastPicked := make(map[uuid.UUID]time.Time, len(stats))
+	var lastPick *PickerStat
+	for _, stat := range stats {
+		counts[stat.Person.ID] = stat.GroupCount
+		lastPicked[stat.Person.ID] = stat.LastPicked
+		if lastPick == nil || !stat.LastPicked.Before(lastPick.LastPicked) {
+			lastPick = stat
+		}
+	}
+	for _, p := range persons {
+		turn.Counts = append(turn.Counts, PickCount{Person: p, Count: counts[p.ID]})
+	}
+	if len(persons) == 0 {
+		return turn
+	}
+
+	switch rotation {
+	case PickerRotationWeighted:
+		// Fewest picks in the group, then whoever picked longest ago, then display order
+		next := persons[0]
+		for _, p := range persons[1:] {
+			if counts[p.ID] < counts[next.ID] ||
+				(counts[p.ID] == counts[next.ID] && lastPicked[p.ID].Before(lastPicked[next.ID])) {
+				next = p
+			}
+		}
+		turn.Person = next
+	default:
+		// The person after the most recent picker, wrapping round; this also
+		// works when the last picker has since been archived
+		turn.Person = persons[0]
+		if lastPick != nil {
+			for _, p := range persons {
+				if p.Position > lastPick.Person.Position {
+					turn.Person = p
+					break
+				}
+			}
+		}
+	}
+
+	return turn
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNextPicker(t *testing.T) {
+	archivedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := &Person{ID: uuid.New(), Initial: "D", Position: 1}
+	j := &Person{ID: uuid.New(), Initial: "J", Position: 2}
+	x := &Person{ID: uuid.New(), Initial: "X", Position: 3, ArchivedAt: &archivedAt}
+	c := &Person{ID: uuid.New(), Initial: "C", Position: 4}
+	active := []*Person{d, j, c}
+
+	day := func(n int) time.Time { return time.Date(2024, 2, n, 0, 0, 0, 0, time.UTC) }
+	stat := func(p *Person, count, lastDay int) *PickerStat {
+		return &PickerStat{Person: p, GroupCount: count, LastPicked: day(lastDay)}
+	}
+
+	tests := []struct {
+		name       string
+		rotation   PickerRotation
+		persons    []*Person
+		stats      []*PickerStat
+		want       *Person
+		wantCounts []int
+	}{
+		{
+			name:       "round robin, no picks yet",
+			rotation:   PickerRotationRoundRobin,
+			persons:    active,
+			want:       d,
+			wantCounts: []int{0, 0, 0},
+		},
+		{
+			name:       "round robin follows the last picker",
+			rotation:   PickerRotationRoundRobin,
+			persons:    active,
+			stats:      []*PickerStat{stat(d, 1, 1), stat(j, 1, 2)},
+			want:       c,
+			wantCounts: []int{1, 1, 0},
+		},
+		{
+			name:       "round robin wraps round",
+			rotation:   PickerRotationRoundRobin,
+			persons:    active,
+			stats:      []*PickerStat{stat(d, 1, 1), stat(j, 1, 2), stat(c, 1, 3)},
+			want:       d,
+			wantCounts: []int{1, 1, 1},
+		},
+		{
+			name:       "round robin skips past an archived last picker",
+			rotation:   PickerRotationRoundRobin,
+			persons:    active,
+			stats:      []*PickerStat{stat(j, 1, 1), stat(x, 2, 2)},
+			want:       c,
+			wantCounts: []int{0, 1, 0},
+		},
+		{
+			name:       "weighted, no picks yet",
+			rotation:   PickerRotationWeighted,
+			persons:    active,
+			want:       d,
+			wantCounts: []int{0, 0, 0},
+		},
+		{
+			name:       "weighted picks whoever has picked least in the group",
+			rotation:   PickerRotationWeighted,
+			persons:    active,
+			stats:      []*PickerStat{stat(d, 2, 1), stat(j, 0, 5), stat(c, 1, 2)},
+			want:       j,
+			wantCounts: []int{2, 0, 1},
+		},
+		{
+			name:       "weighted breaks ties by who picked longest ago",
+			rotation:   PickerRotationWeighted,
+			persons:    active,
+			stats:      []*PickerStat{stat(d, 1, 4), stat(j, 1, 2), stat(c, 1, 3)},
+			want:       j,
+			wantCounts: []int{1, 1, 1},
+		},
+		{
+			name:       "weighted ignores archived persons",
+			rotation:   PickerRotationWeighted,
+			persons:    active,
+			stats:      []*PickerStat{stat(d, 1, 1), stat(j, 1, 2), stat(x, 0, 3), stat(c, 1, 4)},
+			want:       d,
+			wantCounts: []int{1, 1, 1},
+		},
+		{
+			name:     "no active persons",
+			rotation: PickerRotationRoundRobin,
+			stats:    []*PickerStat{stat(x, 1, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turn := NextPicker(tt.rotation, tt.persons, tt.stats, 7)
+			if turn.Person != tt.want {
+				t.Errorf("Person = %v, want %v", initialOf(turn.Person), initialOf(tt.want))
+			}
+			if turn.Rotation != tt.rotation || turn.GroupNumber != 7 {
+				t.Errorf("turn = %s in group %d, want %s in group 7", turn.Rotation, turn.GroupNumber, tt.rotation)
+			}
+			if len(turn.Counts) != len(tt.wantCounts) {
+				t.Fatalf("got %d counts, want %d", len(turn.Counts), len(tt.wantCounts))
+			}
+			for i, count := range turn.Counts {
+				if count.Person != tt.persons[i] || count.Count != tt.wantCounts[i] {
+					t.Errorf("Counts[%d] = %s: %d, want %s: %d", i, count.Person.Initial, count.Count, tt.persons[i].Initial, tt.wantCounts[i])
+				}
+			}
+		})
+	}
+}
+
+func initialOf(p *Person) string {
+	if p == nil {
+		return "nobody"
+	}
+	return p.Initial
+}
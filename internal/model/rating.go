@@ -8,22 +8,31 @@ import (
 
 // Rating represents a family member's rating for a movie entry
 type Rating struct {
-	ID        uuid.UUID `json:"id"`
-	PersonID  uuid.UUID `json:"person_id"`
-	EntryID   uuid.UUID `json:"entry_id"`
-	Score     float64   `json:"score"` // 0.0 - 10.0
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uuid.UUID  `json:"id"`
+	PersonID  uuid.UUID  `json:"person_id"`
+	EntryID   uuid.UUID  `json:"entry_id"`
+	Score     float64    `json:"score"`                // 0.0 - 10.0
+	ViewingID *uuid.UUID `json:"viewing_id,omitempty"` // The viewing the score was given after
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
 
 	// Joined data (populated by repository)
-	Person *Person `json:"person,omitempty"`
+	Person    *Person          `json:"person,omitempty"`
+	Revisions []RatingRevision `json:"revisions,omitempty"` // Every score so far, oldest first
+}
+
+// RatingRevision is a score a rating had from CreatedAt until the next revision
+type RatingRevision struct {
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // UpsertRatingInput represents the input for creating or updating a rating
 type UpsertRatingInput struct {
-	PersonID uuid.UUID `json:"person_id"`
-	EntryID  uuid.UUID `json:"entry_id"`
-	Score    float64   `json:"score"`
+	PersonID  uuid.UUID  `json:"person_id"`
+	EntryID   uuid.UUID  `json:"entry_id"`
+	Score     float64    `json:"score"`
+	ViewingID *uuid.UUID `json:"viewing_id,omitempty"` // nil keeps the current viewing, uuid.Nil detaches it
 }
 
 // RatingColor returns the color class based on the score
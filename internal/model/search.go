@@ -0,0 +1,13 @@
+package model
+
+import "github.com/google/uuid"
+
+// EntrySearch is a full-text search of the library with optional filters
+type EntrySearch struct {
+	Query           string     // Web search syntax, e.g. `alien "deep space" -comedy`
+	Watched         *bool      // nil = watched or not
+	GroupNumber     *int       // nil = every group
+	RatedByPersonID *uuid.UUID // nil = rated by anyone or no one
+	Limit           int
+	Offset          int // Results to skip, for the pages after the first
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session represents a signed-in browser session for a person
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	PersonID   uuid.UUID `json:"person_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+
+	// Joined data (populated by repository)
+	Person *Person `json:"person,omitempty"`
+}
+
+// CreateSessionInput represents the input for creating a session
+type CreateSessionInput struct {
+	PersonID  uuid.UUID
+	TokenHash []byte // SHA-256 of the cookie value
+	ExpiresAt time.Time
+	UserAgent string
+	IPAddress string
+}
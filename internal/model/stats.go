@@ -0,0 +1,95 @@
+package model
+
+import "github.com/google/uuid"
+
+// Stats summarizes the family's watch history
+type Stats struct {
+	WatchedCount    int              `json:"watched_count"`   // Entries with at least one viewing
+	WatchedMinutes  int              `json:"watched_minutes"` // Sum of runtimes of every viewing, rewatches included
+	WatchedByMonth  []PeriodCount    `json:"watched_by_month"`
+	WatchedByYear   []PeriodCount    `json:"watched_by_year"`
+	PersonAverages  []*PersonAverage `json:"person_averages"`
+	Attendance      []*Attendance    `json:"attendance"`
+	PickerScores    []*PickerScore   `json:"picker_scores"`
+	DivisiveEntries []*DivisiveEntry `json:"divisive_entries"`
+}
+
+// PeriodCount is the number of viewings in a month ("2006-01") or year ("2006")
+type PeriodCount struct {
+	Period string `json:"period"`
+	Count  int    `json:"count"`
+}
+
+// PersonAverage is the average score a person gives
+type PersonAverage struct {
+	Person       *Person `json:"person"`
+	RatingCount  int     `json:"rating_count"`
+	AverageScore float64 `json:"average_score"`
+}
+
+// Attendance separates the watched entries a person missed from those they
+// watched but haven't rated
+type Attendance struct {
+	Person       *Person `json:"person"`
+	WatchedCount int     `json:"watched_count"` // Watched entries they were present for
+	MissedCount  int     `json:"missed_count"`  // Watched entries they weren't present for
+	RatedCount   int     `json:"rated_count"`   // Entries they watched and rated
+	UnratedCount int     `json:"unrated_count"` // Entries they watched but haven't rated
+}
+
+// PickerScore is how well the movies a person picked were rated
+type PickerScore struct {
+	Person       *Person `json:"person"`
+	PickCount    int     `json:"pick_count"`    // Picked entries with at least one rating
+	AverageScore float64 `json:"average_score"` // Mean of the ratings on those entries by people who watched them
+}
+
+// DivisiveEntry is an entry whose ratings disagree the most
+type DivisiveEntry struct {
+	EntryID      uuid.UUID `json:"entry_id"`
+	Title        string    `json:"title"`
+	ReleaseYear  *int      `json:"release_year,omitempty"`
+	RatingCount  int       `json:"rating_count"`
+	AverageScore float64   `json:"average_score"`
+	Variance     float64   `json:"variance"` // Population variance of the scores
+}
+
+// WatchedHours returns the total watched runtime in hours
+func (s *Stats) WatchedHours() float64 {
+	return float64(s.WatchedMinutes) / 60
+}
+
+// TasteComparison compares how family members rate the same entries
+type TasteComparison struct {
+	Persons            []*Person     `json:"persons"`
+	Pairs              []*PersonPair `json:"pairs"`
+	Biases             []*PersonBias `json:"biases"`
+	AgreementThreshold float64       `json:"agreement_threshold"` // Max score difference counted as agreeing
+}
+
+// PersonPair compares two people's scores on the entries both rated
+type PersonPair struct {
+	PersonAID        uuid.UUID `json:"person_a_id"`
+	PersonBID        uuid.UUID `json:"person_b_id"`
+	SharedCount      int       `json:"shared_count"`
+	Correlation      *float64  `json:"correlation"` // Pearson r; nil when undefined
+	MeanAbsoluteDiff float64   `json:"mean_absolute_difference"`
+	AgreementRate    float64   `json:"agreement_rate"` // Share of shared entries within the agreement threshold (0-1)
+}
+
+// PersonBias is how far a person's scores sit from the family average
+type PersonBias struct {
+	Person      *Person `json:"person"`
+	RatingCount int     `json:"rating_count"` // Ratings on entries at least two people rated
+	Bias        float64 `json:"bias"`         // Mean of (score - entry average); positive = generous
+}
+
+// Pair returns the comparison between two people in either order, or nil
+func (c *TasteComparison) Pair(a, b uuid.UUID) *PersonPair {
+	for _, pair := range c.Pairs {
+		if (pair.PersonAID == a && pair.PersonBID == b) || (pair.PersonAID == b && pair.PersonBID == a) {
+			return pair
+		}
+	}
+	return nil
+}
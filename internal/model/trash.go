@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrashKind identifies what a trash item restores
+type TrashKind string
+
+const (
+	TrashKindMovie  TrashKind = "movie"  // A movie with the entries deleted along with it
+	TrashKindEntry  TrashKind = "entry"  // An entry with the ratings deleted along with it
+	TrashKindRating TrashKind = "rating" // A single person's rating
+)
+
+// TrashItem is a deleted movie, entry or rating that can still be restored
+type TrashItem struct {
+	Kind        TrashKind  `json:"kind"`
+	MovieID     uuid.UUID  `json:"movie_id"`
+	MovieTitle  string     `json:"movie_title"`
+	EntryID     *uuid.UUID `json:"entry_id,omitempty"`     // Entries and ratings
+	GroupNumber *int       `json:"group_number,omitempty"` // Entries and ratings
+	Person      *Person    `json:"person,omitempty"`       // Ratings only
+	Score       *float64   `json:"score,omitempty"`        // Ratings only
+	RatingCount int        `json:"rating_count"`           // Ratings restored along with an entry or movie
+	DeletedAt   time.Time  `json:"deleted_at"`
+	PurgeAt     *time.Time `json:"purge_at,omitempty"` // nil = kept until restored
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Viewing is one time an entry was watched
+type Viewing struct {
+	ID        uuid.UUID `json:"id"`
+	EntryID   uuid.UUID `json:"entry_id"`
+	WatchedOn time.Time `json:"watched_on"`
+	Location  *string   `json:"location,omitempty"` // Where or on what, e.g. "Cinema" or "Netflix"
+	Note      *string   `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Joined data (populated by repository)
+	Attendees []*Person `json:"attendees"` // Who was present, in display order
+}
+
+// CreateViewingInput represents the input for recording a viewing
+type CreateViewingInput struct {
+	EntryID     uuid.UUID   `json:"entry_id"`
+	WatchedOn   time.Time   `json:"watched_on"`
+	Location    *string     `json:"location,omitempty"`
+	Note        *string     `json:"note,omitempty"`
+	AttendeeIDs []uuid.UUID `json:"attendee_ids"`
+}
+
+// Attended reports whether the person was present at the viewing
+func (v *Viewing) Attended(personID uuid.UUID) bool {
+	for _, p := range v.Attendees {
+		if p.ID == personID {
+			return true
+		}
+	}
+	return false
+}
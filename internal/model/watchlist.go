@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Suggestion is a movie someone would like the family to watch. It stays on
+// the watchlist until it is promoted to an entry in a group.
+type Suggestion struct {
+	ID                  uuid.UUID  `json:"id"`
+	MovieID             uuid.UUID  `json:"movie_id"`
+	SuggestedByPersonID *uuid.UUID `json:"suggested_by_person_id,omitempty"`
+	Reason              *string    `json:"reason,omitempty"` // Why it's worth watching
+	CreatedAt           time.Time  `json:"created_at"`
+	PromotedAt          *time.Time `json:"promoted_at,omitempty"` // nil = still on the watchlist
+	PromotedEntryID     *uuid.UUID `json:"promoted_entry_id,omitempty"`
+
+	// Joined data (populated by repository)
+	Movie       *Movie    `json:"movie,omitempty"`
+	SuggestedBy *Person   `json:"suggested_by,omitempty"`
+	Voters      []*Person `json:"voters"` // Who upvoted, in display order
+}
+
+// CreateSuggestionInput represents the input for suggesting a movie
+type CreateSuggestionInput struct {
+	MovieID             uuid.UUID  `json:"movie_id"`
+	SuggestedByPersonID *uuid.UUID `json:"suggested_by_person_id,omitempty"`
+	Reason              *string    `json:"reason,omitempty"`
+}
+
+// VoteCount returns the number of upvotes
+func (s *Suggestion) VoteCount() int {
+	return len(s.Voters)
+}
+
+// HasVoteFrom reports whether the person upvoted the suggestion
+func (s *Suggestion) HasVoteFrom(personID uuid.UUID) bool {
+	for _, p := range s.Voters {
+		if p.ID == personID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSuggestedBy reports whether the person made the suggestion
+func (s *Suggestion) IsSuggestedBy(personID uuid.UUID) bool {
+	return s.SuggestedByPersonID != nil && *s.SuggestedByPersonID == personID
+}
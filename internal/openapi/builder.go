@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Content types used by the app
+const (
+	contentJSON      = "application/json"
+	contentForm      = "application/x-www-form-urlencoded"
+	contentMultipart = "multipart/form-data"
+	contentHTML      = "text/html"
+	contentText      = "text/plain"
+)
+
+// builder accumulates operations into a Document
+type builder struct {
+	doc     *Document
+	schemas *schemaRegistry
+}
+
+// operation wraps an Operation with chainable setters
+type operation struct {
+	*Operation
+}
+
+// route adds an operation for method and path (in chi pattern syntax)
+func (b *builder) route(method, path, operationID, summary string, tag string) operation {
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = PathItem{}
+		b.doc.Paths[path] = item
+	}
+	method = strings.ToLower(method)
+	if _, exists := item[method]; exists {
+		panic(fmt.Sprintf("openapi: duplicate operation %s %s", method, path))
+	}
+
+	op := &Operation{
+		OperationID: operationID,
+		Summary:     summary,
+		Tags:        []string{tag},
+		Responses:   make(map[string]Response),
+	}
+	item[method] = op
+	return operation{op}
+}
+
+// public marks the operation as not requiring authentication
+func (o operation) public() operation {
+	none := []map[string][]string{}
+	o.Security = &none
+	return o
+}
+
+// scope records the scopes a named API token needs for the operation
+func (o operation) scope(scopes ...string) operation {
+	o.Scopes = scopes
+	return o
+}
+
+// describe sets the operation's long description
+func (o operation) describe(description string) operation {
+	o.Description = description
+	return o
+}
+
+// path declares a path parameter
+func (o operation) path(name, description string, schema *Schema) operation {
+	o.Parameters = append(o.Parameters, Parameter{
+		Name:        name,
+		In:          "path",
+		Required:    true,
+		Description: description,
+		Schema:      schema,
+	})
+	return o
+}
+
+// query declares a query parameter
+func (o operation) query(name, description string, schema *Schema, required bool) operation {
+	o.Parameters = append(o.Parameters, Parameter{
+		Name:        name,
+		In:          "query",
+		Required:    required,
+		Description: description,
+		Schema:      schema,
+	})
+	return o
+}
+
+// form declares a form-encoded request body
+func (o operation) form(fields ...formField) operation {
+	o.body(contentForm, formSchema(fields), true)
+	return o
+}
+
+// multipart declares a multipart/form-data request body, for file uploads
+func (o operation) multipart(fields ...formField) operation {
+	o.body(contentMultipart, formSchema(fields), true)
+	return o
+}
+
+func formSchema(fields []formField) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range fields {
+		schema.Properties[field.name] = field.schema
+		if field.required {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
+
+// json declares a JSON request body
+func (o operation) json(schema *Schema, required bool) operation {
+	o.body(contentJSON, schema, required)
+	return o
+}
+
+func (o operation) body(contentType string, schema *Schema, required bool) {
+	if o.RequestBody == nil {
+		o.RequestBody = &RequestBody{Content: make(map[string]MediaType)}
+	}
+	o.RequestBody.Required = o.RequestBody.Required || required
+	o.RequestBody.Content[contentType] = MediaType{Schema: schema}
+}
+
+// respond adds a response for a status code
+func (o operation) respond(status int, response Response) operation {
+	o.Responses[statusKey(status)] = response
+	return o
+}
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
+
+// formField describes one field of a form-encoded body
+type formField struct {
+	name     string
+	schema   *Schema
+	required bool
+}
+
+func field(name string, schema *Schema, required bool, description string) formField {
+	described := *schema
+	described.Description = description
+	return formField{name: name, schema: &described, required: required}
+}
+
+// Schema shorthands
+
+func stringSchema() *Schema  { return &Schema{Type: "string"} }
+func uuidSchema() *Schema    { return &Schema{Type: "string", Format: "uuid"} }
+func dateSchema() *Schema    { return &Schema{Type: "string", Format: "date"} }
+func integerSchema() *Schema { return &Schema{Type: "integer", Format: "int32"} }
+func booleanSchema() *Schema { return &Schema{Type: "boolean"} }
+
+func numberRange(min, max float64) *Schema {
+	return &Schema{Type: "number", Format: "double", Minimum: &min, Maximum: &max}
+}
+
+func arrayOf(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+func enumSchema(values ...string) *Schema { return &Schema{Type: "string", Enum: values} }
+
+// Response shorthands
+
+func htmlResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentHTML: {Schema: stringSchema()}},
+	}
+}
+
+// htmxResponse is an HTML fragment or empty body with an HX-Trigger header
+func htmxResponse(description string, withBody bool) Response {
+	response := Response{
+		Description: description,
+		Headers:     map[string]*Header{"HX-Trigger": {Ref: "#/components/headers/HX-Trigger"}},
+	}
+	if withBody {
+		response.Content = map[string]MediaType{contentHTML: {Schema: stringSchema()}}
+	}
+	return response
+}
+
+func textResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentText: {Schema: stringSchema()}},
+	}
+}
+
+func jsonResponse(description string, schema *Schema) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentJSON: {Schema: schema}},
+	}
+}
+
+func redirectResponse(description string) Response {
+	return Response{
+		Description: description,
+		Headers: map[string]*Header{"Location": {
+			Description: "Where the browser is sent",
+			Schema:      stringSchema(),
+		}},
+	}
+}
+
+func emptyResponse(description string) Response {
+	return Response{Description: description}
+}
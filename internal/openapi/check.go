@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// CheckRoutes compares the routes registered on router with the spec. It
+// reports routes without a spec entry, spec entries without a route, and
+// operations whose path parameters are not all declared. Routes matching a
+// pattern in ignore (such as static files) are skipped.
+func CheckRoutes(router chi.Routes, doc *Document, ignore ...string) error {
+	var problems []string
+
+	registered := make(map[string]bool)
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if slices.Contains(ignore, route) {
+			return nil
+		}
+		key := routeKey(method, route)
+		registered[key] = true
+		if item, ok := doc.Paths[route]; !ok || item[strings.ToLower(method)] == nil {
+			problems = append(problems, "route has no spec entry: "+key)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk routes: %w", err)
+	}
+
+	for _, key := range doc.Operations() {
+		if !registered[key] {
+			problems = append(problems, "spec entry has no route: "+key)
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+				if !slices.ContainsFunc(op.Parameters, func(p Parameter) bool {
+					return p.In == "path" && p.Name == match[1]
+				}) {
+					problems = append(problems, fmt.Sprintf("path parameter %s not declared: %s", match[1], routeKey(method, path)))
+				}
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	slices.Sort(problems)
+	return errors.New("openapi spec is out of date:\n  " + strings.Join(problems, "\n  "))
+}
+
+// routeKey formats a method and path as "GET /path"
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
@@ -0,0 +1,129 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	uuidType       = reflect.TypeFor[uuid.UUID]()
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaRegistry derives schemas from Go types using their json tags.
+// Named structs become components and are referenced with $ref.
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// of returns the schema for the type of v
+func (g *schemaRegistry) of(v any) *Schema {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+// listOf returns an array schema of the type of v
+func (g *schemaRegistry) listOf(v any) *Schema {
+	return &Schema{Type: "array", Items: g.of(v)}
+}
+
+func (g *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{Description: "Arbitrary JSON"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		inner := g.schemaFor(t.Elem())
+		if inner.Ref != "" {
+			return &Schema{AllOf: []*Schema{inner}, Nullable: true}
+		}
+		nullable := *inner
+		nullable.Nullable = true
+		return &nullable
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		elem := t.Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem() // Slices of pointers never hold nil
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(elem)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.component(t)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	default:
+		return &Schema{}
+	}
+}
+
+// component registers a struct as a named schema and returns a reference to it
+func (g *schemaRegistry) component(t reflect.Type) *Schema {
+	name := t.Name()
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if existing, ok := g.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: schema name %s used by both %s and %s", name, existing, t))
+		}
+		return ref
+	}
+	g.types[name] = t
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.schemas[name] = schema // Registered before fields so recursive types terminate
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		fieldName, opts, _ := strings.Cut(tag, ",")
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+
+		schema.Properties[fieldName] = g.schemaFor(field.Type)
+
+		omitempty := strings.Contains(opts, "omitempty")
+		optional := field.Type.Kind() == reflect.Pointer || field.Type == rawMessageType
+		if !omitempty && !optional {
+			schema.Required = append(schema.Required, fieldName)
+		}
+	}
+
+	return ref
+}
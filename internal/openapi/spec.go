@@ -0,0 +1,947 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/drywaters/seenema/internal/apijson"
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/handler"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/tmdb"
+)
+
+// Path is where the app serves the spec
+const Path = "/openapi.json"
+
+// Tags used to group operations
+const (
+	tagAuth      = "auth"
+	tagPages     = "pages"
+	tagHTMX      = "htmx"
+	tagAccount   = "account"
+	tagMovies    = "movies"
+	tagEntries   = "entries"
+	tagGroups    = "groups"
+	tagRatings   = "ratings"
+	tagPersons   = "persons"
+	tagStats     = "stats"
+	tagWatchlist = "watchlist"
+	tagExport    = "export"
+	tagInternal  = "meta"
+)
+
+var (
+	specOnce sync.Once
+	spec     *Document
+	specJSON []byte
+)
+
+// Spec returns the OpenAPI document describing every route in server.Router
+func Spec() *Document {
+	specOnce.Do(build)
+	return spec
+}
+
+// ServeSpec writes the OpenAPI document as JSON
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(build)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(specJSON)
+}
+
+func build() {
+	b := &builder{
+		doc: &Document{
+			OpenAPI: "3.0.3",
+			Info: Info{
+				Title:   "Seenema",
+				Version: "1.0.0",
+				Description: "Family movie night tracker. Routes under /api/v1 are the stable JSON API; " +
+					"the other routes serve the HTMX web UI and return HTML fragments and HX-Trigger headers.",
+			},
+			Security: []map[string][]string{{"bearerAuth": {}}, {"cookieAuth": {}}},
+			Tags: []Tag{
+				{Name: tagMovies, Description: "JSON API: library movies and TMDB import"},
+				{Name: tagEntries, Description: "JSON API: movies placed in a watch group"},
+				{Name: tagGroups, Description: "JSON API: watch groups. Groups hold entries, so API tokens use entries:read and entries:write"},
+				{Name: tagRatings, Description: "JSON API: per-person ratings"},
+				{Name: tagPersons, Description: "JSON API: family members"},
+				{Name: tagStats, Description: "JSON API: watch-history statistics. They are built from ratings, so API tokens need ratings:read"},
+				{Name: tagWatchlist, Description: "JSON API: suggested movies waiting to join a group. Suggestions use movies:read and movies:write; promoting one creates an entry, so it needs entries:write"},
+				{Name: tagAuth, Description: "Sign in and out"},
+				{Name: tagAccount, Description: "Password, sessions and API tokens (browser sessions only)"},
+				{Name: tagPages, Description: "Full HTML pages"},
+				{Name: tagHTMX, Description: "Form endpoints used by the web UI"},
+				{Name: tagExport, Description: "Whole-collection downloads for spreadsheets and backups. They include every entry and score, so API tokens need entries:read and ratings:read"},
+				{Name: tagInternal, Description: "Health and discovery"},
+			},
+			Paths: make(map[string]PathItem),
+		},
+		schemas: newSchemaRegistry(),
+	}
+
+	addMetaRoutes(b)
+	addPageRoutes(b)
+	addHTMXRoutes(b)
+	addAPIRoutes(b)
+	addExportRoutes(b)
+
+	// UpdateEntryRequest keeps picked_by_person_id raw so it can tell null from omitted
+	b.schemas.of(handler.UpdateEntryRequest{})
+	b.schemas.schemas["UpdateEntryRequest"].Properties["picked_by_person_id"] = &Schema{
+		Type:        "string",
+		Format:      "uuid",
+		Nullable:    true,
+		Description: "null clears the picker; omit to leave unchanged",
+	}
+
+	addAuthResponses(b.doc)
+
+	b.doc.Components = Components{
+		Schemas: b.schemas.schemas,
+		Headers: map[string]*Header{
+			"HX-Trigger": {
+				Description: `HTMX events for the web UI, e.g. {"showToast": {"message": "Saved!", "type": "success"}, "refreshGroups": true}`,
+				Schema:      stringSchema(),
+			},
+		},
+		SecuritySchemes: map[string]SecurityScheme{
+			"bearerAuth": {
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: scopesDescription(),
+			},
+			"cookieAuth": {
+				Type:        "apiKey",
+				In:          "cookie",
+				Name:        auth.SessionCookieName,
+				Description: "Browser session set by POST /login",
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(b.doc, "", "  ")
+	if err != nil {
+		panic("openapi: marshal spec: " + err.Error())
+	}
+	spec, specJSON = b.doc, data
+}
+
+// scopesDescription lists what each named API token scope grants
+func scopesDescription() string {
+	var sb strings.Builder
+	sb.WriteString("The master API token, or a named token limited to the scopes in x-required-scopes:")
+	for _, scope := range model.APITokenScopes {
+		sb.WriteString("\n- " + scope + ": " + model.APITokenScopeDescriptions[scope])
+	}
+	sb.WriteString("\n\n" + model.APITokenScopeNote)
+	return sb.String()
+}
+
+// addAuthResponses adds 401 to every protected operation and 403 to scoped ones
+func addAuthResponses(doc *Document) {
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			if op.Security != nil {
+				continue
+			}
+			if _, ok := op.Responses["401"]; !ok {
+				op.Responses["401"] = emptyResponse("Not signed in (browsers are redirected to /login instead)")
+			}
+			if len(op.Scopes) > 0 {
+				if _, ok := op.Responses["403"]; !ok {
+					op.Responses["403"] = emptyResponse("API token lacks scope " + strings.Join(op.Scopes, " or "))
+				}
+			}
+		}
+	}
+}
+
+func addMetaRoutes(b *builder) {
+	b.route(http.MethodGet, "/health", "health", "Health check", tagInternal).public().
+		respond(http.StatusOK, textResponse("Always \"ok\""))
+	b.route(http.MethodGet, Path, "openAPISpec", "This OpenAPI document", tagInternal).public().
+		respond(http.StatusOK, jsonResponse("OpenAPI 3.0 document", &Schema{Type: "object"}))
+}
+
+// addExportRoutes documents the whole-collection downloads
+func addExportRoutes(b *builder) {
+	const columns = "One row per entry by group and position: entry ID, title, year, TMDB and IMDb IDs, " +
+		"group number and name, position, first watched date, picker, notes and each person's score. " +
+		"Rows stream as they are read."
+	b.route(http.MethodGet, "/export.csv", "exportCSV", "Download the collection as CSV", tagExport).
+		scope(model.ScopeEntriesRead, model.ScopeRatingsRead).
+		describe(columns+" Scores are one column per person, headed by their name.").
+		respond(http.StatusOK, Response{
+			Description: "CSV attachment",
+			Content:     map[string]MediaType{"text/csv": {Schema: stringSchema()}},
+		})
+	b.route(http.MethodGet, "/export.json", "exportJSON", "Download the collection as JSON", tagExport).
+		scope(model.ScopeEntriesRead, model.ScopeRatingsRead).
+		describe(columns+" Scores are an object keyed by person name.").
+		respond(http.StatusOK, Response{
+			Description: "JSON attachment: an array of entry objects",
+			Content:     map[string]MediaType{contentJSON: {Schema: arrayOf(&Schema{Type: "object"})}},
+		})
+}
+
+func addPageRoutes(b *builder) {
+	b.route(http.MethodGet, "/login", "loginPage", "Sign-in page", tagAuth).public().
+		query("redirect", "Relative URL to return to after signing in", stringSchema(), false).
+		query("error", "Error to display", enumSchema("missing_credentials", "invalid_credentials", "invalid_request"), false).
+		respond(http.StatusOK, htmlResponse("Sign-in page"))
+	b.route(http.MethodPost, "/login", "login", "Sign in", tagAuth).public().
+		describe("A person without a password signs in with the master API token and is then asked to set one.").
+		form(
+			field("person_id", uuidSchema(), true, "Who is signing in"),
+			field("password", stringSchema(), true, "Password, or the master API token for a first sign-in"),
+			field("redirect", stringSchema(), false, "Relative URL to return to"),
+		).
+		respond(http.StatusSeeOther, redirectResponse("Session cookie set, or back to /login with an error"))
+	b.route(http.MethodPost, "/logout", "logout", "Sign out", tagAuth).public().
+		respond(http.StatusSeeOther, redirectResponse("Session ended; redirects to /login"))
+
+	b.route(http.MethodGet, "/", "dashboardPage", "Dashboard", tagPages).
+		respond(http.StatusOK, htmlResponse("Dashboard page"))
+	b.route(http.MethodGet, "/dashboard-content", "dashboardContent", "Dashboard groups", tagPages).
+		respond(http.StatusOK, htmlResponse("Dashboard groups fragment"))
+	b.route(http.MethodGet, "/movies/{id}", "movieDetailPage", "Entry detail page", tagPages).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, htmlResponse("Entry detail page")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodGet, "/partials/group/{num}", "groupPartial", "Group section fragment", tagPages).
+		path("num", "Group number", integerSchema()).
+		query("more", "Also return a loader for the next older group", booleanSchema(), false).
+		respond(http.StatusOK, htmlResponse("Group section"))
+	b.route(http.MethodGet, "/partials/rating-form/{entryId}/{personId}", "ratingFormPartial", "Rating input fragment", tagPages).
+		path("entryId", "Entry ID", uuidSchema()).
+		path("personId", "Person whose rating is edited; must be the signed-in person", uuidSchema()).
+		respond(http.StatusOK, htmlResponse("Rating form")).
+		respond(http.StatusForbidden, textResponse("Not the signed-in person")).
+		respond(http.StatusNotFound, textResponse("Entry or person not found"))
+	searchFilters(b.route(http.MethodGet, "/partials/search-results", "searchResultsPartial", "Library search results fragment", tagPages)).
+		query("q", "Search text", stringSchema(), false).
+		respond(http.StatusOK, htmlResponse("Matching entries, best match first")).
+		respond(http.StatusBadRequest, textResponse("Invalid filter"))
+	libraryFilters(b.route(http.MethodGet, "/partials/library", "libraryRowsPartial", "Next page of library cards", tagPages)).
+		respond(http.StatusOK, htmlResponse("Cards followed by a loader for the page after")).
+		respond(http.StatusBadRequest, textResponse("Invalid filter or cursor"))
+	historyFilters(b.route(http.MethodGet, "/partials/entry-history/{id}", "entryHistoryPartial", "Change history fragment for the movie detail page", tagPages)).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, htmlResponse("Filter form and events, newest first")).
+		respond(http.StatusBadRequest, textResponse("Invalid filter"))
+	libraryFilters(b.route(http.MethodGet, "/library", "libraryPage", "Library browse page", tagPages)).
+		respond(http.StatusOK, htmlResponse("Library page")).
+		respond(http.StatusBadRequest, textResponse("Invalid filter or cursor"))
+	searchFilters(b.route(http.MethodGet, "/search", "searchPage", "Library search page", tagPages)).
+		query("q", "Search text", stringSchema(), false).
+		respond(http.StatusOK, htmlResponse("Search page")).
+		respond(http.StatusBadRequest, textResponse("Invalid filter"))
+	b.route(http.MethodGet, "/groups", "groupsPage", "Group management page", tagPages).
+		respond(http.StatusOK, htmlResponse("Groups page"))
+	b.route(http.MethodGet, "/trash", "trashPage", "Deleted items that can be restored", tagPages).
+		respond(http.StatusOK, htmlResponse("Trash page"))
+	b.route(http.MethodGet, "/watchlist", "watchlistPage", "Suggested movies and their upvotes", tagPages).
+		respond(http.StatusOK, htmlResponse("Watchlist page"))
+	b.route(http.MethodGet, "/import", "importPage", "Upload form for Letterboxd and IMDb exports", tagPages).
+		respond(http.StatusOK, htmlResponse("Import page"))
+	b.route(http.MethodGet, "/stats", "statsPage", "Watch-history statistics page", tagPages).
+		respond(http.StatusOK, htmlResponse("Stats page"))
+	b.route(http.MethodGet, "/stats/taste", "tastePage", "Taste comparison page", tagPages).
+		respond(http.StatusOK, htmlResponse("Agreement matrix and personal bias"))
+	b.route(http.MethodGet, "/settings", "settingsPage", "Settings page", tagPages).
+		respond(http.StatusOK, htmlResponse("Settings page"))
+	b.route(http.MethodGet, "/settings/sessions", "sessionsPage", "Signed-in devices page", tagPages).
+		respond(http.StatusOK, htmlResponse("Sessions page"))
+	b.route(http.MethodGet, "/settings/tokens", "apiTokensPage", "API tokens page", tagPages).
+		respond(http.StatusOK, htmlResponse("API tokens page"))
+}
+
+func addHTMXRoutes(b *builder) {
+	// The Undo button in a delete toast restores with undo=true
+	restoreForm := []formField{
+		field("undo", booleanSchema(), false, "Respond with HX-Refresh instead of the trash list"),
+	}
+
+	// Account
+	b.route(http.MethodPost, "/api/account/password", "changePassword", "Set or change the signed-in person's password", tagAccount).
+		form(
+			field("current_password", stringSchema(), false, "Required when a password is already set"),
+			field("new_password", stringSchema(), true, "At least 8 characters"),
+			field("confirm_password", stringSchema(), true, "Must match new_password"),
+		).
+		respond(http.StatusOK, htmxResponse("Result toast (success or error)", false))
+	b.route(http.MethodPost, "/api/sessions/revoke-all", "revokeAllSessions", "Sign out every device", tagAccount).
+		respond(http.StatusOK, Response{
+			Description: "All sessions ended",
+			Headers:     map[string]*Header{"HX-Redirect": {Description: "Always /login", Schema: stringSchema()}},
+		})
+	b.route(http.MethodDelete, "/api/sessions/{id}", "revokeSession", "Sign out one device", tagAccount).
+		path("id", "Session ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated session list", true)).
+		respond(http.StatusNotFound, textResponse("Session not found"))
+	b.route(http.MethodPost, "/api/tokens", "createAPIToken", "Create a named API token", tagAccount).
+		form(
+			field("name", stringSchema(), true, "What the token is for"),
+			field("scopes", arrayOf(enumSchema(model.APITokenScopes...)), true, "Repeat for each scope"),
+			field("expires_in_days", integerSchema(), false, "Omit or 0 for a token that never expires"),
+		).
+		respond(http.StatusOK, htmxResponse("Updated token list showing the secret once", true)).
+		respond(http.StatusCreated, jsonResponse("Created token and its secret, when JSON is requested", b.schemas.of(handler.CreateAPITokenResponse{}))).
+		respond(http.StatusBadRequest, textResponse("Invalid name, scope or expiry"))
+	b.route(http.MethodDelete, "/api/tokens/{id}", "revokeAPIToken", "Revoke an API token", tagAccount).
+		path("id", "Token ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated token list", true)).
+		respond(http.StatusNotFound, textResponse("Token not found"))
+
+	// Movies and entries
+	b.route(http.MethodGet, "/api/tmdb/search", "searchTMDBFragment", "Search TMDB", tagHTMX).
+		scope(model.ScopeMoviesRead).
+		query("q", "Search text", stringSchema(), false).
+		query("for", "watchlist to offer suggesting each result instead of adding it to a group", enumSchema("watchlist"), false).
+		respond(http.StatusOK, htmlResponse("Search results fragment"))
+	b.route(http.MethodPost, "/api/tmdb/add", "addFromTMDB", "Add a TMDB movie to a group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		form(
+			field("tmdb_id", integerSchema(), true, "TMDB movie ID"),
+			field("group_number", integerSchema(), false, "Defaults to 1"),
+			field("assign_picker", booleanSchema(), false, "Credit the pick to whoever's turn it is"),
+		).
+		respond(http.StatusOK, htmxResponse("Movie added; triggers refreshGroups", false)).
+		respond(http.StatusBadRequest, textResponse("Invalid TMDB ID, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, textResponse("Movie not found on TMDB"))
+	b.route(http.MethodPut, "/api/entries/{id}", "updateEntryForm", "Update an entry", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		form(
+			field("group_number", integerSchema(), false, "Move to this group"),
+			field("notes", stringSchema(), false, "Notes"),
+			field("picked_by_person_id", uuidSchema(), false, "Empty clears the picker"),
+		).
+		respond(http.StatusOK, htmxResponse("Entry updated; triggers refreshGroups", false)).
+		respond(http.StatusBadRequest, textResponse("Group neither exists nor is the next new one")).
+		respond(http.StatusConflict, textResponse("Movie is already in that group"))
+	b.route(http.MethodDelete, "/api/entries/{id}", "deleteEntryForm", "Delete an entry", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Entry moved to the trash; triggers refreshGroups and a toast with an undo path", false))
+	b.route(http.MethodPost, "/api/entries/{id}/restore", "restoreEntryForm", "Restore a deleted entry", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		form(restoreForm...).
+		respond(http.StatusOK, htmxResponse("Updated trash list, or a page refresh when undoing", true)).
+		respond(http.StatusNotFound, textResponse("Entry not in the trash")).
+		respond(http.StatusConflict, textResponse("Movie is already in that group again"))
+	b.route(http.MethodPost, "/api/movies/{id}/restore", "restoreMovieForm", "Restore a deleted movie and its entries", tagHTMX).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Movie ID", uuidSchema()).
+		form(restoreForm...).
+		respond(http.StatusOK, htmxResponse("Updated trash list, or a page refresh when undoing", true)).
+		respond(http.StatusNotFound, textResponse("Movie not in the trash")).
+		respond(http.StatusConflict, textResponse("Movie is already in one of its groups again"))
+
+	// Import
+	b.route(http.MethodPost, "/api/import", "importRatingsForm", "Import a Letterboxd or IMDb export", tagHTMX).
+		scope(model.ScopeEntriesWrite, model.ScopeRatingsWrite).
+		describe("Accepts Letterboxd ratings.csv, diary.csv or watched.csv, or an IMDb ratings export, told apart by the header. "+
+			"Rows are matched on TMDB by IMDb ID, then title and year. Matched movies are added to the group with the person's "+
+			"rating and a viewing on the row's date, reusing entries and same-day viewings from earlier imports.").
+		multipart(
+			field("file", &Schema{Type: "string", Format: "binary"}, true, "The CSV export"),
+			field("person_id", uuidSchema(), true, "Whose ratings these are"),
+			field("group_number", integerSchema(), true, "Group to add the movies to"),
+			field("dry_run", enumSchema("true"), false, "Only match the rows and show what would be imported"),
+		).
+		respond(http.StatusOK, htmxResponse("Preview, or the import's totals with a toast", true)).
+		respond(http.StatusBadRequest, textResponse("Unreadable file, unknown format, person or group")).
+		respond(http.StatusForbidden, textResponse("Signed-in persons can only import their own ratings")).
+		respond(http.StatusBadGateway, textResponse("TMDB lookups failed"))
+
+	// Watchlist
+	b.route(http.MethodPost, "/api/watchlist", "suggestMovieForm", "Suggest a TMDB movie for the watchlist", tagHTMX).
+		scope(model.ScopeMoviesWrite).
+		form(
+			field("tmdb_id", integerSchema(), true, "TMDB movie ID"),
+			field("reason", stringSchema(), false, "Why it's worth watching"),
+			field("person_id", uuidSchema(), false, "Who suggests it; ignored when a person is signed in"),
+		).
+		respond(http.StatusOK, htmxResponse("Updated watchlist", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid TMDB ID or unknown person")).
+		respond(http.StatusNotFound, textResponse("Movie not found on TMDB")).
+		respond(http.StatusConflict, textResponse("Movie is already on the watchlist"))
+	b.route(http.MethodDelete, "/api/watchlist/{id}", "withdrawSuggestionForm", "Withdraw a suggestion", tagHTMX).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated watchlist", true)).
+		respond(http.StatusNotFound, textResponse("Suggestion not found"))
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		operationID, summary := "voteForSuggestionForm", "Upvote a suggestion"
+		if method == http.MethodDelete {
+			operationID, summary = "unvoteSuggestionForm", "Withdraw an upvote"
+		}
+		b.route(method, "/api/watchlist/{id}/votes/{personId}", operationID, summary, tagHTMX).
+			scope(model.ScopeMoviesWrite).
+			path("id", "Suggestion ID", uuidSchema()).
+			path("personId", "Person ID", uuidSchema()).
+			respond(http.StatusOK, htmlResponse("Updated watchlist")).
+			respond(http.StatusBadRequest, textResponse("Suggesters can't upvote their own suggestion")).
+			respond(http.StatusForbidden, textResponse("Signed-in persons can only vote for themselves")).
+			respond(http.StatusNotFound, textResponse("Suggestion not found"))
+	}
+	b.route(http.MethodPost, "/api/watchlist/{id}/promote", "promoteSuggestionForm", "Add a suggestion to a group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		form(field("group_number", integerSchema(), false, "Defaults to the current group")).
+		respond(http.StatusOK, htmxResponse("Updated watchlist", true)).
+		respond(http.StatusBadRequest, textResponse("Group neither exists nor is the next new one")).
+		respond(http.StatusNotFound, textResponse("Suggestion not found")).
+		respond(http.StatusConflict, textResponse("Movie is already in the group"))
+	b.route(http.MethodPost, "/api/entries/{id}/watched", "markWatchedForm", "Mark an entry watched", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Records a viewing with everyone active present, unless the entry already has a viewing that day.").
+		form(field("watched_at", dateSchema(), false, "Defaults to today")).
+		respond(http.StatusOK, htmxResponse("Viewing timeline fragment", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid date")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodDelete, "/api/entries/{id}/watched", "clearWatchedForm", "Remove an entry's latest viewing", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Removes the most recent viewing, keeping earlier ones. The entry is unwatched once none are left.").
+		respond(http.StatusOK, htmxResponse("Viewing timeline fragment", true))
+	b.route(http.MethodPost, "/api/entries/{id}/viewings", "addViewingForm", "Record a viewing", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		form(
+			field("watched_on", dateSchema(), true, "Date of the viewing"),
+			field("location", stringSchema(), false, "Where or on what, e.g. Cinema or Netflix"),
+			field("note", stringSchema(), false, "Optional note"),
+			field("attendee", arrayOf(uuidSchema()), false, "Person who was present; repeat for each"),
+		).
+		respond(http.StatusOK, htmxResponse("Viewing timeline fragment", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid date or person ID")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodDelete, "/api/entries/{id}/viewings/{viewingId}", "deleteViewingForm", "Remove a viewing", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("viewingId", "Viewing ID", uuidSchema()).
+		describe("Ratings given after the viewing are kept but no longer attached to it.").
+		respond(http.StatusOK, htmxResponse("Viewing timeline fragment", true)).
+		respond(http.StatusNotFound, textResponse("Viewing not found"))
+	b.route(http.MethodPut, "/api/entries/{id}/attendance/{personId}", "setAttendanceForm", "Mark a person present or absent", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("personId", "Person ID", uuidSchema()).
+		describe("Present adds the person to the latest viewing; absent removes them from every viewing. Averages and the fully-rated count only include people who were present.").
+		form(field("present", booleanSchema(), true, "true if the person watched the entry")).
+		respond(http.StatusOK, htmlResponse("Ratings grid fragment, with the viewing timeline out of band")).
+		respond(http.StatusConflict, textResponse("Entry has no viewings yet"))
+	b.route(http.MethodPost, "/api/entries/{id}/move", "moveEntryForm", "Move an entry to another group (drag and drop)", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		json(b.schemas.of(handler.MoveEntryRequest{}), true).
+		respond(http.StatusOK, htmxResponse("Entry moved", false)).
+		respond(http.StatusBadRequest, textResponse("Group neither exists nor is the next new one")).
+		respond(http.StatusConflict, textResponse("Movie is already in that group, or the entry was moved by another request")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodPost, "/api/entries/{id}/copy", "copyEntryForm", "Copy an entry to another group (drag and drop)", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		json(b.schemas.of(handler.MoveEntryRequest{}), true).
+		respond(http.StatusOK, htmxResponse("Entry copied", false)).
+		respond(http.StatusBadRequest, textResponse("Group neither exists nor is the next new one")).
+		respond(http.StatusConflict, textResponse("Movie is already in that group")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodPost, "/api/groups/{num}/reorder", "reorderGroupForm", "Reorder a group (drag and drop)", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		json(b.schemas.of(handler.ReorderRequest{}), true).
+		respond(http.StatusOK, emptyResponse("Order saved"))
+
+	// Groups
+	groupForm := []formField{
+		field("name", stringSchema(), false, "e.g. Spooky October; empty clears"),
+		field("theme", stringSchema(), false, "Short theme label; empty clears"),
+		field("description", stringSchema(), false, "Empty clears"),
+		field("starts_on", dateSchema(), false, "Empty clears"),
+		field("ends_on", dateSchema(), false, "Not before starts_on; empty clears"),
+	}
+	b.route(http.MethodPost, "/api/groups", "createGroupForm", "Add a group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		form(append(groupForm, field("current", booleanSchema(), false, "Make it the current group"))...).
+		respond(http.StatusOK, htmxResponse("Updated group list", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid date"))
+	b.route(http.MethodPut, "/api/groups/{num}", "updateGroupForm", "Edit a group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		form(groupForm...).
+		respond(http.StatusOK, htmxResponse("Updated group list", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid date")).
+		respond(http.StatusNotFound, textResponse("Group not found"))
+	b.route(http.MethodPost, "/api/groups/{num}/current", "setCurrentGroupForm", "Make a group current", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, htmxResponse("Updated group list", true)).
+		respond(http.StatusNotFound, textResponse("Group not found"))
+	b.route(http.MethodPost, "/api/groups/{num}/archive", "archiveGroupForm", "Archive a group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, htmxResponse("Updated group list", true)).
+		respond(http.StatusNotFound, textResponse("Group not found"))
+	b.route(http.MethodDelete, "/api/groups/{num}/archive", "unarchiveGroupForm", "Restore an archived group", tagHTMX).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, htmxResponse("Updated group list", true)).
+		respond(http.StatusNotFound, textResponse("Group not found"))
+
+	// Ratings
+	b.route(http.MethodPost, "/api/ratings", "saveRatingForm", "Save a rating", tagHTMX).
+		scope(model.ScopeRatingsWrite).
+		form(
+			field("entry_id", uuidSchema(), true, "Entry ID"),
+			field("score", numberRange(0, 10), true, "Score from 0.0 to 10.0"),
+			field("person_id", uuidSchema(), false, "Token clients only; browsers always rate as the signed-in person"),
+		).
+		respond(http.StatusOK, htmxResponse("Updated rating row", true)).
+		respond(http.StatusBadRequest, textResponse("Invalid entry, score or person; token clients must name an active person")).
+		respond(http.StatusNotFound, textResponse("Entry not found"))
+	b.route(http.MethodDelete, "/api/ratings/{personId}/{entryId}", "deleteRatingForm", "Delete a rating", tagHTMX).
+		scope(model.ScopeRatingsWrite).
+		path("personId", "Person whose rating is removed", uuidSchema()).
+		path("entryId", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated rating row; the toast carries an undo path", true)).
+		respond(http.StatusForbidden, textResponse("Not the signed-in person"))
+	b.route(http.MethodPost, "/api/ratings/{personId}/{entryId}/restore", "restoreRatingForm", "Restore a deleted rating", tagHTMX).
+		scope(model.ScopeRatingsWrite).
+		path("personId", "Person whose rating is restored", uuidSchema()).
+		path("entryId", "Entry ID", uuidSchema()).
+		form(restoreForm...).
+		respond(http.StatusOK, htmxResponse("Updated trash list, or a page refresh when undoing", true)).
+		respond(http.StatusForbidden, textResponse("Not the signed-in person")).
+		respond(http.StatusNotFound, textResponse("Rating not in the trash"))
+
+	// Persons
+	personForm := []formField{
+		field("initial", stringSchema(), false, "Single character, upper-cased"),
+		field("name", stringSchema(), false, "Display name"),
+	}
+	b.route(http.MethodGet, "/api/persons", "listAllPersons", "List every person, including archived", tagHTMX).
+		scope(model.ScopePersonsRead).
+		respond(http.StatusOK, jsonResponse("Persons", b.schemas.listOf(model.Person{})))
+	b.route(http.MethodPost, "/api/persons", "createPersonForm", "Add a person", tagHTMX).
+		scope(model.ScopePersonsWrite).
+		form(personForm...).
+		json(b.schemas.of(model.CreatePersonInput{}), true).
+		respond(http.StatusOK, htmxResponse("Updated person list", true)).
+		respond(http.StatusCreated, jsonResponse("Created person, for JSON requests", b.schemas.of(model.Person{}))).
+		respond(http.StatusConflict, textResponse("Initial is already in use"))
+	b.route(http.MethodPost, "/api/persons/reorder", "reorderPersonsForm", "Reorder persons", tagHTMX).
+		scope(model.ScopePersonsWrite).
+		form(field("person_ids", arrayOf(uuidSchema()), true, "Repeat for each person, in order")).
+		json(b.schemas.of(handler.ReorderPersonsRequest{}), true).
+		respond(http.StatusOK, htmxResponse("Updated person list", true)).
+		respond(http.StatusNoContent, emptyResponse("Order saved, for JSON requests")).
+		respond(http.StatusConflict, textResponse("The list doesn't name every person exactly once"))
+	b.route(http.MethodPut, "/api/persons/{id}", "updatePersonForm", "Rename a person", tagHTMX).
+		scope(model.ScopePersonsWrite).
+		path("id", "Person ID", uuidSchema()).
+		form(personForm...).
+		json(b.schemas.of(model.UpdatePersonInput{}), true).
+		respond(http.StatusOK, htmxResponse("Updated person list, or the person as JSON", true)).
+		respond(http.StatusConflict, textResponse("Initial is already in use")).
+		respond(http.StatusNotFound, textResponse("Person not found"))
+	b.route(http.MethodPost, "/api/persons/{id}/archive", "archivePersonForm", "Archive a person", tagHTMX).
+		scope(model.ScopePersonsWrite).
+		path("id", "Person ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated person list, or the person as JSON", true)).
+		respond(http.StatusNotFound, textResponse("Person not found"))
+	b.route(http.MethodDelete, "/api/persons/{id}/archive", "unarchivePersonForm", "Restore an archived person", tagHTMX).
+		scope(model.ScopePersonsWrite).
+		path("id", "Person ID", uuidSchema()).
+		respond(http.StatusOK, htmxResponse("Updated person list, or the person as JSON", true)).
+		respond(http.StatusNotFound, textResponse("Person not found"))
+}
+
+// libraryFilters adds the library browse filter, sort and paging parameters
+func libraryFilters(o operation) operation {
+	sorts := make([]string, 0, len(model.LibrarySorts))
+	for _, sort := range model.LibrarySorts {
+		sorts = append(sorts, string(sort))
+	}
+	return o.
+		query("genre", "Exact genre name", stringSchema(), false).
+		query("decade", "First year of a decade, e.g. 1990", integerSchema(), false).
+		query("min_runtime", "Minimum runtime in minutes", integerSchema(), false).
+		query("max_runtime", "Maximum runtime in minutes", integerSchema(), false).
+		query("watched", "Only watched or unwatched entries", booleanSchema(), false).
+		query("picker", "Only entries this person picked", uuidSchema(), false).
+		query("min_score", "Minimum average rating", numberRange(1, 10), false).
+		query("max_score", "Maximum average rating", numberRange(1, 10), false).
+		query("sort", "Defaults to added", enumSchema(sorts...), false).
+		query("order", "Defaults to asc for title and picker, desc otherwise", enumSchema("asc", "desc"), false).
+		query("after", "next_cursor from the previous page", stringSchema(), false).
+		query("limit", "Page size, at most 100", integerSchema(), false)
+}
+
+// historyFilters adds the entry history filter parameters
+func historyFilters(o operation) operation {
+	return o.
+		query("type", "Only entry, rating or viewing events", enumSchema(model.AuditEntityEntry, model.AuditEntityRating, model.AuditEntityViewing), false).
+		query("action", "Only this kind of change", enumSchema(model.AuditActions...), false).
+		query("actor", "Only changes made by this signed-in person", uuidSchema(), false)
+}
+
+// searchFilters adds the library search filter and paging parameters
+func searchFilters(o operation) operation {
+	return o.
+		query("watched", "Only watched or unwatched entries", booleanSchema(), false).
+		query("group", "Only this group", integerSchema(), false).
+		query("rated_by", "Only entries this person rated", uuidSchema(), false).
+		query("limit", "Page size, default 50 and at most 100", integerSchema(), false).
+		query("offset", "Results to skip, for the pages after the first", integerSchema(), false)
+}
+
+func addAPIRoutes(b *builder) {
+	errorBody := b.schemas.of(apijson.ErrorResponse{})
+	apiError := func(description string) Response { return jsonResponse(description, errorBody) }
+	entry := b.schemas.of(model.Entry{})
+
+	// Movies
+	b.route(http.MethodGet, "/api/v1/movies", "listMovies", "List library movies", tagMovies).
+		scope(model.ScopeMoviesRead).
+		respond(http.StatusOK, jsonResponse("Movies ordered by title", b.schemas.listOf(model.Movie{})))
+	b.route(http.MethodGet, "/api/v1/movies/search", "searchMovies", "Search TMDB", tagMovies).
+		scope(model.ScopeMoviesRead).
+		query("q", "Search text", stringSchema(), true).
+		respond(http.StatusOK, jsonResponse("TMDB results", b.schemas.listOf(tmdb.SearchResult{}))).
+		respond(http.StatusBadRequest, apiError("q is missing"))
+	b.route(http.MethodGet, "/api/v1/movies/{id}", "getMovie", "Get a library movie", tagMovies).
+		scope(model.ScopeMoviesRead).
+		path("id", "Movie ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Movie", b.schemas.of(model.Movie{}))).
+		respond(http.StatusNotFound, apiError("Movie not found"))
+	b.route(http.MethodPost, "/api/v1/movies", "importMovie", "Import a movie from TMDB", tagMovies).
+		scope(model.ScopeMoviesWrite).
+		json(b.schemas.of(handler.ImportMovieRequest{}), true).
+		respond(http.StatusCreated, jsonResponse("Movie imported", b.schemas.of(model.Movie{}))).
+		respond(http.StatusOK, jsonResponse("Movie was already in the library", b.schemas.of(model.Movie{}))).
+		respond(http.StatusNotFound, apiError("No TMDB movie with that ID"))
+	b.route(http.MethodDelete, "/api/v1/movies/{id}", "deleteMovie", "Delete a movie", tagMovies).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Movie ID", uuidSchema()).
+		describe("Moves the movie and every entry of it to the trash, where it can be restored until it is purged.").
+		respond(http.StatusNoContent, emptyResponse("Movie moved to the trash")).
+		respond(http.StatusNotFound, apiError("Movie not found"))
+	b.route(http.MethodPost, "/api/v1/movies/{id}/restore", "restoreMovie", "Restore a deleted movie", tagMovies).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Movie ID", uuidSchema()).
+		describe("Also restores the entries and ratings that were deleted along with the movie.").
+		respond(http.StatusOK, jsonResponse("Restored movie", b.schemas.of(model.Movie{}))).
+		respond(http.StatusNotFound, apiError("Movie not in the trash")).
+		respond(http.StatusConflict, apiError("Movie is already in one of its groups again"))
+
+	// Watchlist
+	suggestion := b.schemas.of(model.Suggestion{})
+	b.route(http.MethodGet, "/api/v1/watchlist", "listWatchlist", "List the watchlist", tagWatchlist).
+		scope(model.ScopeMoviesRead).
+		describe("Suggestions not yet added to a group, most upvoted first.").
+		respond(http.StatusOK, jsonResponse("Suggestions", arrayOf(suggestion)))
+	b.route(http.MethodPost, "/api/v1/watchlist", "suggestMovie", "Suggest a movie", tagWatchlist).
+		scope(model.ScopeMoviesWrite).
+		json(b.schemas.of(handler.SuggestMovieRequest{}), true).
+		respond(http.StatusCreated, jsonResponse("New suggestion", suggestion)).
+		respond(http.StatusBadRequest, apiError("Invalid body, unknown movie or unknown person")).
+		respond(http.StatusConflict, apiError("Movie is already on the watchlist"))
+	b.route(http.MethodDelete, "/api/v1/watchlist/{id}", "withdrawSuggestion", "Withdraw a suggestion", tagWatchlist).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		respond(http.StatusNoContent, emptyResponse("Suggestion withdrawn")).
+		respond(http.StatusNotFound, apiError("Suggestion not found"))
+	b.route(http.MethodPut, "/api/v1/watchlist/{id}/votes/{personId}", "voteForSuggestion", "Upvote a suggestion", tagWatchlist).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		path("personId", "Person ID", uuidSchema()).
+		describe("Voting twice is a no-op. Suggesters can't upvote their own suggestion.").
+		respond(http.StatusOK, jsonResponse("Updated suggestion", suggestion)).
+		respond(http.StatusBadRequest, apiError("Own suggestion or archived person")).
+		respond(http.StatusForbidden, apiError("Signed-in persons can only vote for themselves")).
+		respond(http.StatusNotFound, apiError("Suggestion or person not found"))
+	b.route(http.MethodDelete, "/api/v1/watchlist/{id}/votes/{personId}", "unvoteSuggestion", "Withdraw an upvote", tagWatchlist).
+		scope(model.ScopeMoviesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		path("personId", "Person ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Updated suggestion", suggestion)).
+		respond(http.StatusForbidden, apiError("Signed-in persons can only vote for themselves")).
+		respond(http.StatusNotFound, apiError("Suggestion or person not found"))
+	b.route(http.MethodPost, "/api/v1/watchlist/{id}/promote", "promoteSuggestion", "Add a suggestion to a group", tagWatchlist).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Suggestion ID", uuidSchema()).
+		describe("Creates the entry and takes the suggestion off the watchlist.").
+		json(b.schemas.of(handler.PromoteSuggestionRequest{}), false).
+		respond(http.StatusCreated, jsonResponse("New entry", b.schemas.of(model.Entry{}))).
+		respond(http.StatusBadRequest, apiError("Invalid body, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Suggestion not found")).
+		respond(http.StatusConflict, apiError("Movie is already in that group"))
+
+	// Entries
+	b.route(http.MethodPost, "/api/v1/entries", "createEntry", "Add a movie to a group", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		json(b.schemas.of(handler.CreateEntryRequest{}), true).
+		respond(http.StatusCreated, jsonResponse("Entry created", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid body, unknown movie or person, or a group that neither exists nor is the next new one")).
+		respond(http.StatusConflict, apiError("Movie is already in the group"))
+	b.route(http.MethodGet, "/api/v1/entries/{id}", "getEntry", "Get an entry", tagEntries).
+		scope(model.ScopeEntriesRead).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Entry with movie and ratings", entry)).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	searchFilters(b.route(http.MethodGet, "/api/v1/search", "searchEntries", "Full-text search of the library", tagEntries)).
+		scope(model.ScopeEntriesRead).
+		describe("Matches titles, genres, synopses and entry notes using web search syntax (quoted phrases, -exclusions, or). Returns 50 entries unless limit says otherwise; page with offset.").
+		query("q", "Search text", stringSchema(), true).
+		respond(http.StatusOK, jsonResponse("Matching entries, best match first", arrayOf(entry))).
+		respond(http.StatusBadRequest, apiError("q is missing, or a filter, limit or offset is invalid"))
+	libraryFilters(b.route(http.MethodGet, "/api/v1/library", "browseLibrary", "Browse the library", tagEntries)).
+		scope(model.ScopeEntriesRead).
+		describe("Keyset pagination: pass next_cursor back as after, with the same sort and order, until it is absent.").
+		respond(http.StatusOK, jsonResponse("A page of entries", b.schemas.of(model.LibraryPage{}))).
+		respond(http.StatusBadRequest, apiError("Invalid filter or cursor"))
+	b.route(http.MethodGet, "/api/v1/library/facets", "getLibraryFacets", "List library genres and decades", tagEntries).
+		scope(model.ScopeEntriesRead).
+		respond(http.StatusOK, jsonResponse("Values for the genre and decade filters", b.schemas.of(model.LibraryFacets{})))
+	b.route(http.MethodPatch, "/api/v1/entries/{id}", "updateEntry", "Update an entry", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		json(b.schemas.of(handler.UpdateEntryRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Updated entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid body, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Entry not found")).
+		respond(http.StatusConflict, apiError("Movie is already in the target group"))
+	b.route(http.MethodPost, "/api/v1/entries/{id}/move", "moveEntry", "Move an entry", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Inserts the entry at position in the target group, or appends it, and closes the gap it leaves behind. Moving within the same group changes only its position.").
+		json(b.schemas.of(handler.MoveEntryRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Moved entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid position, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Entry not found")).
+		respond(http.StatusConflict, apiError("Movie is already in the target group, or the entry was moved by another request"))
+	b.route(http.MethodPost, "/api/v1/entries/{id}/copy", "copyEntry", "Copy an entry to another group", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("The copy keeps the notes and picker but starts unwatched and unrated.").
+		json(b.schemas.of(handler.MoveEntryRequest{}), true).
+		respond(http.StatusCreated, jsonResponse("New entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid position, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Entry not found")).
+		respond(http.StatusConflict, apiError("Movie is already in the target group"))
+	b.route(http.MethodDelete, "/api/v1/entries/{id}", "deleteEntry", "Delete an entry", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Moves the entry and its ratings to the trash, where they can be restored until they are purged.").
+		respond(http.StatusNoContent, emptyResponse("Entry moved to the trash")).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	b.route(http.MethodPost, "/api/v1/entries/{id}/restore", "restoreEntry", "Restore a deleted entry", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Also restores the ratings deleted along with the entry. It keeps its old position if that is still free and goes last otherwise.").
+		respond(http.StatusOK, jsonResponse("Restored entry", entry)).
+		respond(http.StatusNotFound, apiError("Entry not in the trash, or its movie still is")).
+		respond(http.StatusConflict, apiError("Movie is already in that group again"))
+	b.route(http.MethodGet, "/api/v1/trash", "listTrash", "List deleted items", tagEntries).
+		scope(model.ScopeEntriesRead).
+		describe("Movies, entries and ratings that can still be restored, most recently deleted first. purge_at is omitted when the server keeps the trash forever. "+
+			"entries:read covers the whole list; restoring needs the write scope of the item's type.").
+		respond(http.StatusOK, jsonResponse("Trash items", b.schemas.listOf(model.TrashItem{})))
+	historyFilters(b.route(http.MethodGet, "/api/v1/entries/{id}/history", "getEntryHistory", "Get an entry's change history", tagEntries)).
+		scope(model.ScopeEntriesRead).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Audit events for the entry, its ratings and its viewings, newest first, at most 200. before and after are the row as JSON, null when it didn't exist. History outlives the entry, so an unknown ID returns an empty list.").
+		respond(http.StatusOK, jsonResponse("Audit events", b.schemas.listOf(model.AuditEvent{}))).
+		respond(http.StatusBadRequest, apiError("Invalid filter"))
+	b.route(http.MethodPut, "/api/v1/entries/{id}/watched", "setWatched", "Mark an entry watched", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Records a viewing with everyone active present, unless the entry already has a viewing on that date. watched_at on the entry is the date of its first viewing.").
+		json(b.schemas.of(handler.SetWatchedRequest{}), false).
+		respond(http.StatusOK, jsonResponse("Updated entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid date")).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	b.route(http.MethodDelete, "/api/v1/entries/{id}/watched", "clearWatched", "Remove an entry's latest viewing", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Removes the most recent viewing, keeping earlier ones. The entry is unwatched once none are left.").
+		respond(http.StatusOK, jsonResponse("Updated entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid body, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	viewing := b.schemas.of(model.Viewing{})
+	b.route(http.MethodGet, "/api/v1/entries/{id}/viewings", "listViewings", "List an entry's viewings", tagEntries).
+		scope(model.ScopeEntriesRead).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Viewings, oldest first", arrayOf(viewing))).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	b.route(http.MethodPost, "/api/v1/entries/{id}/viewings", "createViewing", "Record a viewing", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		describe("Unknown attendee IDs are ignored.").
+		json(b.schemas.of(handler.CreateViewingRequest{}), false).
+		respond(http.StatusCreated, jsonResponse("New viewing", viewing)).
+		respond(http.StatusBadRequest, apiError("Invalid date")).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	b.route(http.MethodDelete, "/api/v1/entries/{id}/viewings/{viewingId}", "deleteViewing", "Remove a viewing", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("viewingId", "Viewing ID", uuidSchema()).
+		describe("Ratings given after the viewing are kept but no longer attached to it.").
+		respond(http.StatusNoContent, emptyResponse("Viewing removed")).
+		respond(http.StatusNotFound, apiError("Viewing not found"))
+	b.route(http.MethodPut, "/api/v1/entries/{id}/attendance/{personId}", "setAttendance", "Mark a person present or absent", tagEntries).
+		scope(model.ScopeEntriesWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("personId", "Person ID", uuidSchema()).
+		describe("Present adds the person to the latest viewing unless they attended one already; absent removes them from every viewing. The entry's attendee_ids decide whose ratings count towards its average.").
+		json(b.schemas.of(handler.SetAttendanceRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Updated entry", entry)).
+		respond(http.StatusBadRequest, apiError("Invalid body, or a group that neither exists nor is the next new one")).
+		respond(http.StatusNotFound, apiError("Entry or person not found")).
+		respond(http.StatusConflict, apiError("Entry has no viewings yet"))
+
+	// Groups
+	group := b.schemas.of(model.Group{})
+	b.route(http.MethodGet, "/api/v1/groups", "listGroups", "List groups", tagGroups).
+		scope(model.ScopeEntriesRead).
+		query("include_archived", "Include archived groups", booleanSchema(), false).
+		respond(http.StatusOK, jsonResponse("Groups with entry counts, lowest number first", arrayOf(group)))
+	b.route(http.MethodPost, "/api/v1/groups", "createGroup", "Create a group", tagGroups).
+		scope(model.ScopeEntriesWrite).
+		json(b.schemas.of(handler.CreateGroupRequest{}), true).
+		respond(http.StatusCreated, jsonResponse("Group created", group)).
+		respond(http.StatusConflict, apiError("A group with that number already exists"))
+	b.route(http.MethodGet, "/api/v1/groups/{num}", "getGroup", "Get a group", tagGroups).
+		scope(model.ScopeEntriesRead).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, jsonResponse("Group", group)).
+		respond(http.StatusNotFound, apiError("Group not found"))
+	b.route(http.MethodPatch, "/api/v1/groups/{num}", "updateGroup", "Update a group", tagGroups).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		describe("Making a group current also restores it if it was archived; archiving the current group leaves no explicit current group.").
+		json(b.schemas.of(handler.UpdateGroupRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Updated group", group)).
+		respond(http.StatusNotFound, apiError("Group not found"))
+	b.route(http.MethodGet, "/api/v1/groups/{num}/entries", "listGroupEntries", "List a group's entries", tagGroups).
+		scope(model.ScopeEntriesRead).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, jsonResponse("Entries in display order", arrayOf(entry)))
+	b.route(http.MethodGet, "/api/v1/groups/{num}/picks", "listGroupPicks", "List who picked each entry in a group", tagGroups).
+		scope(model.ScopeEntriesRead).
+		path("num", "Group number", integerSchema()).
+		respond(http.StatusOK, jsonResponse("Picks, oldest first; entries without a picker are left out", b.schemas.listOf(model.Pick{})))
+	b.route(http.MethodGet, "/api/v1/groups/{num}/next-picker", "getNextPicker", "Suggest whose turn it is to pick", tagGroups).
+		scope(model.ScopeEntriesRead).
+		path("num", "Group number", integerSchema()).
+		describe("Uses the server's PICKER_ROTATION: round_robin follows display order after the last picker, weighted favours whoever has picked least in the group.").
+		respond(http.StatusOK, jsonResponse("Suggested picker with per-person pick counts", b.schemas.of(model.PickerTurn{})))
+	b.route(http.MethodPut, "/api/v1/groups/{num}/order", "reorderGroup", "Set a group's display order", tagGroups).
+		scope(model.ScopeEntriesWrite).
+		path("num", "Group number", integerSchema()).
+		json(b.schemas.of(handler.ReorderGroupRequest{}), true).
+		respond(http.StatusNoContent, emptyResponse("Order saved")).
+		respond(http.StatusBadRequest, apiError("entry_ids does not match the group"))
+
+	// Ratings
+	b.route(http.MethodGet, "/api/v1/entries/{id}/ratings", "listRatings", "List an entry's ratings", tagRatings).
+		scope(model.ScopeRatingsRead).
+		path("id", "Entry ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Ratings in person order", b.schemas.listOf(model.Rating{}))).
+		respond(http.StatusNotFound, apiError("Entry not found"))
+	b.route(http.MethodPut, "/api/v1/entries/{id}/ratings/{personId}", "setRating", "Rate an entry", tagRatings).
+		scope(model.ScopeRatingsWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("personId", "Person rating; browsers may only rate as themselves", uuidSchema()).
+		json(b.schemas.of(handler.SetRatingRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Saved rating", b.schemas.of(model.Rating{}))).
+		respond(http.StatusForbidden, apiError("Not the signed-in person, or token lacks scope")).
+		respond(http.StatusNotFound, apiError("Entry or person not found"))
+	b.route(http.MethodDelete, "/api/v1/entries/{id}/ratings/{personId}", "deleteRating", "Remove a rating", tagRatings).
+		scope(model.ScopeRatingsWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("personId", "Person whose rating is removed", uuidSchema()).
+		respond(http.StatusNoContent, emptyResponse("Rating moved to the trash")).
+		respond(http.StatusForbidden, apiError("Not the signed-in person, or token lacks scope")).
+		respond(http.StatusNotFound, apiError("Entry or rating not found"))
+	b.route(http.MethodPost, "/api/v1/entries/{id}/ratings/{personId}/restore", "restoreRating", "Restore a deleted rating", tagRatings).
+		scope(model.ScopeRatingsWrite).
+		path("id", "Entry ID", uuidSchema()).
+		path("personId", "Person whose rating is restored", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Restored rating", b.schemas.of(model.Rating{}))).
+		respond(http.StatusForbidden, apiError("Not the signed-in person, or token lacks scope")).
+		respond(http.StatusNotFound, apiError("Rating not in the trash"))
+
+	// Stats
+	b.route(http.MethodGet, "/api/v1/stats", "getStats", "Watch-history statistics", tagStats).
+		scope(model.ScopeRatingsRead).
+		describe("Time watched and the per-month and per-year counts come from viewings, so rewatches count when they happened.").
+		respond(http.StatusOK, jsonResponse("Watch counts, time watched and rating statistics", b.schemas.of(model.Stats{})))
+	b.route(http.MethodGet, "/api/v1/stats/taste", "getTasteComparison", "Rating agreement between family members", tagStats).
+		scope(model.ScopeRatingsRead).
+		respond(http.StatusOK, jsonResponse("Pairwise agreement and personal bias", b.schemas.of(model.TasteComparison{})))
+
+	// Persons
+	b.route(http.MethodGet, "/api/v1/persons", "listPersons", "List persons", tagPersons).
+		scope(model.ScopePersonsRead).
+		query("include_archived", "Include archived persons", booleanSchema(), false).
+		respond(http.StatusOK, jsonResponse("Persons in display order", b.schemas.listOf(model.Person{})))
+	b.route(http.MethodPost, "/api/v1/persons", "createPerson", "Add a person", tagPersons).
+		scope(model.ScopePersonsWrite).
+		json(b.schemas.of(model.CreatePersonInput{}), true).
+		respond(http.StatusCreated, jsonResponse("Person created", b.schemas.of(model.Person{}))).
+		respond(http.StatusConflict, apiError("Initial is already in use"))
+	b.route(http.MethodGet, "/api/v1/persons/{id}", "getPerson", "Get a person", tagPersons).
+		scope(model.ScopePersonsRead).
+		path("id", "Person ID", uuidSchema()).
+		respond(http.StatusOK, jsonResponse("Person", b.schemas.of(model.Person{}))).
+		respond(http.StatusNotFound, apiError("Person not found"))
+	b.route(http.MethodPatch, "/api/v1/persons/{id}", "updatePerson", "Update a person", tagPersons).
+		scope(model.ScopePersonsWrite).
+		path("id", "Person ID", uuidSchema()).
+		json(b.schemas.of(handler.UpdatePersonRequest{}), true).
+		respond(http.StatusOK, jsonResponse("Updated person", b.schemas.of(model.Person{}))).
+		respond(http.StatusNotFound, apiError("Person not found")).
+		respond(http.StatusConflict, apiError("Initial is already in use"))
+
+	// Every JSON API operation can fail validation or content negotiation
+	for path, item := range b.doc.Paths {
+		if !strings.HasPrefix(path, "/api/v1/") {
+			continue
+		}
+		for _, op := range item {
+			for status, description := range map[int]string{
+				http.StatusBadRequest:           "Invalid request",
+				http.StatusNotAcceptable:        "Accept does not allow application/json",
+				http.StatusUnsupportedMediaType: "Body is not application/json",
+			} {
+				if _, ok := op.Responses[statusKey(status)]; !ok {
+					op.Responses[statusKey(status)] = apiError(description)
+				}
+			}
+		}
+	}
+}
+
+// Operations returns every documented method and path, sorted
+func (d *Document) Operations() []string {
+	var ops []string
+	for path, item := range d.Paths {
+		for method := range item {
+			ops = append(ops, routeKey(method, path))
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APITokenRepository handles database operations for scoped API tokens
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPITokenRepository creates a new APITokenRepository
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+// Create inserts a new API token
+func (r *APITokenRepository) Create(ctx context.Context, input model.CreateAPITokenInput) (*model.APIToken, error) {
+	query := `
+		INSERT INTO api_tokens (name, token_hash, scopes, created_by_person_id, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, name, scopes, created_by_person_id, created_at, expires_at, last_used_at`
+
+	token := &model.APIToken{}
+	err := r.pool.QueryRow(ctx, query,
+		input.Name,
+		input.TokenHash,
+		input.Scopes,
+		input.CreatedByPersonID,
+		input.ExpiresAt,
+	).Scan(
+		&token.ID,
+		&token.Name,
+		&token.Scopes,
+		&token.CreatedByPersonID,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create api token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetByTokenHash retrieves an unexpired token, or nil if none matches
+func (r *APITokenRepository) GetByTokenHash(ctx context.Context, tokenHash []byte) (*model.APIToken, error) {
+	query := `
+		SELECT id, name, scopes, created_by_person_id, created_at, expires_at, last_used_at
+		FROM api_tokens
+		WHERE token_hash = $1
+		  AND (expires_at IS NULL OR expires_at > NOW())`
+
+	token := &model.APIToken{}
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.Name,
+		&token.Scopes,
+		&token.CreatedByPersonID,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get api token by hash: %w", err)
+	}
+
+	return token, nil
+}
+
+// List retrieves all tokens, newest first, with the creating person
+func (r *APITokenRepository) List(ctx context.Context) ([]*model.APIToken, error) {
+	query := `
+		SELECT t.id, t.name, t.scopes, t.created_by_person_id, t.created_at, t.expires_at, t.last_used_at,
+		       p.id, p.initial, p.name
+		FROM api_tokens t
+		LEFT JOIN persons p ON t.created_by_person_id = p.id
+		ORDER BY t.created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*model.APIToken
+	for rows.Next() {
+		token := &model.APIToken{}
+		var personID *uuid.UUID
+		var personInitial, personName *string
+		if err := rows.Scan(
+			&token.ID,
+			&token.Name,
+			&token.Scopes,
+			&token.CreatedByPersonID,
+			&token.CreatedAt,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+			&personID,
+			&personInitial,
+			&personName,
+		); err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		if personID != nil && personInitial != nil && personName != nil {
+			token.CreatedByPerson = &model.Person{ID: *personID, Initial: *personInitial, Name: *personName}
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate api tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Touch records that the token was just used, at most once a minute
+func (r *APITokenRepository) Touch(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+	_, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("touch api token: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes a token, returning false if it was not found
+func (r *APITokenRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `DELETE FROM api_tokens WHERE id = $1`
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("delete api token: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
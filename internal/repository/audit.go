@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/drywaters/seenema/internal/auth"
+	"github.com/drywaters/seenema/internal/model"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepository reads the audit log. Events are written by the other
+// repositories, in the same transaction as the change they record.
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// auditHistoryLimit caps how many events an entry's history returns
+const auditHistoryLimit = 200
+
+// ListForEntry returns the events for an entry, its ratings and its viewings, newest first
+func (r *AuditRepository) ListForEntry(ctx context.Context, entryID uuid.UUID, filter model.AuditFilter) ([]*model.AuditEvent, error) {
+	query := `
+		SELECT a.id, a.occurred_at, a.action, a.entity_type, a.entity_id, a.entry_id,
+		       a.actor_kind, a.actor_name, a.actor_person_id, a.actor_token_id, a.request_id, a.before, a.after,
+		       p.id, p.initial, p.name
+		FROM audit_events a
+		LEFT JOIN persons p
+		       ON a.entity_type = 'rating' AND p.id = (COALESCE(a.after, a.before)->>'person_id')::uuid
+		WHERE a.entry_id = $1
+		  AND (NULLIF($2, '') IS NULL OR a.entity_type = $2)
+		  AND (NULLIF($3, '') IS NULL OR a.action = $3)
+		  AND ($4::uuid IS NULL OR a.actor_person_id = $4)
+		ORDER BY a.occurred_at DESC, a.id DESC
+		LIMIT $5`
+
+	rows, err := r.pool.Query(ctx, query, entryID, filter.EntityType, filter.Action, filter.ActorPersonID, auditHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("list entry history: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*model.AuditEvent{}
+	for rows.Next() {
+		event := &model.AuditEvent{}
+		var ratedPersonID *uuid.UUID
+		var ratedInitial, ratedName *string
+		if err := rows.Scan(
+			&event.ID,
+			&event.OccurredAt,
+			&event.Action,
+			&event.EntityType,
+			&event.EntityID,
+			&event.EntryID,
+			&event.ActorKind,
+			&event.ActorName,
+			&event.ActorPersonID,
+			&event.ActorTokenID,
+			&event.RequestID,
+			&event.Before,
+			&event.After,
+			&ratedPersonID,
+			&ratedInitial,
+			&ratedName,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit event: %w", err)
+		}
+		if ratedPersonID != nil && ratedInitial != nil && ratedName != nil {
+			event.RatedPerson = &model.Person{ID: *ratedPersonID, Initial: *ratedInitial, Name: *ratedName}
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// auditSnapshots select an audited table's rows as (id, JSON) with the table
+// aliased as t, leaving out search vectors, TMDB metadata and password hashes.
+// Viewings include their attendees.
+var auditSnapshots = map[string]string{
+	model.AuditEntityMovie:  `SELECT t.id, to_jsonb(t) - 'search_vector' - 'metadata_json' FROM movies t`,
+	model.AuditEntityEntry:  `SELECT t.id, to_jsonb(t) - 'notes_vector' FROM entries t`,
+	model.AuditEntityRating: `SELECT t.id, to_jsonb(t) FROM ratings t`,
+	model.AuditEntityPerson: `SELECT t.id, to_jsonb(t) - 'password_hash' FROM persons t`,
+	model.AuditEntityViewing: `SELECT t.id, to_jsonb(t) || jsonb_build_object('attendee_ids',
+		(SELECT COALESCE(jsonb_agg(va.person_id ORDER BY va.person_id), '[]') FROM viewing_attendees va WHERE va.viewing_id = t.id)
+	) FROM viewings t`,
+}
+
+// auditScope is a set of rows whose changes are recorded under one action.
+// An empty action is inferred as create, update or delete per row.
+type auditScope struct {
+	action     string
+	entityType string
+	where      string // Condition on t, using args
+	args       []any
+}
+
+// audited runs fn within tx and records an event for every row in the scopes
+// that fn creates, changes or removes. Rows are locked before fn runs so the
+// before snapshots can't go stale.
+func audited(ctx context.Context, tx pgx.Tx, fn func() error, scopes ...auditScope) error {
+	before := make([]map[uuid.UUID][]byte, len(scopes))
+	for i, scope := range scopes {
+		snapshots, err := auditSnapshot(ctx, tx, scope, true)
+		if err != nil {
+			return err
+		}
+		before[i] = snapshots
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	for i, scope := range scopes {
+		after, err := auditSnapshot(ctx, tx, scope, false)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]uuid.UUID, 0, len(before[i])+len(after))
+		for id := range before[i] {
+			ids = append(ids, id)
+		}
+		for id := range after {
+			if _, ok := before[i][id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		slices.SortFunc(ids, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+
+		for _, id := range ids {
+			if bytes.Equal(before[i][id], after[id]) {
+				continue
+			}
+			if err := recordAuditEvent(ctx, tx, scope.action, scope.entityType, id, before[i][id], after[id]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// auditCreated records a row that was just inserted within tx
+func auditCreated(ctx context.Context, tx pgx.Tx, action, entityType string, id uuid.UUID) error {
+	after, err := auditSnapshot(ctx, tx, auditScope{entityType: entityType, where: "t.id = $1", args: []any{id}}, false)
+	if err != nil {
+		return err
+	}
+	return recordAuditEvent(ctx, tx, action, entityType, id, nil, after[id])
+}
+
+// auditSnapshot returns the rows in scope as JSON, keyed by ID
+func auditSnapshot(ctx context.Context, tx pgx.Tx, scope auditScope, lock bool) (map[uuid.UUID][]byte, error) {
+	query := auditSnapshots[scope.entityType] + " WHERE " + scope.where
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	rows, err := tx.Query(ctx, query, scope.args...)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s for audit: %w", scope.entityType, err)
+	}
+	defer rows.Close()
+
+	snapshots := make(map[uuid.UUID][]byte)
+	for rows.Next() {
+		var id uuid.UUID
+		var snapshot []byte
+		if err := rows.Scan(&id, &snapshot); err != nil {
+			return nil, fmt.Errorf("scan %s snapshot: %w", scope.entityType, err)
+		}
+		snapshots[id] = snapshot
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s snapshots: %w", scope.entityType, err)
+	}
+
+	return snapshots, nil
+}
+
+// recordAuditEvent inserts one audit event attributed to the request in ctx
+func recordAuditEvent(ctx context.Context, tx pgx.Tx, action, entityType string, id uuid.UUID, before, after []byte) error {
+	switch {
+	case action != "":
+	case before == nil:
+		action = model.AuditActionCreate
+	case after == nil:
+		action = model.AuditActionDelete
+	default:
+		action = model.AuditActionUpdate
+	}
+
+	// Entry, rating and viewing events are listed in the entry's history
+	var entryID *uuid.UUID
+	switch entityType {
+	case model.AuditEntityEntry:
+		entryID = &id
+	case model.AuditEntityRating, model.AuditEntityViewing:
+		snapshot := after
+		if snapshot == nil {
+			snapshot = before
+		}
+		var row struct {
+			EntryID uuid.UUID `json:"entry_id"`
+		}
+		if err := json.Unmarshal(snapshot, &row); err != nil {
+			return fmt.Errorf("read %s snapshot: %w", entityType, err)
+		}
+		entryID = &row.EntryID
+	}
+
+	actorKind, actorName, actorPersonID, actorTokenID := auditActor(ctx)
+	var requestID *string
+	if reqID := chimw.GetReqID(ctx); reqID != "" {
+		requestID = &reqID
+	}
+
+	query := `
+		INSERT INTO audit_events (action, entity_type, entity_id, entry_id, actor_kind, actor_name, actor_person_id, actor_token_id, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	if _, err := tx.Exec(ctx, query,
+		action,
+		entityType,
+		id,
+		entryID,
+		actorKind,
+		actorName,
+		actorPersonID,
+		actorTokenID,
+		requestID,
+		before,
+		after,
+	); err != nil {
+		return fmt.Errorf("record audit event: %w", err)
+	}
+	return nil
+}
+
+// auditActor describes who is making the request in ctx
+func auditActor(ctx context.Context) (kind, name string, personID, tokenID *uuid.UUID) {
+	if person := auth.PersonFromContext(ctx); person != nil {
+		return model.AuditActorPerson, person.Name, &person.ID, nil
+	}
+	if token := auth.APITokenFromContext(ctx); token != nil {
+		return model.AuditActorAPIToken, token.Name, nil, &token.ID
+	}
+	if auth.IsHouseholdToken(ctx) {
+		return model.AuditActorHouseholdToken, "Household token", nil, nil
+	}
+	return model.AuditActorSystem, "System", nil, nil
+}
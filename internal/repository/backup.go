@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// restoreBatchSize is how many rows a single restore statement writes
+const restoreBatchSize = 500
+
+// backupTable describes how one table is dumped and restored
+type backupTable struct {
+	name string
+	// key identifies a row across databases. Restores update rows with a
+	// matching key when key is the primary key, and otherwise only add rows
+	// whose key is missing.
+	key    []string
+	upsert bool
+	// omit lists columns left out of backups, e.g. serial IDs that differ
+	// between databases
+	omit []string
+	// prepare runs before the table is restored, given every backed-up row
+	// as a JSON array, to move existing rows out of the way of unique indexes
+	// or refuse rows that would collide with them
+	prepare func(ctx context.Context, tx pgx.Tx, rows []byte) error
+	// finish runs after the table is restored, to tidy up after prepare
+	finish func(ctx context.Context, tx pgx.Tx) error
+}
+
+// backupTables are the tables a backup holds, parents before children so
+// restores satisfy foreign keys. Sessions, API tokens and the audit log are
+// left out.
+var backupTables = []backupTable{
+	{name: "persons", key: []string{"id"}, upsert: true, prepare: personPositions.clear, finish: personPositions.renumber},
+	{name: "groups", key: []string{"number"}, upsert: true, prepare: clearCurrentGroup},
+	{name: "movies", key: []string{"id"}, upsert: true, prepare: checkTMDBIds},
+	{name: "entries", key: []string{"id"}, upsert: true, prepare: entryPositions.clear, finish: entryPositions.renumber},
+	{name: "viewings", key: []string{"id"}, upsert: true},
+	{name: "viewing_attendees", key: []string{"viewing_id", "person_id"}},
+	{name: "ratings", key: []string{"id"}, upsert: true},
+	{name: "rating_revisions", key: []string{"rating_id", "created_at"}, omit: []string{"id"}},
+	{name: "watchlist_suggestions", key: []string{"id"}, upsert: true},
+	{name: "watchlist_votes", key: []string{"suggestion_id", "person_id"}},
+}
+
+// BackupTableNames returns the tables a backup holds, in restore order
+func BackupTableNames() []string {
+	names := make([]string, len(backupTables))
+	for i, table := range backupTables {
+		names[i] = table.name
+	}
+	return names
+}
+
+func findBackupTable(name string) (backupTable, bool) {
+	for _, table := range backupTables {
+		if table.name == name {
+			return table, true
+		}
+	}
+	return backupTable{}, false
+}
+
+// positionIndex describes a unique index on a table's positions
+type positionIndex struct {
+	table string
+	// group is the column positions are unique within, if any
+	group string
+	// softDeleted is true if the index only covers rows that aren't deleted
+	softDeleted bool
+}
+
+var (
+	personPositions = positionIndex{table: "persons"}
+	entryPositions  = positionIndex{table: "entries", group: "group_number", softDeleted: true}
+)
+
+// covers is the condition for a row the index covers, given the row's alias
+func (p positionIndex) covers(alias string) string {
+	if p.softDeleted {
+		return alias + ".deleted_at IS NULL"
+	}
+	return "TRUE"
+}
+
+// clear moves rows out of the way of the backup's positions, so restoring a
+// different order doesn't trip the unique index. A row moves if the backup
+// puts it somewhere else or puts another row in its place, whether or not
+// the backup has it; renumber closes the gaps this leaves.
+func (p positionIndex) clear(ctx context.Context, tx pgx.Tx, rows []byte) error {
+	samePlace := "b.position = t.position"
+	if p.group != "" {
+		samePlace += " AND b." + p.group + " = t." + p.group
+	}
+	query := `
+		UPDATE ` + p.table + ` t SET position = -t.position
+		WHERE t.position > 0 AND ` + p.covers("t") + ` AND EXISTS (
+			SELECT 1 FROM jsonb_populate_recordset(NULL::` + p.table + `, $1::jsonb) b
+			WHERE (b.id = t.id AND NOT (` + samePlace + `))
+			   OR (b.id <> t.id AND ` + p.covers("b") + ` AND ` + samePlace + `)
+		)`
+	_, err := tx.Exec(ctx, query, rows)
+	return err
+}
+
+// renumber numbers the rows the index covers 1..n again, keeping restored rows
+// in the backup's order and putting rows clear moved aside after them. Rows
+// already in place are left untouched.
+func (p positionIndex) renumber(ctx context.Context, tx pgx.Tx) error {
+	// Renumbered rows pass through positions below every current one, so
+	// they can't collide with rows still waiting to move
+	var offset int
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(ABS(position)), 0) FROM `+p.table).Scan(&offset); err != nil {
+		return fmt.Errorf("renumber %s: %w", p.table, err)
+	}
+
+	window := "ORDER BY position < 0, ABS(position), id"
+	if p.group != "" {
+		window = "PARTITION BY " + p.group + " " + window
+	}
+	query := `
+		WITH ranked AS (
+			SELECT id, ROW_NUMBER() OVER (` + window + `) AS rn
+			FROM ` + p.table + ` t
+			WHERE ` + p.covers("t") + `
+		)
+		UPDATE ` + p.table + ` t SET position = -($1::int + ranked.rn)
+		FROM ranked
+		WHERE t.id = ranked.id AND t.position <> ranked.rn`
+	if _, err := tx.Exec(ctx, query, offset); err != nil {
+		return fmt.Errorf("renumber %s: %w", p.table, err)
+	}
+	query = `UPDATE ` + p.table + ` SET position = -position - $1::int WHERE position < -$1::int`
+	if _, err := tx.Exec(ctx, query, offset); err != nil {
+		return fmt.Errorf("renumber %s: %w", p.table, err)
+	}
+	return nil
+}
+
+// checkTMDBIds refuses movies whose TMDB ID belongs to a different movie in
+// the database, since restoring one would mean dropping the other
+func checkTMDBIds(ctx context.Context, tx pgx.Tx, rows []byte) error {
+	query := `
+		SELECT b.id, b.tmdb_id, t.id
+		FROM jsonb_populate_recordset(NULL::movies, $1::jsonb) b
+		JOIN movies t ON t.tmdb_id = b.tmdb_id AND t.id <> b.id
+		ORDER BY b.tmdb_id
+		LIMIT 1`
+	var backupID, existingID uuid.UUID
+	var tmdbID int
+	err := tx.QueryRow(ctx, query, rows).Scan(&backupID, &tmdbID, &existingID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("the backup's movie %s has TMDB ID %d, which movie %s already has in this database", backupID, tmdbID, existingID)
+}
+
+// clearCurrentGroup unsets the current group unless the backup agrees it is current
+func clearCurrentGroup(ctx context.Context, tx pgx.Tx, rows []byte) error {
+	query := `
+		UPDATE groups g SET is_current = FALSE
+		WHERE g.is_current AND NOT EXISTS (
+			SELECT 1 FROM jsonb_populate_recordset(NULL::groups, $1::jsonb) b
+			WHERE b.number = g.number AND b.is_current
+		)`
+	_, err := tx.Exec(ctx, query, rows)
+	return err
+}
+
+// BackupRepository reads and writes whole tables for backups
+type BackupRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewBackupRepository creates a new BackupRepository
+func NewBackupRepository(pool *pgxpool.Pool) *BackupRepository {
+	return &BackupRepository{pool: pool}
+}
+
+// BackupSnapshot is a consistent, read-only view of the database
+type BackupSnapshot struct {
+	tx pgx.Tx
+}
+
+// Snapshot calls fn with a view of the database that doesn't change while
+// fn reads each table
+func (r *BackupRepository) Snapshot(ctx context.Context, fn func(*BackupSnapshot) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("begin backup snapshot: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	return fn(&BackupSnapshot{tx: tx})
+}
+
+// SchemaVersion returns the latest migration applied to the database
+func (s *BackupSnapshot) SchemaVersion(ctx context.Context) (int64, error) {
+	return schemaVersion(ctx, s.tx)
+}
+
+// EachRow calls fn with every row of a backup table as a JSON object, in key
+// order. Generated and omitted columns are left out.
+func (s *BackupSnapshot) EachRow(ctx context.Context, name string, fn func(json.RawMessage) error) error {
+	table, ok := findBackupTable(name)
+	if !ok {
+		return fmt.Errorf("%s is not a backup table", name)
+	}
+
+	generated, err := generatedColumns(ctx, s.tx, table.name)
+	if err != nil {
+		return err
+	}
+
+	skipped := append([]string{}, generated...) // never nil: jsonb minus NULL is NULL
+	skipped = append(skipped, table.omit...)
+
+	query := `SELECT to_jsonb(t) - $1::text[] FROM ` + table.name + ` t ORDER BY ` + strings.Join(table.key, ", ")
+	rows, err := s.tx.Query(ctx, query, skipped)
+	if err != nil {
+		return fmt.Errorf("dump %s: %w", table.name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row json.RawMessage
+		if err := rows.Scan(&row); err != nil {
+			return fmt.Errorf("scan %s row: %w", table.name, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate %s rows: %w", table.name, err)
+	}
+	return nil
+}
+
+// BackupRestorer writes backed-up rows within a single transaction
+type BackupRestorer struct {
+	tx pgx.Tx
+}
+
+// Restore calls fn within a transaction that commits only if fn succeeds, so
+// a failed restore leaves the database as it was
+func (r *BackupRepository) Restore(ctx context.Context, fn func(*BackupRestorer) error) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// Serialize restores against each other
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(2, 0)"); err != nil {
+			return fmt.Errorf("lock restore: %w", err)
+		}
+		return fn(&BackupRestorer{tx: tx})
+	})
+}
+
+// SchemaVersion returns the latest migration applied to the database
+func (s *BackupRestorer) SchemaVersion(ctx context.Context) (int64, error) {
+	return schemaVersion(ctx, s.tx)
+}
+
+// RestoreTable writes a table's backed-up rows, returning how many rows were
+// added or changed. Rows already matching the backup are left untouched, so
+// restoring the same backup twice changes nothing the second time. Columns
+// missing from an older backup keep their defaults or current values.
+func (s *BackupRestorer) RestoreTable(ctx context.Context, name string, rows []json.RawMessage) (int64, error) {
+	table, ok := findBackupTable(name)
+	if !ok {
+		return 0, fmt.Errorf("%s is not a backup table", name)
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	columns, err := restoreColumns(ctx, s.tx, table, rows[0])
+	if err != nil {
+		return 0, err
+	}
+
+	if table.prepare != nil {
+		data, err := json.Marshal(rows)
+		if err != nil {
+			return 0, fmt.Errorf("encode %s rows: %w", table.name, err)
+		}
+		if err := table.prepare(ctx, s.tx, data); err != nil {
+			return 0, fmt.Errorf("prepare %s: %w", table.name, err)
+		}
+	}
+
+	query := restoreQuery(table, columns)
+	var changed int64
+	for start := 0; start < len(rows); start += restoreBatchSize {
+		batch := rows[start:min(start+restoreBatchSize, len(rows))]
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return 0, fmt.Errorf("encode %s rows: %w", table.name, err)
+		}
+		tag, err := s.tx.Exec(ctx, query, data)
+		if err != nil {
+			return 0, fmt.Errorf("restore %s: %w", table.name, err)
+		}
+		changed += tag.RowsAffected()
+	}
+
+	if table.finish != nil {
+		if err := table.finish(ctx, s.tx); err != nil {
+			return 0, err
+		}
+	}
+	return changed, nil
+}
+
+// restoreQuery builds the statement that writes a batch of rows, passed as a
+// JSON array in $1
+func restoreQuery(table backupTable, columns []string) string {
+	list := strings.Join(columns, ", ")
+	query := `INSERT INTO ` + table.name + ` (` + list + `)
+		SELECT ` + list + ` FROM jsonb_populate_recordset(NULL::` + table.name + `, $1::jsonb) AS b`
+
+	if !table.upsert {
+		match := make([]string, len(table.key))
+		for i, column := range table.key {
+			match[i] = "t." + column + " = b." + column
+		}
+		return query + `
+		WHERE NOT EXISTS (SELECT 1 FROM ` + table.name + ` t WHERE ` + strings.Join(match, " AND ") + `)`
+	}
+
+	var sets, current, excluded []string
+	for _, column := range columns {
+		if slices.Contains(table.key, column) {
+			continue
+		}
+		sets = append(sets, column+" = EXCLUDED."+column)
+		// Triggers bump updated_at on every update, so it can't tell
+		// whether a row already matches the backup
+		if column != "updated_at" {
+			current = append(current, table.name+"."+column)
+			excluded = append(excluded, "EXCLUDED."+column)
+		}
+	}
+	if len(current) == 0 {
+		return query + `
+		ON CONFLICT (` + strings.Join(table.key, ", ") + `) DO NOTHING`
+	}
+	return query + `
+		ON CONFLICT (` + strings.Join(table.key, ", ") + `) DO UPDATE
+		SET ` + strings.Join(sets, ", ") + `
+		WHERE (` + strings.Join(current, ", ") + `) IS DISTINCT FROM (` + strings.Join(excluded, ", ") + `)`
+}
+
+// restoreColumns returns the table's writable columns that the backup has,
+// judging by one of its rows
+func restoreColumns(ctx context.Context, tx pgx.Tx, table backupTable, row json.RawMessage) ([]string, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(row, &keys); err != nil {
+		return nil, fmt.Errorf("read %s row: %w", table.name, err)
+	}
+
+	query := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position`
+	dbColumns, err := queryStrings(ctx, tx, query, table.name)
+	if err != nil {
+		return nil, fmt.Errorf("get %s columns: %w", table.name, err)
+	}
+
+	var columns []string
+	for _, column := range dbColumns {
+		if _, ok := keys[column]; ok && !slices.Contains(table.omit, column) {
+			columns = append(columns, column)
+		}
+	}
+	for _, column := range table.key {
+		if !slices.Contains(columns, column) {
+			return nil, fmt.Errorf("backup of %s has no %s column", table.name, column)
+		}
+	}
+	return columns, nil
+}
+
+// generatedColumns returns the table's generated columns, which backups skip
+func generatedColumns(ctx context.Context, tx pgx.Tx, table string) ([]string, error) {
+	query := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND is_generated <> 'NEVER'`
+	columns, err := queryStrings(ctx, tx, query, table)
+	if err != nil {
+		return nil, fmt.Errorf("get %s generated columns: %w", table, err)
+	}
+	return columns, nil
+}
+
+// schemaVersion returns the latest migration goose applied and didn't roll
+// back, or 0 if migrations have never run
+func schemaVersion(ctx context.Context, tx pgx.Tx) (int64, error) {
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("check migrations table: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int64
+	query := `
+		SELECT COALESCE(MAX(v.version_id), 0)
+		FROM goose_db_version v
+		WHERE v.is_applied AND NOT EXISTS (
+			SELECT 1 FROM goose_db_version d
+			WHERE d.version_id = v.version_id AND NOT d.is_applied AND d.id > v.id
+		)`
+	if err := tx.QueryRow(ctx, query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+	return version, nil
+}
+
+func queryStrings(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
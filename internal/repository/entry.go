@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/drywaters/seenema/internal/model"
@@ -22,6 +23,53 @@ func NewEntryRepository(pool *pgxpool.Pool) *EntryRepository {
 	return &EntryRepository{pool: pool}
 }
 
+// entryColumns are the columns scanEntry reads, from entries e joined to
+// movies m and, for the picker, LEFT JOIN persons p
+const entryColumns = `e.id, e.movie_id, e.group_number, e.position, e.watched_at, e.added_at, e.notes, e.picked_by_person_id,
+		       m.id, m.created_at, m.updated_at, m.title, m.release_year, m.poster_url, m.synopsis, m.runtime_minutes, m.tmdb_id, m.imdb_id, m.metadata_json,
+		       p.id, p.initial, p.name`
+
+// scanEntry reads an entry and its movie and picker from a row of entryColumns.
+// Ratings and attendees are left for the caller to fetch.
+func scanEntry(row pgx.Row) (*model.Entry, error) {
+	entry := &model.Entry{}
+	movie := &model.Movie{}
+	var pickedByPersonDBID *uuid.UUID
+	var pickedByInitial *string
+	var pickedByName *string
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.MovieID,
+		&entry.GroupNumber,
+		&entry.Position,
+		&entry.WatchedAt,
+		&entry.AddedAt,
+		&entry.Notes,
+		&entry.PickedByPersonID,
+		&movie.ID,
+		&movie.CreatedAt,
+		&movie.UpdatedAt,
+		&movie.Title,
+		&movie.ReleaseYear,
+		&movie.PosterURL,
+		&movie.Synopsis,
+		&movie.RuntimeMinutes,
+		&movie.TMDBId,
+		&movie.IMDBId,
+		&movie.MetadataJSON,
+		&pickedByPersonDBID,
+		&pickedByInitial,
+		&pickedByName,
+	); err != nil {
+		return nil, err
+	}
+
+	entry.Movie = movie
+	applyPickedByPerson(entry, pickedByPersonDBID, pickedByInitial, pickedByName)
+	return entry, nil
+}
+
 func applyPickedByPerson(entry *model.Entry, pickedByPersonDBID *uuid.UUID, pickedByInitial, pickedByName *string) {
 	if pickedByPersonDBID != nil && pickedByInitial != nil && pickedByName != nil {
 		entry.PickedByPerson = &model.Person{
@@ -47,10 +95,14 @@ func (r *EntryRepository) Create(ctx context.Context, input model.CreateEntryInp
 		return nil, fmt.Errorf("create entry lock group: %w", err)
 	}
 
+	if err := ensureGroup(ctx, tx, input.GroupNumber); err != nil {
+		return nil, fmt.Errorf("create entry: %w", err)
+	}
+
 	// Insert with position = max position in group + 1 (or 1 if no entries in group)
 	query := `
 		INSERT INTO entries (movie_id, group_number, notes, picked_by_person_id, position)
-		VALUES ($1, $2, $3, $4, COALESCE((SELECT MAX(position) FROM entries WHERE group_number = $2), 0) + 1)
+		VALUES ($1, $2, $3, $4, COALESCE((SELECT MAX(position) FROM entries WHERE group_number = $2 AND deleted_at IS NULL), 0) + 1)
 		RETURNING id, movie_id, group_number, position, watched_at, added_at, notes, picked_by_person_id`
 
 	entry := &model.Entry{}
@@ -73,6 +125,10 @@ func (r *EntryRepository) Create(ctx context.Context, input model.CreateEntryInp
 		return nil, fmt.Errorf("create entry: %w", err)
 	}
 
+	if err := auditCreated(ctx, tx, model.AuditActionCreate, model.AuditEntityEntry, entry.ID); err != nil {
+		return nil, fmt.Errorf("create entry: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("create entry commit: %w", err)
 	}
@@ -80,47 +136,16 @@ func (r *EntryRepository) Create(ctx context.Context, input model.CreateEntryInp
 	return entry, nil
 }
 
-// GetByID retrieves an entry by its ID with movie and ratings
+// GetByID retrieves an entry by its ID with movie, ratings and viewings
 func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Entry, error) {
 	query := `
-		SELECT e.id, e.movie_id, e.group_number, e.position, e.watched_at, e.added_at, e.notes, e.picked_by_person_id,
-		       m.id, m.created_at, m.updated_at, m.title, m.release_year, m.poster_url, m.synopsis, m.runtime_minutes, m.tmdb_id, m.imdb_id, m.metadata_json,
-		       p.id, p.initial, p.name
+		SELECT ` + entryColumns + `
 		FROM entries e
 		JOIN movies m ON e.movie_id = m.id
 		LEFT JOIN persons p ON e.picked_by_person_id = p.id
-		WHERE e.id = $1`
+		WHERE e.id = $1 AND e.deleted_at IS NULL`
 
-	entry := &model.Entry{}
-	movie := &model.Movie{}
-	var pickedByPersonDBID *uuid.UUID
-	var pickedByInitial *string
-	var pickedByName *string
-
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&entry.ID,
-		&entry.MovieID,
-		&entry.GroupNumber,
-		&entry.Position,
-		&entry.WatchedAt,
-		&entry.AddedAt,
-		&entry.Notes,
-		&entry.PickedByPersonID,
-		&movie.ID,
-		&movie.CreatedAt,
-		&movie.UpdatedAt,
-		&movie.Title,
-		&movie.ReleaseYear,
-		&movie.PosterURL,
-		&movie.Synopsis,
-		&movie.RuntimeMinutes,
-		&movie.TMDBId,
-		&movie.IMDBId,
-		&movie.MetadataJSON,
-		&pickedByPersonDBID,
-		&pickedByInitial,
-		&pickedByName,
-	)
+	entry, err := scanEntry(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -128,9 +153,6 @@ func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Ent
 		return nil, fmt.Errorf("get entry by id: %w", err)
 	}
 
-	entry.Movie = movie
-	applyPickedByPerson(entry, pickedByPersonDBID, pickedByInitial, pickedByName)
-
 	// Fetch ratings with person info
 	ratings, err := r.getRatingsForEntry(ctx, id)
 	if err != nil {
@@ -138,6 +160,19 @@ func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Ent
 	}
 	entry.Ratings = ratings
 
+	viewings, err := listViewings(ctx, r.pool, "v.entry_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	entry.Viewings = viewings
+	for _, viewing := range viewings {
+		for _, attendee := range viewing.Attendees {
+			if !slices.Contains(entry.AttendeeIDs, attendee.ID) {
+				entry.AttendeeIDs = append(entry.AttendeeIDs, attendee.ID)
+			}
+		}
+	}
+
 	return entry, nil
 }
 
@@ -146,7 +181,7 @@ func (r *EntryRepository) GetByMovieAndGroup(ctx context.Context, movieID uuid.U
 	query := `
 		SELECT id, movie_id, group_number, position, watched_at, added_at, notes, picked_by_person_id
 		FROM entries
-		WHERE movie_id = $1 AND group_number = $2`
+		WHERE movie_id = $1 AND group_number = $2 AND deleted_at IS NULL`
 
 	entry := &model.Entry{}
 	err := r.pool.QueryRow(ctx, query, movieID, groupNumber).Scan(
@@ -169,15 +204,15 @@ func (r *EntryRepository) GetByMovieAndGroup(ctx context.Context, movieID uuid.U
 	return entry, nil
 }
 
-// getRatingsForEntry fetches all ratings for an entry with person information
+// getRatingsForEntry fetches all ratings for an entry with person information and score history
 func (r *EntryRepository) getRatingsForEntry(ctx context.Context, entryID uuid.UUID) ([]*model.Rating, error) {
 	query := `
-		SELECT r.id, r.person_id, r.entry_id, r.score, r.created_at, r.updated_at,
-		       p.id, p.initial, p.name
+		SELECT r.id, r.person_id, r.entry_id, r.score, r.viewing_id, r.created_at, r.updated_at,
+		       p.id, p.initial, p.name, rv.revision_scores, rv.revision_times
 		FROM ratings r
-		JOIN persons p ON r.person_id = p.id
-		WHERE r.entry_id = $1
-		ORDER BY p.initial`
+		JOIN persons p ON r.person_id = p.id` + ratingRevisionsJoin + `
+		WHERE r.entry_id = $1 AND r.deleted_at IS NULL
+		ORDER BY p.position`
 
 	rows, err := r.pool.Query(ctx, query, entryID)
 	if err != nil {
@@ -189,20 +224,26 @@ func (r *EntryRepository) getRatingsForEntry(ctx context.Context, entryID uuid.U
 	for rows.Next() {
 		rating := &model.Rating{}
 		person := &model.Person{}
+		var revisionScores []float64
+		var revisionTimes []time.Time
 		if err := rows.Scan(
 			&rating.ID,
 			&rating.PersonID,
 			&rating.EntryID,
 			&rating.Score,
+			&rating.ViewingID,
 			&rating.CreatedAt,
 			&rating.UpdatedAt,
 			&person.ID,
 			&person.Initial,
 			&person.Name,
+			&revisionScores,
+			&revisionTimes,
 		); err != nil {
 			return nil, fmt.Errorf("scan rating: %w", err)
 		}
 		rating.Person = person
+		rating.Revisions = ratingRevisions(revisionScores, revisionTimes)
 		ratings = append(ratings, rating)
 	}
 	if err := rows.Err(); err != nil {
@@ -220,12 +261,12 @@ func (r *EntryRepository) getRatingsForEntries(ctx context.Context, entryIDs []u
 	}
 
 	query := `
-		SELECT r.id, r.person_id, r.entry_id, r.score, r.created_at, r.updated_at,
+		SELECT r.id, r.person_id, r.entry_id, r.score, r.viewing_id, r.created_at, r.updated_at,
 		       p.id, p.initial, p.name
 		FROM ratings r
 		JOIN persons p ON r.person_id = p.id
-		WHERE r.entry_id = ANY($1)
-		ORDER BY r.entry_id, p.initial`
+		WHERE r.entry_id = ANY($1) AND r.deleted_at IS NULL
+		ORDER BY r.entry_id, p.position`
 
 	rows, err := r.pool.Query(ctx, query, entryIDs)
 	if err != nil {
@@ -241,6 +282,7 @@ func (r *EntryRepository) getRatingsForEntries(ctx context.Context, entryIDs []u
 			&rating.PersonID,
 			&rating.EntryID,
 			&rating.Score,
+			&rating.ViewingID,
 			&rating.CreatedAt,
 			&rating.UpdatedAt,
 			&person.ID,
@@ -259,19 +301,60 @@ func (r *EntryRepository) getRatingsForEntries(ctx context.Context, entryIDs []u
 	return ratingsByEntry, nil
 }
 
+// getAttendeesForEntries fetches who attended any viewing of each of several entries
+func (r *EntryRepository) getAttendeesForEntries(ctx context.Context, entryIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	attendeesByEntry := make(map[uuid.UUID][]uuid.UUID, len(entryIDs))
+	if len(entryIDs) == 0 {
+		return attendeesByEntry, nil
+	}
+
+	query := `
+		SELECT DISTINCT v.entry_id, va.person_id
+		FROM viewings v
+		JOIN viewing_attendees va ON va.viewing_id = v.id
+		WHERE v.entry_id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, query, entryIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get attendees for entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entryID, personID uuid.UUID
+		if err := rows.Scan(&entryID, &personID); err != nil {
+			return nil, fmt.Errorf("scan attendee: %w", err)
+		}
+		attendeesByEntry[entryID] = append(attendeesByEntry[entryID], personID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attendee rows: %w", err)
+	}
+
+	return attendeesByEntry, nil
+}
+
 // ListByGroup retrieves all entries for a specific group with movie and ratings
 func (r *EntryRepository) ListByGroup(ctx context.Context, groupNumber int) ([]*model.Entry, error) {
+	entriesByGroup, err := r.ListByGroups(ctx, []int{groupNumber})
+	if err != nil {
+		return nil, err
+	}
+	return entriesByGroup[groupNumber], nil
+}
+
+// ListByGroups retrieves the entries for several groups at once, keyed by group number.
+// It always runs two queries however many groups are asked for.
+func (r *EntryRepository) ListByGroups(ctx context.Context, groupNumbers []int) (map[int][]*model.Entry, error) {
 	query := `
-		SELECT e.id, e.movie_id, e.group_number, e.position, e.watched_at, e.added_at, e.notes, e.picked_by_person_id,
-		       m.id, m.created_at, m.updated_at, m.title, m.release_year, m.poster_url, m.synopsis, m.runtime_minutes, m.tmdb_id, m.imdb_id, m.metadata_json,
-		       p.id, p.initial, p.name
+		SELECT ` + entryColumns + `
 		FROM entries e
 		JOIN movies m ON e.movie_id = m.id
 		LEFT JOIN persons p ON e.picked_by_person_id = p.id
-		WHERE e.group_number = $1
-		ORDER BY e.position ASC`
+		WHERE e.group_number = ANY($1) AND e.deleted_at IS NULL
+		ORDER BY e.group_number, e.position ASC`
 
-	rows, err := r.pool.Query(ctx, query, groupNumber)
+	rows, err := r.pool.Query(ctx, query, groupNumbers)
 	if err != nil {
 		return nil, fmt.Errorf("list entries by group: %w", err)
 	}
@@ -279,40 +362,10 @@ func (r *EntryRepository) ListByGroup(ctx context.Context, groupNumber int) ([]*
 
 	var entries []*model.Entry
 	for rows.Next() {
-		entry := &model.Entry{}
-		movie := &model.Movie{}
-		var pickedByPersonDBID *uuid.UUID
-		var pickedByInitial *string
-		var pickedByName *string
-
-		if err := rows.Scan(
-			&entry.ID,
-			&entry.MovieID,
-			&entry.GroupNumber,
-			&entry.Position,
-			&entry.WatchedAt,
-			&entry.AddedAt,
-			&entry.Notes,
-			&entry.PickedByPersonID,
-			&movie.ID,
-			&movie.CreatedAt,
-			&movie.UpdatedAt,
-			&movie.Title,
-			&movie.ReleaseYear,
-			&movie.PosterURL,
-			&movie.Synopsis,
-			&movie.RuntimeMinutes,
-			&movie.TMDBId,
-			&movie.IMDBId,
-			&movie.MetadataJSON,
-			&pickedByPersonDBID,
-			&pickedByInitial,
-			&pickedByName,
-		); err != nil {
+		entry, err := scanEntry(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan entry: %w", err)
 		}
-		entry.Movie = movie
-		applyPickedByPerson(entry, pickedByPersonDBID, pickedByInitial, pickedByName)
 		entries = append(entries, entry)
 	}
 	if err := rows.Err(); err != nil {
@@ -328,97 +381,227 @@ func (r *EntryRepository) ListByGroup(ctx context.Context, groupNumber int) ([]*
 	if err != nil {
 		return nil, err
 	}
+	attendeesByEntry, err := r.getAttendeesForEntries(ctx, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entriesByGroup := make(map[int][]*model.Entry, len(groupNumbers))
+	for _, entry := range entries {
+		entry.Ratings = ratingsByEntry[entry.ID]
+		entry.AttendeeIDs = attendeesByEntry[entry.ID]
+		entriesByGroup[entry.GroupNumber] = append(entriesByGroup[entry.GroupNumber], entry)
+	}
+
+	return entriesByGroup, nil
+}
+
+// Search finds entries whose title, genres, synopsis or notes match the query,
+// best match first, returning at most search.Limit after skipping search.Offset
+func (r *EntryRepository) Search(ctx context.Context, search model.EntrySearch) ([]*model.Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		LEFT JOIN persons p ON e.picked_by_person_id = p.id,
+		     websearch_to_tsquery('english', $1) q
+		WHERE (m.search_vector @@ q OR e.notes_vector @@ q)
+		  AND e.deleted_at IS NULL
+		  AND ($2::boolean IS NULL OR (e.watched_at IS NOT NULL) = $2)
+		  AND ($3::int IS NULL OR e.group_number = $3)
+		  AND ($4::uuid IS NULL OR EXISTS (SELECT 1 FROM ratings r WHERE r.entry_id = e.id AND r.person_id = $4 AND r.deleted_at IS NULL))
+		ORDER BY ts_rank(m.search_vector || e.notes_vector, q) DESC, m.title ASC, e.group_number DESC
+		LIMIT $5 OFFSET $6`
+
+	rows, err := r.pool.Query(ctx, query, search.Query, search.Watched, search.GroupNumber, search.RatedByPersonID, search.Limit, search.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("search entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate search results: %w", err)
+	}
+
+	entryIDs := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		entryIDs = append(entryIDs, entry.ID)
+	}
+
+	ratingsByEntry, err := r.getRatingsForEntries(ctx, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+	attendeesByEntry, err := r.getAttendeesForEntries(ctx, entryIDs)
+	if err != nil {
+		return nil, err
+	}
 	for _, entry := range entries {
 		entry.Ratings = ratingsByEntry[entry.ID]
+		entry.AttendeeIDs = attendeesByEntry[entry.ID]
 	}
 
 	return entries, nil
 }
 
-// ListGroups returns all unique group numbers in ascending order
-func (r *EntryRepository) ListGroups(ctx context.Context) ([]int, error) {
-	query := `SELECT DISTINCT group_number FROM entries ORDER BY group_number`
+// ListPicks returns a group's entries with a picker, oldest first
+func (r *EntryRepository) ListPicks(ctx context.Context, groupNumber int) ([]*model.Pick, error) {
+	query := `
+		SELECT e.id, e.group_number, m.title, e.added_at,
+		       p.id, p.initial, p.name, p.position, p.archived_at, p.created_at
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		JOIN persons p ON e.picked_by_person_id = p.id
+		WHERE e.deleted_at IS NULL AND e.group_number = $1
+		ORDER BY e.added_at ASC, e.position ASC`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, groupNumber)
 	if err != nil {
-		return nil, fmt.Errorf("list groups: %w", err)
+		return nil, fmt.Errorf("list picks: %w", err)
 	}
 	defer rows.Close()
 
-	var groups []int
+	var picks []*model.Pick
 	for rows.Next() {
-		var group int
-		if err := rows.Scan(&group); err != nil {
-			return nil, fmt.Errorf("scan group: %w", err)
+		pick := &model.Pick{Person: &model.Person{}}
+		if err := rows.Scan(
+			&pick.EntryID,
+			&pick.GroupNumber,
+			&pick.MovieTitle,
+			&pick.AddedAt,
+			&pick.Person.ID,
+			&pick.Person.Initial,
+			&pick.Person.Name,
+			&pick.Person.Position,
+			&pick.Person.ArchivedAt,
+			&pick.Person.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan pick: %w", err)
 		}
-		groups = append(groups, group)
+		picks = append(picks, pick)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate groups rows: %w", err)
+		return nil, fmt.Errorf("iterate picks: %w", err)
 	}
 
-	return groups, nil
+	return picks, nil
 }
 
-// GetCurrentGroup returns the highest group number, or 1 if no entries exist
-func (r *EntryRepository) GetCurrentGroup(ctx context.Context) (int, error) {
-	query := `SELECT COALESCE(MAX(group_number), 1) FROM entries`
+// PickerStats returns, for everyone who has picked an entry, how many they
+// picked in a group and when they last picked in any group, least recent first
+func (r *EntryRepository) PickerStats(ctx context.Context, groupNumber int) ([]*model.PickerStat, error) {
+	query := `
+		SELECT p.id, p.initial, p.name, p.position, p.archived_at, p.created_at,
+		       COUNT(*) FILTER (WHERE e.group_number = $1), MAX(e.added_at)
+		FROM entries e
+		JOIN persons p ON e.picked_by_person_id = p.id
+		WHERE e.deleted_at IS NULL
+		GROUP BY p.id
+		ORDER BY MAX(e.added_at), p.position`
 
-	var group int
-	err := r.pool.QueryRow(ctx, query).Scan(&group)
+	rows, err := r.pool.Query(ctx, query, groupNumber)
 	if err != nil {
-		return 1, fmt.Errorf("get current group: %w", err)
+		return nil, fmt.Errorf("get picker stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []*model.PickerStat
+	for rows.Next() {
+		stat := &model.PickerStat{Person: &model.Person{}}
+		if err := rows.Scan(
+			&stat.Person.ID,
+			&stat.Person.Initial,
+			&stat.Person.Name,
+			&stat.Person.Position,
+			&stat.Person.ArchivedAt,
+			&stat.Person.CreatedAt,
+			&stat.GroupCount,
+			&stat.LastPicked,
+		); err != nil {
+			return nil, fmt.Errorf("scan picker stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate picker stats: %w", err)
 	}
 
-	return group, nil
+	return stats, nil
 }
 
 // Update updates an existing entry
 func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, input model.UpdateEntryInput) error {
-	query := `
-		UPDATE entries
-		SET group_number = COALESCE($2, group_number),
-		    notes = COALESCE($3, notes),
-		    picked_by_person_id = CASE
-		    	WHEN $4::uuid IS NULL THEN picked_by_person_id
-		    	WHEN $4::uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN NULL
-		    	ELSE $4::uuid
-		    END
-		WHERE id = $1`
-
-	_, err := r.pool.Exec(ctx, query, id, input.GroupNumber, input.Notes, input.PickedByPersonID)
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		return fmt.Errorf("update entry: %w", err)
+		return fmt.Errorf("update entry begin tx: %w", err)
 	}
-	return nil
-}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-// Delete removes an entry from the database
-func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM entries WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+	err = audited(ctx, tx, func() error {
+		// Changing group goes through moveEntry so positions stay unique and gap-free
+		if input.GroupNumber != nil {
+			found, err := moveEntry(ctx, tx, id, *input.GroupNumber, nil)
+			if err != nil || !found {
+				return err
+			}
+		}
+
+		query := `
+			UPDATE entries
+			SET notes = COALESCE($2, notes),
+			    picked_by_person_id = CASE
+			    	WHEN $3::uuid IS NULL THEN picked_by_person_id
+			    	WHEN $3::uuid = '00000000-0000-0000-0000-000000000000'::uuid THEN NULL
+			    	ELSE $3::uuid
+			    END
+			WHERE id = $1 AND deleted_at IS NULL`
+		_, err := tx.Exec(ctx, query, id, input.Notes, input.PickedByPersonID)
+		return err
+	}, auditScope{action: model.AuditActionUpdate, entityType: model.AuditEntityEntry, where: "t.id = $1", args: []any{id}})
 	if err != nil {
-		return fmt.Errorf("delete entry: %w", err)
+		return fmt.Errorf("update entry: %w", err)
 	}
-	return nil
-}
 
-// SetWatchedDate marks an entry as watched on the given date
-func (r *EntryRepository) SetWatchedDate(ctx context.Context, id uuid.UUID, watchedAt time.Time) error {
-	query := `UPDATE entries SET watched_at = $2 WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id, watchedAt)
-	if err != nil {
-		return fmt.Errorf("set watched date: %w", err)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("update entry commit: %w", err)
 	}
 	return nil
 }
 
-// ClearWatchedDate clears the watched date for an entry
-func (r *EntryRepository) ClearWatchedDate(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE entries SET watched_at = NULL WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+// Delete moves an entry and its ratings to the trash. They share one
+// deleted_at so restoring the entry brings back exactly those ratings.
+func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		WITH trashed AS (
+			UPDATE entries SET deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id, deleted_at
+		)
+		UPDATE ratings r
+		SET deleted_at = t.deleted_at
+		FROM trashed t
+		WHERE r.entry_id = t.id AND r.deleted_at IS NULL`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			_, err := tx.Exec(ctx, query, id)
+			return err
+		},
+			auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityEntry, where: "t.id = $1", args: []any{id}},
+			auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityRating, where: "t.entry_id = $1", args: []any{id}},
+		)
+	})
 	if err != nil {
-		return fmt.Errorf("clear watched date: %w", err)
+		return fmt.Errorf("delete entry: %w", err)
 	}
 	return nil
 }
@@ -444,39 +627,18 @@ func (r *EntryRepository) ReorderEntries(ctx context.Context, groupNumber int, e
 	}
 
 	var groupCount int
-	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM entries WHERE group_number = $1 AND id = ANY($2::uuid[])", groupNumber, entryIDs).Scan(&groupCount); err != nil {
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM entries WHERE group_number = $1 AND id = ANY($2::uuid[]) AND deleted_at IS NULL", groupNumber, entryIDs).Scan(&groupCount); err != nil {
 		return fmt.Errorf("reorder entries count group: %w", err)
 	}
 	if groupCount != len(entryIDs) {
 		return fmt.Errorf("reorder entries count mismatch: group has %d matching entries, request has %d", groupCount, len(entryIDs))
 	}
 
-	positions := make([]int, len(entryIDs))
-	for i := range entryIDs {
-		positions[i] = i + 1
-	}
-
-	// Move current positions out of the way to avoid unique constraint conflicts.
-	if _, err := tx.Exec(ctx, `
-		UPDATE entries
-		SET position = -position
-		WHERE group_number = $1 AND id = ANY($2::uuid[])`,
-		groupNumber,
-		entryIDs,
-	); err != nil {
-		return fmt.Errorf("reorder entries temp positions: %w", err)
-	}
-
-	query := `
-		UPDATE entries AS e
-		SET position = v.position
-		FROM (
-			SELECT unnest($1::uuid[]) AS id, unnest($2::int[]) AS position
-		) AS v
-		WHERE e.id = v.id AND e.group_number = $3`
-	_, err = tx.Exec(ctx, query, entryIDs, positions, groupNumber)
+	err = audited(ctx, tx, func() error {
+		return setGroupPositions(ctx, tx, groupNumber, entryIDs)
+	}, auditScope{action: model.AuditActionReorder, entityType: model.AuditEntityEntry, where: "t.id = ANY($1::uuid[])", args: []any{entryIDs}})
 	if err != nil {
-		return fmt.Errorf("update entry positions: %w", err)
+		return fmt.Errorf("reorder entries: %w", err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
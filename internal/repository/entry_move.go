@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAlreadyInGroup is returned when moving or copying an entry to a group
+// that already has its movie
+var ErrAlreadyInGroup = errors.New("movie is already in that group")
+
+// ErrEntryMoved is returned when another request moved the entry first
+var ErrEntryMoved = errors.New("entry was moved by another request")
+
+// MoveEntry moves an entry to another group, or to a new position within its
+// own group. The entry is inserted at position (1-based, clamped to the group);
+// with a nil position it is appended to a new group or left where it is in its
+// own. The group it left is renumbered so no gaps are left behind. Returns nil
+// if the entry does not exist.
+func (r *EntryRepository) MoveEntry(ctx context.Context, id uuid.UUID, groupNumber int, position *int) (*model.Entry, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("move entry begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var found bool
+	err = audited(ctx, tx, func() error {
+		found, err = moveEntry(ctx, tx, id, groupNumber, position)
+		return err
+	}, auditScope{action: model.AuditActionMove, entityType: model.AuditEntityEntry, where: "t.id = $1", args: []any{id}})
+	if err != nil || !found {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("move entry commit: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// CopyEntry adds the entry's movie to another group, keeping its notes and
+// picker but not its watched date or ratings. The copy is inserted at position
+// or appended when position is nil. Returns nil if the entry does not exist.
+func (r *EntryRepository) CopyEntry(ctx context.Context, id uuid.UUID, groupNumber int, position *int) (*model.Entry, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("copy entry begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := lockGroups(ctx, tx, groupNumber, groupNumber); err != nil {
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+	if err := ensureGroup(ctx, tx, groupNumber); err != nil {
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+	if err := checkNotInGroup(ctx, tx, id, groupNumber); err != nil {
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+
+	// Position 0 is never used, so the copy can't collide before the group is renumbered
+	var copyID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO entries (movie_id, group_number, notes, picked_by_person_id, position)
+		SELECT movie_id, $2, notes, picked_by_person_id, 0
+		FROM entries
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id`,
+		id,
+		groupNumber,
+	).Scan(&copyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+
+	if err := placeInGroup(ctx, tx, copyID, groupNumber, position); err != nil {
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+	if err := auditCreated(ctx, tx, model.AuditActionCopy, model.AuditEntityEntry, copyID); err != nil {
+		return nil, fmt.Errorf("copy entry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("copy entry commit: %w", err)
+	}
+
+	return r.GetByID(ctx, copyID)
+}
+
+// moveEntry moves an entry within tx, reporting false if it does not exist
+func moveEntry(ctx context.Context, tx pgx.Tx, id uuid.UUID, groupNumber int, position *int) (bool, error) {
+	var sourceGroup int
+	err := tx.QueryRow(ctx, `SELECT group_number FROM entries WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&sourceGroup)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("move entry get group: %w", err)
+	}
+
+	if err := lockGroups(ctx, tx, sourceGroup, groupNumber); err != nil {
+		return false, fmt.Errorf("move entry: %w", err)
+	}
+
+	// The entry may have moved between the read above and taking the locks
+	var lockedGroup int
+	err = tx.QueryRow(ctx, `SELECT group_number FROM entries WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).Scan(&lockedGroup)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("move entry get group: %w", err)
+	}
+	if lockedGroup != sourceGroup {
+		return false, fmt.Errorf("move entry to group %d: %w", lockedGroup, ErrEntryMoved)
+	}
+	if groupNumber == sourceGroup && position == nil {
+		return true, nil
+	}
+
+	if groupNumber != sourceGroup {
+		if err := ensureGroup(ctx, tx, groupNumber); err != nil {
+			return false, fmt.Errorf("move entry: %w", err)
+		}
+		if err := checkNotInGroup(ctx, tx, id, groupNumber); err != nil {
+			return false, fmt.Errorf("move entry: %w", err)
+		}
+		// Position 0 is never used, so the entry can't collide before the group is renumbered
+		if _, err := tx.Exec(ctx, `UPDATE entries SET group_number = $2, position = 0 WHERE id = $1`, id, groupNumber); err != nil {
+			return false, fmt.Errorf("move entry: %w", err)
+		}
+	}
+
+	if err := placeInGroup(ctx, tx, id, groupNumber, position); err != nil {
+		return false, fmt.Errorf("move entry: %w", err)
+	}
+
+	if groupNumber != sourceGroup {
+		remaining, err := groupEntryIDs(ctx, tx, sourceGroup)
+		if err != nil {
+			return false, fmt.Errorf("move entry: %w", err)
+		}
+		if err := setGroupPositions(ctx, tx, sourceGroup, remaining); err != nil {
+			return false, fmt.Errorf("move entry compact source: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// checkNotInGroup returns ErrAlreadyInGroup if the group already has a live
+// entry for the entry's movie, other than the entry itself
+func checkNotInGroup(ctx context.Context, tx pgx.Tx, id uuid.UUID, groupNumber int) error {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM entries other
+			JOIN entries e ON e.movie_id = other.movie_id
+			WHERE e.id = $1 AND other.id <> $1 AND other.group_number = $2 AND other.deleted_at IS NULL
+		)`
+	var exists bool
+	if err := tx.QueryRow(ctx, query, id, groupNumber).Scan(&exists); err != nil {
+		return fmt.Errorf("check group for movie: %w", err)
+	}
+	if exists {
+		return ErrAlreadyInGroup
+	}
+	return nil
+}
+
+// lockGroups takes the position locks of two groups, which may be the same, in
+// ascending order so that moves in opposite directions can't deadlock
+func lockGroups(ctx context.Context, tx pgx.Tx, a, b int) error {
+	for _, number := range lockOrder(a, b) {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(1, $1)", number); err != nil {
+			return fmt.Errorf("lock group %d: %w", number, err)
+		}
+	}
+	return nil
+}
+
+// lockOrder returns the distinct groups among a and b, ascending
+func lockOrder(a, b int) []int {
+	switch {
+	case a == b:
+		return []int{a}
+	case a > b:
+		return []int{b, a}
+	default:
+		return []int{a, b}
+	}
+}
+
+// placeInGroup renumbers a group with the entry at position, or last when position is nil
+func placeInGroup(ctx context.Context, tx pgx.Tx, id uuid.UUID, groupNumber int, position *int) error {
+	ids, err := groupEntryIDs(ctx, tx, groupNumber)
+	if err != nil {
+		return err
+	}
+	return setGroupPositions(ctx, tx, groupNumber, orderWith(ids, id, position))
+}
+
+// orderWith returns ids in order with id at position, clamped to the list, or
+// last when position is nil
+func orderWith(ids []uuid.UUID, id uuid.UUID, position *int) []uuid.UUID {
+	others := make([]uuid.UUID, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			others = append(others, other)
+		}
+	}
+
+	index := len(others)
+	if position != nil {
+		index = min(max(*position-1, 0), len(others))
+	}
+
+	ordered := make([]uuid.UUID, 0, len(others)+1)
+	ordered = append(ordered, others[:index]...)
+	ordered = append(ordered, id)
+	ordered = append(ordered, others[index:]...)
+	return ordered
+}
+
+// groupEntryIDs returns a group's entry IDs in display order
+func groupEntryIDs(ctx context.Context, tx pgx.Tx, groupNumber int) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `SELECT id FROM entries WHERE group_number = $1 AND deleted_at IS NULL ORDER BY position, added_at`, groupNumber)
+	if err != nil {
+		return nil, fmt.Errorf("list group entry ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan group entry id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group entry ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// setGroupPositions numbers the given entries of a group 1..n in order.
+// The caller must hold the group's lock.
+func setGroupPositions(ctx context.Context, tx pgx.Tx, groupNumber int, entryIDs []uuid.UUID) error {
+	if len(entryIDs) == 0 {
+		return nil
+	}
+
+	positions := make([]int, len(entryIDs))
+	for i := range entryIDs {
+		positions[i] = i + 1
+	}
+
+	// Move current positions out of the way to avoid unique constraint conflicts.
+	if _, err := tx.Exec(ctx, `
+		UPDATE entries
+		SET position = -position
+		WHERE group_number = $1 AND id = ANY($2::uuid[])`,
+		groupNumber,
+		entryIDs,
+	); err != nil {
+		return fmt.Errorf("set entry temp positions: %w", err)
+	}
+
+	query := `
+		UPDATE entries AS e
+		SET position = v.position
+		FROM (
+			SELECT unnest($1::uuid[]) AS id, unnest($2::int[]) AS position
+		) AS v
+		WHERE e.id = v.id AND e.group_number = $3`
+	if _, err := tx.Exec(ctx, query, entryIDs, positions, groupNumber); err != nil {
+		return fmt.Errorf("update entry positions: %w", err)
+	}
+
+	return nil
+}
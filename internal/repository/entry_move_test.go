@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderWith(t *testing.T) {
+	a, b, c, x := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	names := map[uuid.UUID]string{a: "a", b: "b", c: "c", x: "x"}
+	at := func(n int) *int { return &n }
+
+	tests := []struct {
+		name     string
+		ids      []uuid.UUID
+		id       uuid.UUID
+		position *int
+		want     []uuid.UUID
+	}{
+		{name: "appended without a position", ids: []uuid.UUID{a, b, c}, id: x, want: []uuid.UUID{a, b, c, x}},
+		{name: "inserted at the front", ids: []uuid.UUID{a, b, c}, id: x, position: at(1), want: []uuid.UUID{x, a, b, c}},
+		{name: "inserted in the middle", ids: []uuid.UUID{a, b, c}, id: x, position: at(2), want: []uuid.UUID{a, x, b, c}},
+		{name: "position past the end is clamped", ids: []uuid.UUID{a, b, c}, id: x, position: at(9), want: []uuid.UUID{a, b, c, x}},
+		{name: "position below 1 is clamped", ids: []uuid.UUID{a, b, c}, id: x, position: at(0), want: []uuid.UUID{x, a, b, c}},
+		{name: "into an empty group", id: x, position: at(3), want: []uuid.UUID{x}},
+		{name: "moved down within its group", ids: []uuid.UUID{a, b, c}, id: a, position: at(3), want: []uuid.UUID{b, c, a}},
+		{name: "moved up within its group", ids: []uuid.UUID{a, b, c}, id: c, position: at(1), want: []uuid.UUID{c, a, b}},
+		{name: "left in place within its group", ids: []uuid.UUID{a, b, c}, id: b, position: at(2), want: []uuid.UUID{a, b, c}},
+		{name: "moved last within its group", ids: []uuid.UUID{a, b, c}, id: a, want: []uuid.UUID{b, c, a}},
+	}
+
+	label := func(ids []uuid.UUID) []string {
+		labels := make([]string, len(ids))
+		for i, id := range ids {
+			labels[i] = names[id]
+		}
+		return labels
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := orderWith(tt.ids, tt.id, tt.position)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("orderWith() = %v, want %v", label(got), label(tt.want))
+			}
+		})
+	}
+}
+
+func TestLockOrder(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want []int
+	}{
+		{a: 2, b: 2, want: []int{2}},
+		{a: 1, b: 3, want: []int{1, 3}},
+		{a: 3, b: 1, want: []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		if got := lockOrder(tt.a, tt.b); !slices.Equal(got, tt.want) {
+			t.Errorf("lockOrder(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExportRepository reads the whole collection for exports
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewExportRepository creates a new ExportRepository
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{pool: pool}
+}
+
+// Each calls fn for every entry, by group then position, as rows arrive from
+// the database so the collection is never held in memory. Deleted entries and
+// movies are left out. Stops at the first error fn returns.
+func (r *ExportRepository) Each(ctx context.Context, fn func(*model.ExportRow) error) error {
+	query := `
+		SELECT e.id, m.title, m.release_year, m.tmdb_id, m.imdb_id,
+		       e.group_number, g.name, e.position, e.watched_at, p.name, e.notes,
+		       (SELECT jsonb_object_agg(r.person_id, r.score)
+		        FROM ratings r
+		        WHERE r.entry_id = e.id AND r.deleted_at IS NULL)
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id AND m.deleted_at IS NULL
+		JOIN groups g ON e.group_number = g.number
+		LEFT JOIN persons p ON e.picked_by_person_id = p.id
+		WHERE e.deleted_at IS NULL
+		ORDER BY e.group_number, e.position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("export entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &model.ExportRow{}
+		if err := rows.Scan(
+			&row.EntryID,
+			&row.Title,
+			&row.ReleaseYear,
+			&row.TMDBId,
+			&row.IMDBId,
+			&row.GroupNumber,
+			&row.GroupName,
+			&row.Position,
+			&row.WatchedAt,
+			&row.PickedBy,
+			&row.Notes,
+			&row.Scores,
+		); err != nil {
+			return fmt.Errorf("scan export row: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate export rows: %w", err)
+	}
+	return nil
+}
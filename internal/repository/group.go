@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GroupRepository handles database operations for watch groups
+type GroupRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewGroupRepository creates a new GroupRepository
+func NewGroupRepository(pool *pgxpool.Pool) *GroupRepository {
+	return &GroupRepository{pool: pool}
+}
+
+// groupColumns selects a group with its entry counts; queries must alias groups as g
+const groupColumns = `
+	g.number, g.name, g.theme, g.description, g.starts_on, g.ends_on, g.is_current, g.archived_at, g.created_at,
+	(SELECT COUNT(*) FROM entries e WHERE e.group_number = g.number AND e.deleted_at IS NULL),
+	(SELECT COUNT(e.watched_at) FROM entries e WHERE e.group_number = g.number AND e.deleted_at IS NULL)`
+
+func scanGroup(row pgx.Row) (*model.Group, error) {
+	group := &model.Group{}
+	err := row.Scan(
+		&group.Number,
+		&group.Name,
+		&group.Theme,
+		&group.Description,
+		&group.StartsOn,
+		&group.EndsOn,
+		&group.IsCurrent,
+		&group.ArchivedAt,
+		&group.CreatedAt,
+		&group.EntryCount,
+		&group.WatchedCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// List retrieves groups in ascending order, optionally including archived ones
+func (r *GroupRepository) List(ctx context.Context, includeArchived bool) ([]*model.Group, error) {
+	query := `
+		SELECT ` + groupColumns + `
+		FROM groups g
+		WHERE $1 OR g.archived_at IS NULL
+		ORDER BY g.number`
+
+	rows, err := r.pool.Query(ctx, query, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("list groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ListNewest retrieves up to limit of the newest active groups, newest first
+func (r *GroupRepository) ListNewest(ctx context.Context, limit int) ([]*model.Group, error) {
+	// Limit before selecting, so only the returned groups are counted
+	query := `
+		SELECT ` + groupColumns + `
+		FROM (
+			SELECT * FROM groups
+			WHERE archived_at IS NULL
+			ORDER BY number DESC
+			LIMIT $1
+		) g
+		ORDER BY g.number DESC`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list newest groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ListChoices retrieves every active group's number, name and entry count,
+// newest first, for choosing a group. Other fields are left empty.
+func (r *GroupRepository) ListChoices(ctx context.Context) ([]*model.Group, error) {
+	query := `
+		SELECT g.number, g.name, COUNT(e.id)
+		FROM groups g
+		LEFT JOIN entries e ON e.group_number = g.number AND e.deleted_at IS NULL
+		WHERE g.archived_at IS NULL
+		GROUP BY g.number
+		ORDER BY g.number DESC`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list group choices: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*model.Group
+	for rows.Next() {
+		group := &model.Group{}
+		if err := rows.Scan(&group.Number, &group.Name, &group.EntryCount); err != nil {
+			return nil, fmt.Errorf("scan group choice: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate group choices: %w", err)
+	}
+
+	return groups, nil
+}
+
+// NextNumber returns the number a new group would take, one past the highest
+// group including archived ones
+func (r *GroupRepository) NextNumber(ctx context.Context) (int, error) {
+	var number int
+	if err := r.pool.QueryRow(ctx, `SELECT COALESCE(MAX(number), 0) + 1 FROM groups`).Scan(&number); err != nil {
+		return 0, fmt.Errorf("get next group number: %w", err)
+	}
+	return number, nil
+}
+
+// GetByNumber retrieves a group, or nil if it does not exist
+func (r *GroupRepository) GetByNumber(ctx context.Context, number int) (*model.Group, error) {
+	query := `SELECT ` + groupColumns + ` FROM groups g WHERE g.number = $1`
+
+	group, err := scanGroup(r.pool.QueryRow(ctx, query, number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetCurrent returns the current group's number. Without an explicit current
+// group it falls back to the highest active group, then to 1.
+func (r *GroupRepository) GetCurrent(ctx context.Context) (int, error) {
+	query := `
+		SELECT COALESCE(
+			(SELECT number FROM groups WHERE is_current),
+			(SELECT MAX(number) FROM groups WHERE archived_at IS NULL),
+			1)`
+
+	var number int
+	if err := r.pool.QueryRow(ctx, query).Scan(&number); err != nil {
+		return 1, fmt.Errorf("get current group: %w", err)
+	}
+
+	return number, nil
+}
+
+// GetOlder returns the highest active group number below number, or nil if there is none
+func (r *GroupRepository) GetOlder(ctx context.Context, number int) (*int, error) {
+	query := `SELECT MAX(number) FROM groups WHERE number < $1 AND archived_at IS NULL`
+
+	var older *int
+	if err := r.pool.QueryRow(ctx, query, number).Scan(&older); err != nil {
+		return nil, fmt.Errorf("get older group: %w", err)
+	}
+
+	return older, nil
+}
+
+// Create inserts a group. A nil number takes the next number after the highest group.
+func (r *GroupRepository) Create(ctx context.Context, number *int, input model.GroupInput, makeCurrent bool) (*model.Group, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create group begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Serialize number assignment so concurrent creates don't pick the same number
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(3, 0)"); err != nil {
+		return nil, fmt.Errorf("create group lock: %w", err)
+	}
+
+	query := `
+		INSERT INTO groups (number, name, theme, description, starts_on, ends_on)
+		VALUES (COALESCE($1::int, (SELECT COALESCE(MAX(number), 0) + 1 FROM groups)), $2, $3, $4, $5, $6)
+		RETURNING number`
+
+	var created int
+	if err := tx.QueryRow(ctx, query,
+		number,
+		input.Name,
+		input.Theme,
+		input.Description,
+		input.StartsOn,
+		input.EndsOn,
+	).Scan(&created); err != nil {
+		return nil, fmt.Errorf("create group: %w", err)
+	}
+
+	if makeCurrent {
+		if err := setCurrentGroup(ctx, tx, created); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create group commit: %w", err)
+	}
+
+	return r.GetByNumber(ctx, created)
+}
+
+// Update replaces a group's details, returning nil if it does not exist
+func (r *GroupRepository) Update(ctx context.Context, number int, input model.GroupInput) (*model.Group, error) {
+	query := `
+		UPDATE groups
+		SET name = $2, theme = $3, description = $4, starts_on = $5, ends_on = $6
+		WHERE number = $1`
+
+	tag, err := r.pool.Exec(ctx, query,
+		number,
+		input.Name,
+		input.Theme,
+		input.Description,
+		input.StartsOn,
+		input.EndsOn,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("update group: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	return r.GetByNumber(ctx, number)
+}
+
+// SetCurrent makes a group the current one, restoring it if it was archived
+func (r *GroupRepository) SetCurrent(ctx context.Context, number int) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("set current group begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := setCurrentGroup(ctx, tx, number); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("set current group commit: %w", err)
+	}
+	return nil
+}
+
+// Archive hides a group from the dashboard; an archived group is never current
+func (r *GroupRepository) Archive(ctx context.Context, number int) error {
+	query := `UPDATE groups SET archived_at = NOW(), is_current = FALSE WHERE number = $1 AND archived_at IS NULL`
+	_, err := r.pool.Exec(ctx, query, number)
+	if err != nil {
+		return fmt.Errorf("archive group: %w", err)
+	}
+	return nil
+}
+
+// Unarchive restores an archived group
+func (r *GroupRepository) Unarchive(ctx context.Context, number int) error {
+	query := `UPDATE groups SET archived_at = NULL WHERE number = $1`
+	_, err := r.pool.Exec(ctx, query, number)
+	if err != nil {
+		return fmt.Errorf("unarchive group: %w", err)
+	}
+	return nil
+}
+
+// setCurrentGroup moves the current flag to the given group within tx.
+// The flag is cleared first because the one-current index is checked row by row.
+func setCurrentGroup(ctx context.Context, tx pgx.Tx, number int) error {
+	if _, err := tx.Exec(ctx, `UPDATE groups SET is_current = FALSE WHERE is_current AND number <> $1`, number); err != nil {
+		return fmt.Errorf("clear current group: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE groups SET is_current = TRUE, archived_at = NULL WHERE number = $1`, number); err != nil {
+		return fmt.Errorf("set current group: %w", err)
+	}
+	return nil
+}
+
+// ErrUnknownGroup is returned when an entry is added, moved or copied to a
+// group that doesn't exist and isn't the next free number
+var ErrUnknownGroup = errors.New("group does not exist")
+
+// ensureGroup checks an entry's group exists, creating it if it is the next
+// free number. A new group becomes current, as adding to "+ New Group" always
+// did before groups had a table. Any other missing number is ErrUnknownGroup,
+// so a typo can't start a group.
+func ensureGroup(ctx context.Context, tx pgx.Tx, number int) error {
+	// Same lock as GroupRepository.Create, which picks the next free number
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(3, 0)"); err != nil {
+		return fmt.Errorf("ensure group lock: %w", err)
+	}
+
+	var exists bool
+	var next int
+	query := `SELECT COALESCE(BOOL_OR(number = $1), FALSE), COALESCE(MAX(number), 0) + 1 FROM groups`
+	if err := tx.QueryRow(ctx, query, number).Scan(&exists, &next); err != nil {
+		return fmt.Errorf("ensure group: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	if number != next {
+		return fmt.Errorf("group %d: %w; the next new group is %d", number, ErrUnknownGroup, next)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO groups (number) VALUES ($1)`, number); err != nil {
+		return fmt.Errorf("ensure group: %w", err)
+	}
+	return setCurrentGroup(ctx, tx, number)
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a library cursor is malformed or was made for a different sort
+var ErrInvalidCursor = errors.New("invalid library cursor")
+
+// librarySortColumn describes how to order by a library sort field.
+// Missing values are replaced by a sentinel so they sort last and keyset
+// comparisons never see NULL.
+type librarySortColumn struct {
+	expr     string // SQL expression
+	sqlType  string // Type the cursor value is cast back to
+	ascNull  string // Stand-in for NULL when sorting ascending
+	descNull string // Stand-in for NULL when sorting descending
+}
+
+var librarySortColumns = map[model.LibrarySort]librarySortColumn{
+	model.LibrarySortAdded:   {expr: "e.added_at", sqlType: "timestamptz"},
+	model.LibrarySortTitle:   {expr: "lower(m.title)", sqlType: "text"},
+	model.LibrarySortYear:    {expr: "m.release_year", sqlType: "int", ascNull: "2147483647", descNull: "-2147483648"},
+	model.LibrarySortRuntime: {expr: "m.runtime_minutes", sqlType: "int", ascNull: "2147483647", descNull: "-2147483648"},
+	model.LibrarySortWatched: {expr: "e.watched_at", sqlType: "date", ascNull: "'infinity'", descNull: "'-infinity'"},
+	model.LibrarySortPicker:  {expr: "p.position", sqlType: "int", ascNull: "2147483647", descNull: "-2147483648"},
+	model.LibrarySortScore:   {expr: "s.avg_score", sqlType: "float8", ascNull: "'Infinity'", descNull: "'-Infinity'"},
+}
+
+// orderExpr returns the non-null expression to order by
+func (c librarySortColumn) orderExpr(descending bool) string {
+	null := c.ascNull
+	if descending {
+		null = c.descNull
+	}
+	if null == "" {
+		return c.expr
+	}
+	return fmt.Sprintf("COALESCE(%s, (%s)::%s)", c.expr, null, c.sqlType)
+}
+
+// libraryCursor is the position after the last entry of a page
+type libraryCursor struct {
+	Sort string    `json:"s"` // Sort and direction the cursor was made for
+	Key  string    `json:"k"` // Sort value, as Postgres text
+	ID   uuid.UUID `json:"id"`
+}
+
+func librarySortKey(filter model.LibraryFilter) string {
+	if filter.Descending {
+		return string(filter.Sort) + ":desc"
+	}
+	return string(filter.Sort) + ":asc"
+}
+
+func encodeLibraryCursor(cursor libraryCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeLibraryCursor(s string, filter model.LibraryFilter) (*libraryCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor libraryCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != librarySortKey(filter) {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Browse returns one page of the library, filtered and sorted, using keyset pagination
+func (r *EntryRepository) Browse(ctx context.Context, filter model.LibraryFilter) (*model.LibraryPage, error) {
+	column, ok := librarySortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("browse library: unknown sort %q", filter.Sort)
+	}
+
+	var cursorKey *string
+	var cursorID *uuid.UUID
+	if filter.After != "" {
+		cursor, err := decodeLibraryCursor(filter.After, filter)
+		if err != nil {
+			return nil, err
+		}
+		cursorKey, cursorID = &cursor.Key, &cursor.ID
+	}
+
+	var genre *string
+	if filter.Genre != "" {
+		genre = &filter.Genre
+	}
+
+	direction, compare := "ASC", ">"
+	if filter.Descending {
+		direction, compare = "DESC", "<"
+	}
+	orderExpr := column.orderExpr(filter.Descending)
+
+	// The sort expression and direction come from librarySortColumns, never from user input
+	query := fmt.Sprintf(`
+		SELECT e.id, e.movie_id, e.group_number, e.position, e.watched_at, e.added_at, e.notes, e.picked_by_person_id,
+		       m.id, m.created_at, m.updated_at, m.title, m.release_year, m.poster_url, m.synopsis, m.runtime_minutes, m.tmdb_id, m.imdb_id, m.metadata_json,
+		       p.id, p.initial, p.name,
+		       (%[1]s)::text
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		LEFT JOIN persons p ON e.picked_by_person_id = p.id
+		LEFT JOIN LATERAL (
+			SELECT AVG(r.score)::float8 AS avg_score FROM ratings r WHERE r.entry_id = e.id AND r.deleted_at IS NULL AND `+attendedRating+`
+		) s ON TRUE
+		WHERE e.deleted_at IS NULL
+		  AND ($1::text IS NULL OR m.metadata_json->'genres' @> jsonb_build_array(jsonb_build_object('name', $1::text)))
+		  AND ($2::int IS NULL OR m.release_year BETWEEN $2 AND $2 + 9)
+		  AND ($3::int IS NULL OR m.runtime_minutes >= $3)
+		  AND ($4::int IS NULL OR m.runtime_minutes <= $4)
+		  AND ($5::boolean IS NULL OR (e.watched_at IS NOT NULL) = $5)
+		  AND ($6::uuid IS NULL OR e.picked_by_person_id = $6)
+		  AND ($7::float8 IS NULL OR s.avg_score >= $7)
+		  AND ($8::float8 IS NULL OR s.avg_score <= $8)
+		  AND ($9::text IS NULL OR (%[1]s, e.id) %[2]s ($9::text::%[3]s, $10::uuid))
+		ORDER BY %[1]s %[4]s, e.id %[4]s
+		LIMIT $11`, orderExpr, compare, column.sqlType, direction)
+
+	// Fetch one extra row to learn whether there is another page
+	rows, err := r.pool.Query(ctx, query,
+		genre,
+		filter.Decade,
+		filter.MinRuntime,
+		filter.MaxRuntime,
+		filter.Watched,
+		filter.PickedByPersonID,
+		filter.MinScore,
+		filter.MaxScore,
+		cursorKey,
+		cursorID,
+		filter.Limit+1,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("browse library: %w", err)
+	}
+	defer rows.Close()
+
+	page := &model.LibraryPage{Entries: []*model.Entry{}}
+	var sortKeys []string
+	for rows.Next() {
+		entry := &model.Entry{}
+		movie := &model.Movie{}
+		var pickedByPersonDBID *uuid.UUID
+		var pickedByInitial *string
+		var pickedByName *string
+		var sortKey string
+
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.MovieID,
+			&entry.GroupNumber,
+			&entry.Position,
+			&entry.WatchedAt,
+			&entry.AddedAt,
+			&entry.Notes,
+			&entry.PickedByPersonID,
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.UpdatedAt,
+			&movie.Title,
+			&movie.ReleaseYear,
+			&movie.PosterURL,
+			&movie.Synopsis,
+			&movie.RuntimeMinutes,
+			&movie.TMDBId,
+			&movie.IMDBId,
+			&movie.MetadataJSON,
+			&pickedByPersonDBID,
+			&pickedByInitial,
+			&pickedByName,
+			&sortKey,
+		); err != nil {
+			return nil, fmt.Errorf("scan library entry: %w", err)
+		}
+		entry.Movie = movie
+		applyPickedByPerson(entry, pickedByPersonDBID, pickedByInitial, pickedByName)
+		page.Entries = append(page.Entries, entry)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate library entries: %w", err)
+	}
+
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		last := page.Entries[len(page.Entries)-1]
+		page.NextCursor = encodeLibraryCursor(libraryCursor{
+			Sort: librarySortKey(filter),
+			Key:  sortKeys[filter.Limit-1],
+			ID:   last.ID,
+		})
+	}
+
+	entryIDs := make([]uuid.UUID, 0, len(page.Entries))
+	for _, entry := range page.Entries {
+		entryIDs = append(entryIDs, entry.ID)
+	}
+
+	ratingsByEntry, err := r.getRatingsForEntries(ctx, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+	attendeesByEntry, err := r.getAttendeesForEntries(ctx, entryIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range page.Entries {
+		entry.Ratings = ratingsByEntry[entry.ID]
+		entry.AttendeeIDs = attendeesByEntry[entry.ID]
+	}
+
+	return page, nil
+}
+
+// LibraryFacets returns the genres and decades present in the library
+func (r *EntryRepository) LibraryFacets(ctx context.Context) (*model.LibraryFacets, error) {
+	facets := &model.LibraryFacets{Genres: []string{}, Decades: []int{}}
+
+	genreQuery := `
+		SELECT DISTINCT g.name #>> '{}' AS genre
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		CROSS JOIN LATERAL jsonb_path_query(m.metadata_json, '$.genres[*].name') AS g(name)
+		WHERE e.deleted_at IS NULL
+		ORDER BY genre`
+
+	rows, err := r.pool.Query(ctx, genreQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list library genres: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var genre string
+		if err := rows.Scan(&genre); err != nil {
+			return nil, fmt.Errorf("scan library genre: %w", err)
+		}
+		facets.Genres = append(facets.Genres, genre)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate library genres: %w", err)
+	}
+
+	decadeQuery := `
+		SELECT DISTINCT (m.release_year / 10) * 10 AS decade
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		WHERE m.release_year IS NOT NULL AND e.deleted_at IS NULL
+		ORDER BY decade`
+
+	rows, err = r.pool.Query(ctx, decadeQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list library decades: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var decade int
+		if err := rows.Scan(&decade); err != nil {
+			return nil, fmt.Errorf("scan library decade: %w", err)
+		}
+		facets.Decades = append(facets.Decades, decade)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate library decades: %w", err)
+	}
+
+	return facets, nil
+}
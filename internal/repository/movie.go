@@ -35,28 +35,34 @@ func (r *MovieRepository) Create(ctx context.Context, input model.CreateMovieInp
 		RETURNING id, created_at, updated_at, title, release_year, poster_url, synopsis, runtime_minutes, tmdb_id, imdb_id, metadata_json`
 
 	movie := &model.Movie{}
-	err := r.pool.QueryRow(ctx, query,
-		input.Title,
-		input.ReleaseYear,
-		input.PosterURL,
-		input.Synopsis,
-		input.RuntimeMinutes,
-		input.TMDBId,
-		input.IMDBId,
-		metadataBytes,
-	).Scan(
-		&movie.ID,
-		&movie.CreatedAt,
-		&movie.UpdatedAt,
-		&movie.Title,
-		&movie.ReleaseYear,
-		&movie.PosterURL,
-		&movie.Synopsis,
-		&movie.RuntimeMinutes,
-		&movie.TMDBId,
-		&movie.IMDBId,
-		&movie.MetadataJSON,
-	)
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query,
+			input.Title,
+			input.ReleaseYear,
+			input.PosterURL,
+			input.Synopsis,
+			input.RuntimeMinutes,
+			input.TMDBId,
+			input.IMDBId,
+			metadataBytes,
+		).Scan(
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.UpdatedAt,
+			&movie.Title,
+			&movie.ReleaseYear,
+			&movie.PosterURL,
+			&movie.Synopsis,
+			&movie.RuntimeMinutes,
+			&movie.TMDBId,
+			&movie.IMDBId,
+			&movie.MetadataJSON,
+		)
+		if err != nil {
+			return err
+		}
+		return auditCreated(ctx, tx, model.AuditActionCreate, model.AuditEntityMovie, movie.ID)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("create movie: %w", err)
 	}
@@ -64,12 +70,37 @@ func (r *MovieRepository) Create(ctx context.Context, input model.CreateMovieInp
 	return movie, nil
 }
 
+// RestoreByTMDBId brings a deleted movie back out of the trash, leaving the
+// entries deleted with it where they are. Returns nil if no movie with the
+// TMDB ID is in the trash.
+func (r *MovieRepository) RestoreByTMDBId(ctx context.Context, tmdbID int) (*model.Movie, error) {
+	var id uuid.UUID
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, `SELECT id FROM movies WHERE tmdb_id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, tmdbID).Scan(&id)
+		if err != nil {
+			return err
+		}
+		return audited(ctx, tx, func() error {
+			_, err := tx.Exec(ctx, `UPDATE movies SET deleted_at = NULL WHERE id = $1`, id)
+			return err
+		}, auditScope{action: model.AuditActionRestore, entityType: model.AuditEntityMovie, where: "t.id = $1", args: []any{id}})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("restore movie by tmdb id: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 // GetByID retrieves a movie by its ID
 func (r *MovieRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Movie, error) {
 	query := `
 		SELECT id, created_at, updated_at, title, release_year, poster_url, synopsis, runtime_minutes, tmdb_id, imdb_id, metadata_json
 		FROM movies
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	movie := &model.Movie{}
 	err := r.pool.QueryRow(ctx, query, id).Scan(
@@ -100,7 +131,7 @@ func (r *MovieRepository) GetByTMDBId(ctx context.Context, tmdbID int) (*model.M
 	query := `
 		SELECT id, created_at, updated_at, title, release_year, poster_url, synopsis, runtime_minutes, tmdb_id, imdb_id, metadata_json
 		FROM movies
-		WHERE tmdb_id = $1`
+		WHERE tmdb_id = $1 AND deleted_at IS NULL`
 
 	movie := &model.Movie{}
 	err := r.pool.QueryRow(ctx, query, tmdbID).Scan(
@@ -131,6 +162,7 @@ func (r *MovieRepository) List(ctx context.Context) ([]*model.Movie, error) {
 	query := `
 		SELECT id, created_at, updated_at, title, release_year, poster_url, synopsis, runtime_minutes, tmdb_id, imdb_id, metadata_json
 		FROM movies
+		WHERE deleted_at IS NULL
 		ORDER BY title`
 
 	rows, err := r.pool.Query(ctx, query)
@@ -206,23 +238,27 @@ func (r *MovieRepository) Update(ctx context.Context, id uuid.UUID, input model.
 	query := fmt.Sprintf(`
 		UPDATE movies
 		SET %s
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 		RETURNING id, created_at, updated_at, title, release_year, poster_url, synopsis, runtime_minutes, tmdb_id, imdb_id, metadata_json`, strings.Join(setClauses, ", "))
 
 	updated := &model.Movie{}
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&updated.ID,
-		&updated.CreatedAt,
-		&updated.UpdatedAt,
-		&updated.Title,
-		&updated.ReleaseYear,
-		&updated.PosterURL,
-		&updated.Synopsis,
-		&updated.RuntimeMinutes,
-		&updated.TMDBId,
-		&updated.IMDBId,
-		&updated.MetadataJSON,
-	)
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			return tx.QueryRow(ctx, query, args...).Scan(
+				&updated.ID,
+				&updated.CreatedAt,
+				&updated.UpdatedAt,
+				&updated.Title,
+				&updated.ReleaseYear,
+				&updated.PosterURL,
+				&updated.Synopsis,
+				&updated.RuntimeMinutes,
+				&updated.TMDBId,
+				&updated.IMDBId,
+				&updated.MetadataJSON,
+			)
+		}, auditScope{action: model.AuditActionUpdate, entityType: model.AuditEntityMovie, where: "t.id = $1", args: []any{id}})
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -233,10 +269,35 @@ func (r *MovieRepository) Update(ctx context.Context, id uuid.UUID, input model.
 	return updated, nil
 }
 
-// Delete removes a movie from the database
+// Delete moves a movie to the trash along with its entries and their ratings.
+// They share one deleted_at so restoring the movie brings back exactly those.
 func (r *MovieRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM movies WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+	query := `
+		WITH trashed_movie AS (
+			UPDATE movies SET deleted_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id, deleted_at
+		), trashed_entries AS (
+			UPDATE entries e
+			SET deleted_at = t.deleted_at
+			FROM trashed_movie t
+			WHERE e.movie_id = t.id AND e.deleted_at IS NULL
+			RETURNING e.id, e.deleted_at
+		)
+		UPDATE ratings r
+		SET deleted_at = t.deleted_at
+		FROM trashed_entries t
+		WHERE r.entry_id = t.id AND r.deleted_at IS NULL`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			_, err := tx.Exec(ctx, query, id)
+			return err
+		},
+			auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityMovie, where: "t.id = $1", args: []any{id}},
+			auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityEntry, where: "t.movie_id = $1", args: []any{id}},
+			auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityRating, where: "t.entry_id IN (SELECT id FROM entries WHERE movie_id = $1)", args: []any{id}},
+		)
+	})
 	if err != nil {
 		return fmt.Errorf("delete movie: %w", err)
 	}
@@ -11,7 +11,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PersonRepository handles database operations for persons (read-only)
+// PersonRepository handles database operations for persons
 type PersonRepository struct {
 	pool *pgxpool.Pool
 }
@@ -21,20 +21,36 @@ func NewPersonRepository(pool *pgxpool.Pool) *PersonRepository {
 	return &PersonRepository{pool: pool}
 }
 
-// GetAll retrieves all persons ordered by initial
-func (r *PersonRepository) GetAll(ctx context.Context) ([]*model.Person, error) {
-	query := `SELECT id, initial, name FROM persons ORDER BY initial`
+const personColumns = `id, initial, name, position, archived_at, created_at, password_hash IS NOT NULL`
 
+func scanPerson(row pgx.Row) (*model.Person, error) {
+	person := &model.Person{}
+	err := row.Scan(
+		&person.ID,
+		&person.Initial,
+		&person.Name,
+		&person.Position,
+		&person.ArchivedAt,
+		&person.CreatedAt,
+		&person.HasPassword,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return person, nil
+}
+
+func (r *PersonRepository) list(ctx context.Context, query string) ([]*model.Person, error) {
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("get all persons: %w", err)
+		return nil, fmt.Errorf("list persons: %w", err)
 	}
 	defer rows.Close()
 
 	var persons []*model.Person
 	for rows.Next() {
-		person := &model.Person{}
-		if err := rows.Scan(&person.ID, &person.Initial, &person.Name); err != nil {
+		person, err := scanPerson(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan person: %w", err)
 		}
 		persons = append(persons, person)
@@ -46,12 +62,23 @@ func (r *PersonRepository) GetAll(ctx context.Context) ([]*model.Person, error)
 	return persons, nil
 }
 
+// GetAll retrieves all active (non-archived) persons in display order
+func (r *PersonRepository) GetAll(ctx context.Context) ([]*model.Person, error) {
+	query := `SELECT ` + personColumns + ` FROM persons WHERE archived_at IS NULL ORDER BY position`
+	return r.list(ctx, query)
+}
+
+// ListAll retrieves every person, active ones first, each in display order
+func (r *PersonRepository) ListAll(ctx context.Context) ([]*model.Person, error) {
+	query := `SELECT ` + personColumns + ` FROM persons ORDER BY archived_at IS NOT NULL, position`
+	return r.list(ctx, query)
+}
+
 // GetByID retrieves a person by their ID
 func (r *PersonRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Person, error) {
-	query := `SELECT id, initial, name FROM persons WHERE id = $1`
+	query := `SELECT ` + personColumns + ` FROM persons WHERE id = $1`
 
-	person := &model.Person{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(&person.ID, &person.Initial, &person.Name)
+	person, err := scanPerson(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -64,10 +91,9 @@ func (r *PersonRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Pe
 
 // GetByInitial retrieves a person by their initial
 func (r *PersonRepository) GetByInitial(ctx context.Context, initial string) (*model.Person, error) {
-	query := `SELECT id, initial, name FROM persons WHERE initial = $1`
+	query := `SELECT ` + personColumns + ` FROM persons WHERE initial = $1`
 
-	person := &model.Person{}
-	err := r.pool.QueryRow(ctx, query, initial).Scan(&person.ID, &person.Initial, &person.Name)
+	person, err := scanPerson(r.pool.QueryRow(ctx, query, initial))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -78,7 +104,7 @@ func (r *PersonRepository) GetByInitial(ctx context.Context, initial string) (*m
 	return person, nil
 }
 
-// GetAllAsMap returns all persons as a map keyed by initial
+// GetAllAsMap returns all active persons as a map keyed by initial
 func (r *PersonRepository) GetAllAsMap(ctx context.Context) (map[string]*model.Person, error) {
 	persons, err := r.GetAll(ctx)
 	if err != nil {
@@ -93,3 +119,185 @@ func (r *PersonRepository) GetAllAsMap(ctx context.Context) (map[string]*model.P
 	return personMap, nil
 }
 
+// Create inserts a new person at the end of the display order
+func (r *PersonRepository) Create(ctx context.Context, input model.CreatePersonInput) (*model.Person, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create person begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Serialize position assignment to avoid duplicate positions under concurrency.
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(2, 0)"); err != nil {
+		return nil, fmt.Errorf("create person lock: %w", err)
+	}
+
+	query := `
+		INSERT INTO persons (initial, name, position)
+		VALUES ($1, $2, COALESCE((SELECT MAX(position) FROM persons), 0) + 1)
+		RETURNING ` + personColumns
+
+	person, err := scanPerson(tx.QueryRow(ctx, query, input.Initial, input.Name))
+	if err != nil {
+		return nil, fmt.Errorf("create person: %w", err)
+	}
+
+	if err := auditCreated(ctx, tx, model.AuditActionCreate, model.AuditEntityPerson, person.ID); err != nil {
+		return nil, fmt.Errorf("create person: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("create person commit: %w", err)
+	}
+
+	return person, nil
+}
+
+// Update renames a person or changes their initial
+func (r *PersonRepository) Update(ctx context.Context, id uuid.UUID, input model.UpdatePersonInput) (*model.Person, error) {
+	query := `
+		UPDATE persons
+		SET initial = COALESCE($2, initial),
+		    name = COALESCE($3, name)
+		WHERE id = $1
+		RETURNING ` + personColumns
+
+	var person *model.Person
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			var err error
+			person, err = scanPerson(tx.QueryRow(ctx, query, id, input.Initial, input.Name))
+			return err
+		}, auditScope{action: model.AuditActionUpdate, entityType: model.AuditEntityPerson, where: "t.id = $1", args: []any{id}})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("update person: %w", err)
+	}
+
+	return person, nil
+}
+
+// Archive hides a person from rating grids and pickers while keeping their ratings
+func (r *PersonRepository) Archive(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE persons SET archived_at = NOW() WHERE id = $1 AND archived_at IS NULL`
+	err := r.auditedExec(ctx, model.AuditActionArchive, id, query)
+	if err != nil {
+		return fmt.Errorf("archive person: %w", err)
+	}
+	return nil
+}
+
+// Unarchive restores an archived person
+func (r *PersonRepository) Unarchive(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE persons SET archived_at = NULL WHERE id = $1`
+	err := r.auditedExec(ctx, model.AuditActionUnarchive, id, query)
+	if err != nil {
+		return fmt.Errorf("unarchive person: %w", err)
+	}
+	return nil
+}
+
+// auditedExec runs a single-person update in a transaction with an audit event
+func (r *PersonRepository) auditedExec(ctx context.Context, action string, id uuid.UUID, query string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			_, err := tx.Exec(ctx, query, id)
+			return err
+		}, auditScope{action: action, entityType: model.AuditEntityPerson, where: "t.id = $1", args: []any{id}})
+	})
+}
+
+// ErrPersonsMismatch is returned when a reorder doesn't list every person exactly once
+var ErrPersonsMismatch = errors.New("reorder must list every person exactly once")
+
+// Reorder updates the display order of persons
+// personIDs should contain every person in the desired order (first = position 1)
+func (r *PersonRepository) Reorder(ctx context.Context, personIDs []uuid.UUID) error {
+	if len(personIDs) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("reorder persons begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(2, 0)"); err != nil {
+		return fmt.Errorf("reorder persons lock: %w", err)
+	}
+
+	var total, matching int
+	if err := tx.QueryRow(ctx, "SELECT COUNT(*), COUNT(*) FILTER (WHERE id = ANY($1::uuid[])) FROM persons", personIDs).Scan(&total, &matching); err != nil {
+		return fmt.Errorf("reorder persons count: %w", err)
+	}
+	if total != len(personIDs) || matching != len(personIDs) {
+		return fmt.Errorf("%w: %d persons exist, %d matched, request has %d", ErrPersonsMismatch, total, matching, len(personIDs))
+	}
+
+	positions := make([]int, len(personIDs))
+	for i := range personIDs {
+		positions[i] = i + 1
+	}
+
+	err = audited(ctx, tx, func() error {
+		// Move current positions out of the way to avoid unique constraint conflicts.
+		if _, err := tx.Exec(ctx, `UPDATE persons SET position = -position`); err != nil {
+			return fmt.Errorf("reorder persons temp positions: %w", err)
+		}
+
+		query := `
+			UPDATE persons AS p
+			SET position = v.position
+			FROM (
+				SELECT unnest($1::uuid[]) AS id, unnest($2::int[]) AS position
+			) AS v
+			WHERE p.id = v.id`
+		if _, err := tx.Exec(ctx, query, personIDs, positions); err != nil {
+			return fmt.Errorf("update person positions: %w", err)
+		}
+		return nil
+	}, auditScope{action: model.AuditActionReorder, entityType: model.AuditEntityPerson, where: "TRUE"})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("reorder persons commit: %w", err)
+	}
+
+	return nil
+}
+
+// GetPasswordHash returns the person's bcrypt password hash, or nil if none is set
+func (r *PersonRepository) GetPasswordHash(ctx context.Context, id uuid.UUID) (*string, error) {
+	query := `SELECT password_hash FROM persons WHERE id = $1`
+
+	var hash *string
+	err := r.pool.QueryRow(ctx, query, id).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get password hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// SetPasswordHash stores a new bcrypt password hash for the person
+func (r *PersonRepository) SetPasswordHash(ctx context.Context, id uuid.UUID, hash string) error {
+	query := `UPDATE persons SET password_hash = $2 WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id, hash)
+	if err != nil {
+		return fmt.Errorf("set password hash: %w", err)
+	}
+	return nil
+}
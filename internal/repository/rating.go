@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/drywaters/seenema/internal/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,28 +21,44 @@ func NewRatingRepository(pool *pgxpool.Pool) *RatingRepository {
 	return &RatingRepository{pool: pool}
 }
 
-// Upsert creates or updates a rating
+// Upsert creates or updates a rating. The caller checks that the viewing belongs to the entry.
 func (r *RatingRepository) Upsert(ctx context.Context, input model.UpsertRatingInput) (*model.Rating, error) {
 	query := `
-		INSERT INTO ratings (person_id, entry_id, score)
-		VALUES ($1, $2, $3)
+		INSERT INTO ratings (person_id, entry_id, score, viewing_id)
+		VALUES ($1, $2, $3, NULLIF($4::uuid, '00000000-0000-0000-0000-000000000000'::uuid))
 		ON CONFLICT (person_id, entry_id)
-		DO UPDATE SET score = $3, updated_at = NOW()
-		RETURNING id, person_id, entry_id, score, created_at, updated_at`
+		DO UPDATE SET score = $3,
+		              viewing_id = CASE
+		              	WHEN $4::uuid IS NULL THEN ratings.viewing_id
+		              	ELSE NULLIF($4::uuid, '00000000-0000-0000-0000-000000000000'::uuid)
+		              END,
+		              updated_at = NOW(),
+		              deleted_at = NULL
+		RETURNING id, person_id, entry_id, score, viewing_id, created_at, updated_at`
 
 	rating := &model.Rating{}
-	err := r.pool.QueryRow(ctx, query,
-		input.PersonID,
-		input.EntryID,
-		input.Score,
-	).Scan(
-		&rating.ID,
-		&rating.PersonID,
-		&rating.EntryID,
-		&rating.Score,
-		&rating.CreatedAt,
-		&rating.UpdatedAt,
-	)
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			err := tx.QueryRow(ctx, query,
+				input.PersonID,
+				input.EntryID,
+				input.Score,
+				input.ViewingID,
+			).Scan(
+				&rating.ID,
+				&rating.PersonID,
+				&rating.EntryID,
+				&rating.Score,
+				&rating.ViewingID,
+				&rating.CreatedAt,
+				&rating.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+			return addRatingRevision(ctx, tx, rating.ID, rating.Score)
+		}, auditScope{entityType: model.AuditEntityRating, where: "t.person_id = $1 AND t.entry_id = $2", args: []any{input.PersonID, input.EntryID}})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("upsert rating: %w", err)
 	}
@@ -48,15 +66,51 @@ func (r *RatingRepository) Upsert(ctx context.Context, input model.UpsertRatingI
 	return rating, nil
 }
 
-// GetByEntryID retrieves all ratings for an entry with person information
+// addRatingRevision records the rating's score unless it is the same as the latest revision
+func addRatingRevision(ctx context.Context, tx pgx.Tx, ratingID uuid.UUID, score float64) error {
+	query := `
+		INSERT INTO rating_revisions (rating_id, score)
+		SELECT $1::uuid, $2::numeric
+		WHERE $2::numeric IS DISTINCT FROM (
+			SELECT score FROM rating_revisions
+			WHERE rating_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		)`
+	if _, err := tx.Exec(ctx, query, ratingID, score); err != nil {
+		return fmt.Errorf("add rating revision: %w", err)
+	}
+	return nil
+}
+
+// ratingRevisionsJoin adds revision_scores and revision_times, the rating r's
+// history oldest first, to a ratings query
+const ratingRevisionsJoin = `
+		LEFT JOIN LATERAL (
+			SELECT array_agg(rv.score::float8 ORDER BY rv.created_at, rv.id) AS revision_scores,
+			       array_agg(rv.created_at ORDER BY rv.created_at, rv.id) AS revision_times
+			FROM rating_revisions rv
+			WHERE rv.rating_id = r.id
+		) rv ON TRUE`
+
+// ratingRevisions pairs up the columns selected through ratingRevisionsJoin
+func ratingRevisions(scores []float64, times []time.Time) []model.RatingRevision {
+	revisions := make([]model.RatingRevision, 0, len(scores))
+	for i := range min(len(scores), len(times)) {
+		revisions = append(revisions, model.RatingRevision{Score: scores[i], CreatedAt: times[i]})
+	}
+	return revisions
+}
+
+// GetByEntryID retrieves all ratings for an entry with person information and score history
 func (r *RatingRepository) GetByEntryID(ctx context.Context, entryID uuid.UUID) ([]*model.Rating, error) {
 	query := `
-		SELECT r.id, r.person_id, r.entry_id, r.score, r.created_at, r.updated_at,
-		       p.id, p.initial, p.name
+		SELECT r.id, r.person_id, r.entry_id, r.score, r.viewing_id, r.created_at, r.updated_at,
+		       p.id, p.initial, p.name, rv.revision_scores, rv.revision_times
 		FROM ratings r
-		JOIN persons p ON r.person_id = p.id
-		WHERE r.entry_id = $1
-		ORDER BY p.initial`
+		JOIN persons p ON r.person_id = p.id` + ratingRevisionsJoin + `
+		WHERE r.entry_id = $1 AND r.deleted_at IS NULL
+		ORDER BY p.position`
 
 	rows, err := r.pool.Query(ctx, query, entryID)
 	if err != nil {
@@ -68,20 +122,26 @@ func (r *RatingRepository) GetByEntryID(ctx context.Context, entryID uuid.UUID)
 	for rows.Next() {
 		rating := &model.Rating{}
 		person := &model.Person{}
+		var revisionScores []float64
+		var revisionTimes []time.Time
 		if err := rows.Scan(
 			&rating.ID,
 			&rating.PersonID,
 			&rating.EntryID,
 			&rating.Score,
+			&rating.ViewingID,
 			&rating.CreatedAt,
 			&rating.UpdatedAt,
 			&person.ID,
 			&person.Initial,
 			&person.Name,
+			&revisionScores,
+			&revisionTimes,
 		); err != nil {
 			return nil, fmt.Errorf("scan rating: %w", err)
 		}
 		rating.Person = person
+		rating.Revisions = ratingRevisions(revisionScores, revisionTimes)
 		ratings = append(ratings, rating)
 	}
 	if err := rows.Err(); err != nil {
@@ -91,10 +151,15 @@ func (r *RatingRepository) GetByEntryID(ctx context.Context, entryID uuid.UUID)
 	return ratings, nil
 }
 
-// Delete removes a rating
+// Delete moves a rating to the trash
 func (r *RatingRepository) Delete(ctx context.Context, personID, entryID uuid.UUID) error {
-	query := `DELETE FROM ratings WHERE person_id = $1 AND entry_id = $2`
-	_, err := r.pool.Exec(ctx, query, personID, entryID)
+	query := `UPDATE ratings SET deleted_at = NOW() WHERE person_id = $1 AND entry_id = $2 AND deleted_at IS NULL`
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			_, err := tx.Exec(ctx, query, personID, entryID)
+			return err
+		}, auditScope{action: model.AuditActionDelete, entityType: model.AuditEntityRating, where: "t.person_id = $1 AND t.entry_id = $2", args: []any{personID, entryID}})
+	})
 	if err != nil {
 		return fmt.Errorf("delete rating: %w", err)
 	}
@@ -103,7 +168,7 @@ func (r *RatingRepository) Delete(ctx context.Context, personID, entryID uuid.UU
 
 // GetAverageForEntry calculates the average rating for an entry
 func (r *RatingRepository) GetAverageForEntry(ctx context.Context, entryID uuid.UUID) (*float64, error) {
-	query := `SELECT AVG(score)::numeric(3,1) FROM ratings WHERE entry_id = $1`
+	query := `SELECT AVG(score)::numeric(3,1) FROM ratings WHERE entry_id = $1 AND deleted_at IS NULL`
 
 	var avg *float64
 	err := r.pool.QueryRow(ctx, query, entryID).Scan(&avg)
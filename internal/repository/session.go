@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SessionRepository handles database operations for login sessions
+type SessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{pool: pool}
+}
+
+// Create inserts a new session
+func (r *SessionRepository) Create(ctx context.Context, input model.CreateSessionInput) (*model.Session, error) {
+	query := `
+		INSERT INTO sessions (token_hash, person_id, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+		RETURNING id, person_id, created_at, expires_at, last_seen_at, user_agent, ip_address`
+
+	session := &model.Session{}
+	err := r.pool.QueryRow(ctx, query,
+		input.TokenHash,
+		input.PersonID,
+		input.ExpiresAt,
+		input.UserAgent,
+		input.IPAddress,
+	).Scan(
+		&session.ID,
+		&session.PersonID,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.LastSeenAt,
+		&session.UserAgent,
+		&session.IPAddress,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetByTokenHash retrieves an unexpired session for an active person, or nil if none matches
+func (r *SessionRepository) GetByTokenHash(ctx context.Context, tokenHash []byte) (*model.Session, error) {
+	query := `
+		SELECT s.id, s.person_id, s.created_at, s.expires_at, s.last_seen_at, s.user_agent, s.ip_address,
+		       p.id, p.initial, p.name, p.position, p.archived_at, p.created_at, p.password_hash IS NOT NULL
+		FROM sessions s
+		JOIN persons p ON s.person_id = p.id
+		WHERE s.token_hash = $1
+		  AND s.expires_at > NOW()
+		  AND p.archived_at IS NULL`
+
+	session := &model.Session{}
+	person := &model.Person{}
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&session.ID,
+		&session.PersonID,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+		&session.LastSeenAt,
+		&session.UserAgent,
+		&session.IPAddress,
+		&person.ID,
+		&person.Initial,
+		&person.Name,
+		&person.Position,
+		&person.ArchivedAt,
+		&person.CreatedAt,
+		&person.HasPassword,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get session by token: %w", err)
+	}
+	session.Person = person
+
+	return session, nil
+}
+
+// ListByPerson retrieves a person's unexpired sessions, most recently used first
+func (r *SessionRepository) ListByPerson(ctx context.Context, personID uuid.UUID) ([]*model.Session, error) {
+	query := `
+		SELECT id, person_id, created_at, expires_at, last_seen_at, user_agent, ip_address
+		FROM sessions
+		WHERE person_id = $1 AND expires_at > NOW()
+		ORDER BY last_seen_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, personID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions by person: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*model.Session
+	for rows.Next() {
+		session := &model.Session{}
+		if err := rows.Scan(
+			&session.ID,
+			&session.PersonID,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+			&session.LastSeenAt,
+			&session.UserAgent,
+			&session.IPAddress,
+		); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Touch records that the session was just used
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE sessions SET last_seen_at = NOW() WHERE id = $1`
+	_, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("touch session: %w", err)
+	}
+	return nil
+}
+
+// DeleteByTokenHash removes the session with the given token hash
+func (r *SessionRepository) DeleteByTokenHash(ctx context.Context, tokenHash []byte) error {
+	query := `DELETE FROM sessions WHERE token_hash = $1`
+	_, err := r.pool.Exec(ctx, query, tokenHash)
+	if err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteByID removes one of a person's sessions, returning false if it was not found
+func (r *SessionRepository) DeleteByID(ctx context.Context, personID, id uuid.UUID) (bool, error) {
+	query := `DELETE FROM sessions WHERE id = $1 AND person_id = $2`
+	tag, err := r.pool.Exec(ctx, query, id, personID)
+	if err != nil {
+		return false, fmt.Errorf("delete session by id: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteByPerson removes every session for a person (sign out all devices)
+func (r *SessionRepository) DeleteByPerson(ctx context.Context, personID uuid.UUID) (int64, error) {
+	query := `DELETE FROM sessions WHERE person_id = $1`
+	tag, err := r.pool.Exec(ctx, query, personID)
+	if err != nil {
+		return 0, fmt.Errorf("delete sessions by person: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteOthers removes every session for a person except keepID, returning how many were removed
+func (r *SessionRepository) DeleteOthers(ctx context.Context, personID, keepID uuid.UUID) (int64, error) {
+	query := `DELETE FROM sessions WHERE person_id = $1 AND id <> $2`
+	tag, err := r.pool.Exec(ctx, query, personID, keepID)
+	if err != nil {
+		return 0, fmt.Errorf("delete other sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpired removes sessions past their expiry and returns how many were removed
+func (r *SessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at <= NOW()`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
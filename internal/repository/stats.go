@@ -0,0 +1,404 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// divisiveEntryLimit is how many of the most divisive entries GetStats returns
+const divisiveEntryLimit = 10
+
+// StatsRepository computes watch-history statistics with aggregate queries
+type StatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewStatsRepository creates a new StatsRepository
+func NewStatsRepository(pool *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{pool: pool}
+}
+
+// GetStats returns watch counts, time watched and rating statistics
+func (r *StatsRepository) GetStats(ctx context.Context) (*model.Stats, error) {
+	stats := &model.Stats{}
+
+	// Rewatches add to the time watched but not to the number of movies
+	totalsQuery := `
+		SELECT COUNT(DISTINCT e.id), COALESCE(SUM(m.runtime_minutes), 0)
+		FROM viewings v
+		JOIN entries e ON v.entry_id = e.id AND e.deleted_at IS NULL
+		JOIN movies m ON e.movie_id = m.id`
+	if err := r.pool.QueryRow(ctx, totalsQuery).Scan(&stats.WatchedCount, &stats.WatchedMinutes); err != nil {
+		return nil, fmt.Errorf("get watched totals: %w", err)
+	}
+
+	var err error
+	if stats.WatchedByMonth, err = r.watchedPer(ctx, "YYYY-MM"); err != nil {
+		return nil, err
+	}
+	if stats.WatchedByYear, err = r.watchedPer(ctx, "YYYY"); err != nil {
+		return nil, err
+	}
+	if stats.PersonAverages, err = r.personAverages(ctx); err != nil {
+		return nil, err
+	}
+	if stats.Attendance, err = r.attendance(ctx); err != nil {
+		return nil, err
+	}
+	if stats.PickerScores, err = r.pickerScores(ctx); err != nil {
+		return nil, err
+	}
+	if stats.DivisiveEntries, err = r.divisiveEntries(ctx); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// watchedPer counts viewings per period, formatted with a to_char pattern, so
+// a rewatch counts in the period it happened
+func (r *StatsRepository) watchedPer(ctx context.Context, format string) ([]model.PeriodCount, error) {
+	query := `
+		SELECT to_char(v.watched_on, $1) AS period, COUNT(*)
+		FROM viewings v
+		JOIN entries e ON v.entry_id = e.id AND e.deleted_at IS NULL
+		GROUP BY period
+		ORDER BY period`
+
+	rows, err := r.pool.Query(ctx, query, format)
+	if err != nil {
+		return nil, fmt.Errorf("count watched per period: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []model.PeriodCount{}
+	for rows.Next() {
+		var count model.PeriodCount
+		if err := rows.Scan(&count.Period, &count.Count); err != nil {
+			return nil, fmt.Errorf("scan period count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate period counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// countedRatings selects the ratings stats are computed from: live ratings of
+// live entries by people who watched them
+const countedRatings = `
+	SELECT r.id, r.entry_id, r.person_id, r.score
+	FROM ratings r
+	JOIN entries e ON r.entry_id = e.id AND e.deleted_at IS NULL
+	WHERE r.deleted_at IS NULL AND ` + attendedRating
+
+// personAverages returns each person's average score, in display order
+func (r *StatsRepository) personAverages(ctx context.Context) ([]*model.PersonAverage, error) {
+	query := `
+		WITH counted AS (` + countedRatings + `)
+		SELECT p.id, p.initial, p.name, COUNT(r.id), AVG(r.score)::float8
+		FROM persons p
+		JOIN counted r ON r.person_id = p.id
+		GROUP BY p.id
+		ORDER BY p.position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get person averages: %w", err)
+	}
+	defer rows.Close()
+
+	averages := []*model.PersonAverage{}
+	for rows.Next() {
+		average := &model.PersonAverage{Person: &model.Person{}}
+		if err := rows.Scan(
+			&average.Person.ID,
+			&average.Person.Initial,
+			&average.Person.Name,
+			&average.RatingCount,
+			&average.AverageScore,
+		); err != nil {
+			return nil, fmt.Errorf("scan person average: %w", err)
+		}
+		averages = append(averages, average)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate person averages: %w", err)
+	}
+
+	return averages, nil
+}
+
+// attendance counts, for each active person, the watched entries they were
+// present for or missed, and how many of those they watched they rated
+func (r *StatsRepository) attendance(ctx context.Context) ([]*model.Attendance, error) {
+	query := `
+		SELECT p.id, p.initial, p.name,
+		       COUNT(*) FILTER (WHERE a.attended),
+		       COUNT(*) FILTER (WHERE NOT a.attended),
+		       COUNT(*) FILTER (WHERE a.attended AND r.id IS NOT NULL),
+		       COUNT(*) FILTER (WHERE a.attended AND r.id IS NULL)
+		FROM persons p
+		CROSS JOIN entries e
+		CROSS JOIN LATERAL (
+			SELECT EXISTS (
+				SELECT 1 FROM viewings v JOIN viewing_attendees va ON va.viewing_id = v.id
+				WHERE v.entry_id = e.id AND va.person_id = p.id
+			) AS attended
+		) a
+		LEFT JOIN ratings r ON r.entry_id = e.id AND r.person_id = p.id AND r.deleted_at IS NULL
+		WHERE p.archived_at IS NULL AND e.watched_at IS NOT NULL AND e.deleted_at IS NULL
+		GROUP BY p.id
+		ORDER BY p.position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get attendance: %w", err)
+	}
+	defer rows.Close()
+
+	attendance := []*model.Attendance{}
+	for rows.Next() {
+		a := &model.Attendance{Person: &model.Person{}}
+		if err := rows.Scan(
+			&a.Person.ID,
+			&a.Person.Initial,
+			&a.Person.Name,
+			&a.WatchedCount,
+			&a.MissedCount,
+			&a.RatedCount,
+			&a.UnratedCount,
+		); err != nil {
+			return nil, fmt.Errorf("scan attendance: %w", err)
+		}
+		attendance = append(attendance, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attendance: %w", err)
+	}
+
+	return attendance, nil
+}
+
+// pickerScores ranks pickers by the average rating of the entries they picked
+func (r *StatsRepository) pickerScores(ctx context.Context) ([]*model.PickerScore, error) {
+	query := `
+		SELECT p.id, p.initial, p.name, COUNT(DISTINCT e.id), AVG(r.score)::float8
+		FROM entries e
+		JOIN persons p ON e.picked_by_person_id = p.id
+		JOIN ratings r ON r.entry_id = e.id AND r.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL AND ` + attendedRating + `
+		GROUP BY p.id
+		ORDER BY AVG(r.score) DESC, p.position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get picker scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := []*model.PickerScore{}
+	for rows.Next() {
+		score := &model.PickerScore{Person: &model.Person{}}
+		if err := rows.Scan(
+			&score.Person.ID,
+			&score.Person.Initial,
+			&score.Person.Name,
+			&score.PickCount,
+			&score.AverageScore,
+		); err != nil {
+			return nil, fmt.Errorf("scan picker score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate picker scores: %w", err)
+	}
+
+	return scores, nil
+}
+
+// divisiveEntries returns the entries with the highest score variance,
+// considering only entries rated by at least two people
+func (r *StatsRepository) divisiveEntries(ctx context.Context) ([]*model.DivisiveEntry, error) {
+	query := `
+		SELECT e.id, m.title, m.release_year, COUNT(r.id), AVG(r.score)::float8, VAR_POP(r.score)::float8 AS variance
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id
+		JOIN ratings r ON r.entry_id = e.id AND r.deleted_at IS NULL
+		WHERE e.deleted_at IS NULL AND ` + attendedRating + `
+		GROUP BY e.id, m.id
+		HAVING COUNT(r.id) >= 2
+		ORDER BY variance DESC, m.title
+		LIMIT $1`
+
+	rows, err := r.pool.Query(ctx, query, divisiveEntryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("get divisive entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*model.DivisiveEntry{}
+	for rows.Next() {
+		entry := &model.DivisiveEntry{}
+		if err := rows.Scan(
+			&entry.EntryID,
+			&entry.Title,
+			&entry.ReleaseYear,
+			&entry.RatingCount,
+			&entry.AverageScore,
+			&entry.Variance,
+		); err != nil {
+			return nil, fmt.Errorf("scan divisive entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate divisive entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// agreementThreshold is the largest score difference still counted as agreeing
+const agreementThreshold = 1.0
+
+// GetTasteComparison compares active persons' ratings pairwise and against the family average
+func (r *StatsRepository) GetTasteComparison(ctx context.Context) (*model.TasteComparison, error) {
+	comparison := &model.TasteComparison{AgreementThreshold: agreementThreshold}
+
+	var err error
+	if comparison.Persons, err = r.ratingPersons(ctx); err != nil {
+		return nil, err
+	}
+	if comparison.Pairs, err = r.personPairs(ctx); err != nil {
+		return nil, err
+	}
+	if comparison.Biases, err = r.personBiases(ctx); err != nil {
+		return nil, err
+	}
+
+	return comparison, nil
+}
+
+// ratingPersons returns active persons in display order
+func (r *StatsRepository) ratingPersons(ctx context.Context) ([]*model.Person, error) {
+	query := `
+		SELECT id, initial, name
+		FROM persons
+		WHERE archived_at IS NULL
+		ORDER BY position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list rating persons: %w", err)
+	}
+	defer rows.Close()
+
+	persons := []*model.Person{}
+	for rows.Next() {
+		person := &model.Person{}
+		if err := rows.Scan(&person.ID, &person.Initial, &person.Name); err != nil {
+			return nil, fmt.Errorf("scan rating person: %w", err)
+		}
+		persons = append(persons, person)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rating persons: %w", err)
+	}
+
+	return persons, nil
+}
+
+// personPairs compares every pair of active persons on the entries both rated
+func (r *StatsRepository) personPairs(ctx context.Context) ([]*model.PersonPair, error) {
+	query := `
+		WITH counted AS (` + countedRatings + `)
+		SELECT a.person_id, b.person_id,
+		       COUNT(*),
+		       CORR(a.score, b.score)::float8,
+		       AVG(ABS(a.score - b.score))::float8,
+		       AVG(CASE WHEN ABS(a.score - b.score) <= $1 THEN 1 ELSE 0 END)::float8
+		FROM counted a
+		JOIN counted b ON a.entry_id = b.entry_id AND a.person_id < b.person_id
+		JOIN persons pa ON a.person_id = pa.id AND pa.archived_at IS NULL
+		JOIN persons pb ON b.person_id = pb.id AND pb.archived_at IS NULL
+		GROUP BY a.person_id, b.person_id`
+
+	rows, err := r.pool.Query(ctx, query, agreementThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("compare person pairs: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := []*model.PersonPair{}
+	for rows.Next() {
+		pair := &model.PersonPair{}
+		if err := rows.Scan(
+			&pair.PersonAID,
+			&pair.PersonBID,
+			&pair.SharedCount,
+			&pair.Correlation,
+			&pair.MeanAbsoluteDiff,
+			&pair.AgreementRate,
+		); err != nil {
+			return nil, fmt.Errorf("scan person pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate person pairs: %w", err)
+	}
+
+	return pairs, nil
+}
+
+// personBiases returns how far each active person's scores sit from the
+// average score of the same entries, for entries at least two people rated
+func (r *StatsRepository) personBiases(ctx context.Context) ([]*model.PersonBias, error) {
+	query := `
+		WITH counted AS (` + countedRatings + `),
+		entry_averages AS (
+			SELECT entry_id, AVG(score) AS average
+			FROM counted
+			GROUP BY entry_id
+			HAVING COUNT(*) >= 2
+		)
+		SELECT p.id, p.initial, p.name, COUNT(*), AVG(r.score - ea.average)::float8
+		FROM counted r
+		JOIN entry_averages ea ON r.entry_id = ea.entry_id
+		JOIN persons p ON r.person_id = p.id AND p.archived_at IS NULL
+		GROUP BY p.id
+		ORDER BY p.position`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("get person biases: %w", err)
+	}
+	defer rows.Close()
+
+	biases := []*model.PersonBias{}
+	for rows.Next() {
+		bias := &model.PersonBias{Person: &model.Person{}}
+		if err := rows.Scan(
+			&bias.Person.ID,
+			&bias.Person.Initial,
+			&bias.Person.Name,
+			&bias.RatingCount,
+			&bias.Bias,
+		); err != nil {
+			return nil, fmt.Errorf("scan person bias: %w", err)
+		}
+		biases = append(biases, bias)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate person biases: %w", err)
+	}
+
+	return biases, nil
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TrashRepository lists, restores and purges soft-deleted movies, entries and ratings.
+// Rows deleted together share a deleted_at, which is how a restore finds them again.
+type TrashRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTrashRepository creates a new TrashRepository
+func NewTrashRepository(pool *pgxpool.Pool) *TrashRepository {
+	return &TrashRepository{pool: pool}
+}
+
+// List returns the trash, most recently deleted first. Items only appear once
+// whatever they belong to is active again, so a movie's entries are restored
+// with it rather than listed separately. With a positive retention, PurgeAt is
+// set to when the purge job will remove each item.
+func (r *TrashRepository) List(ctx context.Context, retention time.Duration) ([]*model.TrashItem, error) {
+	query := `
+		SELECT 'movie', m.id, m.title, NULL::uuid, NULL::int, NULL::uuid, NULL::text, NULL::text, NULL::float8,
+		       (SELECT COUNT(*) FROM ratings r JOIN entries e ON r.entry_id = e.id
+		        WHERE e.movie_id = m.id AND r.deleted_at = m.deleted_at),
+		       m.deleted_at
+		FROM movies m
+		WHERE m.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'entry', m.id, m.title, e.id, e.group_number, NULL, NULL, NULL, NULL,
+		       (SELECT COUNT(*) FROM ratings r WHERE r.entry_id = e.id AND r.deleted_at = e.deleted_at),
+		       e.deleted_at
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id AND m.deleted_at IS NULL
+		WHERE e.deleted_at IS NOT NULL
+		UNION ALL
+		SELECT 'rating', m.id, m.title, e.id, e.group_number, p.id, p.initial, p.name, r.score::float8,
+		       0,
+		       r.deleted_at
+		FROM ratings r
+		JOIN entries e ON r.entry_id = e.id AND e.deleted_at IS NULL
+		JOIN movies m ON e.movie_id = m.id AND m.deleted_at IS NULL
+		JOIN persons p ON r.person_id = p.id
+		WHERE r.deleted_at IS NOT NULL
+		ORDER BY 11 DESC`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*model.TrashItem{}
+	for rows.Next() {
+		item := &model.TrashItem{}
+		var personID *uuid.UUID
+		var personInitial, personName *string
+		if err := rows.Scan(
+			&item.Kind,
+			&item.MovieID,
+			&item.MovieTitle,
+			&item.EntryID,
+			&item.GroupNumber,
+			&personID,
+			&personInitial,
+			&personName,
+			&item.Score,
+			&item.RatingCount,
+			&item.DeletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan trash item: %w", err)
+		}
+		if personID != nil && personInitial != nil && personName != nil {
+			item.Person = &model.Person{ID: *personID, Initial: *personInitial, Name: *personName}
+		}
+		if retention > 0 {
+			purgeAt := item.DeletedAt.Add(retention)
+			item.PurgeAt = &purgeAt
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate trash: %w", err)
+	}
+
+	return items, nil
+}
+
+// RestoreEntry brings back a deleted entry and the ratings deleted with it. It
+// keeps its old position if that is still free and goes last otherwise.
+// Returns false if the entry is not in the trash or its movie still is.
+func (r *TrashRepository) RestoreEntry(ctx context.Context, id uuid.UUID) (bool, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("restore entry begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT e.deleted_at
+		FROM entries e
+		JOIN movies m ON e.movie_id = m.id AND m.deleted_at IS NULL
+		WHERE e.id = $1 AND e.deleted_at IS NOT NULL`,
+		id,
+	).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("restore entry: %w", err)
+	}
+
+	found, err := restoreEntry(ctx, tx, id, deletedAt)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("restore entry commit: %w", err)
+	}
+	return true, nil
+}
+
+// RestoreRating brings back a person's deleted rating of an active entry.
+// Returns false if there is no such rating in the trash.
+func (r *TrashRepository) RestoreRating(ctx context.Context, personID, entryID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE ratings r
+		SET deleted_at = NULL
+		FROM entries e
+		WHERE r.entry_id = e.id
+		  AND r.person_id = $1 AND r.entry_id = $2
+		  AND r.deleted_at IS NOT NULL AND e.deleted_at IS NULL`
+	var restored bool
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			tag, err := tx.Exec(ctx, query, personID, entryID)
+			restored = tag.RowsAffected() > 0
+			return err
+		}, auditScope{action: model.AuditActionRestore, entityType: model.AuditEntityRating, where: "t.person_id = $1 AND t.entry_id = $2", args: []any{personID, entryID}})
+	})
+	if err != nil {
+		return false, fmt.Errorf("restore rating: %w", err)
+	}
+	return restored, nil
+}
+
+// RestoreMovie brings back a deleted movie with the entries and ratings deleted
+// along with it. Returns false if the movie is not in the trash.
+func (r *TrashRepository) RestoreMovie(ctx context.Context, id uuid.UUID) (bool, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("restore movie begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var deletedAt time.Time
+	err = tx.QueryRow(ctx, `SELECT deleted_at FROM movies WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("restore movie: %w", err)
+	}
+
+	err = audited(ctx, tx, func() error {
+		_, err := tx.Exec(ctx, `UPDATE movies SET deleted_at = NULL WHERE id = $1`, id)
+		return err
+	}, auditScope{action: model.AuditActionRestore, entityType: model.AuditEntityMovie, where: "t.id = $1", args: []any{id}})
+	if err != nil {
+		return false, fmt.Errorf("restore movie: %w", err)
+	}
+
+	// Restore in group order so the group locks are always taken in ascending order
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM entries
+		WHERE movie_id = $1 AND deleted_at = $2
+		ORDER BY group_number`,
+		id,
+		deletedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("restore movie list entries: %w", err)
+	}
+	entryIDs, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return false, fmt.Errorf("restore movie list entries: %w", err)
+	}
+
+	for _, entryID := range entryIDs {
+		if _, err := restoreEntry(ctx, tx, entryID, deletedAt); err != nil {
+			return false, fmt.Errorf("restore movie: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("restore movie commit: %w", err)
+	}
+	return true, nil
+}
+
+// restoreEntry clears an entry's deleted_at and that of its ratings deleted at
+// the same time, reporting false if another request restored it first
+func restoreEntry(ctx context.Context, tx pgx.Tx, id uuid.UUID, deletedAt time.Time) (bool, error) {
+	var groupNumber int
+	if err := tx.QueryRow(ctx, `SELECT group_number FROM entries WHERE id = $1`, id).Scan(&groupNumber); err != nil {
+		return false, fmt.Errorf("restore entry get group: %w", err)
+	}
+	if err := lockGroups(ctx, tx, groupNumber, groupNumber); err != nil {
+		return false, fmt.Errorf("restore entry: %w", err)
+	}
+
+	var restored bool
+	err := audited(ctx, tx, func() error {
+		// A gap left by the deletion is harmless; a taken position sends the entry to the end
+		tag, err := tx.Exec(ctx, `
+			UPDATE entries e
+			SET deleted_at = NULL,
+			    position = CASE
+			    	WHEN EXISTS (
+			    		SELECT 1 FROM entries o
+			    		WHERE o.group_number = e.group_number AND o.position = e.position AND o.deleted_at IS NULL
+			    	)
+			    	THEN (SELECT COALESCE(MAX(o.position), 0) + 1 FROM entries o WHERE o.group_number = e.group_number AND o.deleted_at IS NULL)
+			    	ELSE e.position
+			    END
+			WHERE e.id = $1 AND e.deleted_at = $2`,
+			id,
+			deletedAt,
+		)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		restored = true
+
+		if _, err := tx.Exec(ctx, `UPDATE ratings SET deleted_at = NULL WHERE entry_id = $1 AND deleted_at = $2`, id, deletedAt); err != nil {
+			return fmt.Errorf("restore entry ratings: %w", err)
+		}
+		return nil
+	},
+		auditScope{action: model.AuditActionRestore, entityType: model.AuditEntityEntry, where: "t.id = $1", args: []any{id}},
+		auditScope{action: model.AuditActionRestore, entityType: model.AuditEntityRating, where: "t.entry_id = $1", args: []any{id}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("restore entry: %w", err)
+	}
+	return restored, nil
+}
+
+// Purge permanently removes everything deleted before the cutoff and returns
+// how many movies, entries and ratings were removed. Rows belonging to a purged
+// movie or entry go with it through ON DELETE CASCADE.
+func (r *TrashRepository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("purge trash begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var purged int64
+	for _, table := range []string{"movies", "entries", "ratings"} {
+		tag, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE deleted_at < $1`, before)
+		if err != nil {
+			return 0, fmt.Errorf("purge trashed %s: %w", table, err)
+		}
+		purged += tag.RowsAffected()
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("purge trash commit: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ViewingRepository handles database operations for viewings
+type ViewingRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewViewingRepository creates a new ViewingRepository
+func NewViewingRepository(pool *pgxpool.Pool) *ViewingRepository {
+	return &ViewingRepository{pool: pool}
+}
+
+// Create records a viewing of an active entry and returns it with its
+// attendees. Unknown attendee IDs are ignored. Returns nil if the entry
+// doesn't exist.
+func (r *ViewingRepository) Create(ctx context.Context, input model.CreateViewingInput) (*model.Viewing, error) {
+	var viewingID uuid.UUID
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			query := `
+				INSERT INTO viewings (entry_id, watched_on, location, note)
+				SELECT id, $2, $3, $4 FROM entries WHERE id = $1 AND deleted_at IS NULL
+				RETURNING id`
+			err := tx.QueryRow(ctx, query, input.EntryID, input.WatchedOn, input.Location, input.Note).Scan(&viewingID)
+			if err != nil {
+				return err
+			}
+
+			query = `
+				INSERT INTO viewing_attendees (viewing_id, person_id)
+				SELECT $1, id FROM persons WHERE id = ANY($2::uuid[])`
+			if _, err := tx.Exec(ctx, query, viewingID, input.AttendeeIDs); err != nil {
+				return fmt.Errorf("add attendees: %w", err)
+			}
+
+			return syncWatchedAt(ctx, tx, input.EntryID)
+		}, viewingScope(input.EntryID))
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("create viewing: %w", err)
+	}
+
+	return r.GetByID(ctx, viewingID)
+}
+
+// GetByID retrieves a viewing with its attendees
+func (r *ViewingRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Viewing, error) {
+	viewings, err := listViewings(ctx, r.pool, "v.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(viewings) == 0 {
+		return nil, nil
+	}
+	return viewings[0], nil
+}
+
+// ListForEntry returns an entry's viewings with their attendees, oldest first
+func (r *ViewingRepository) ListForEntry(ctx context.Context, entryID uuid.UUID) ([]*model.Viewing, error) {
+	return listViewings(ctx, r.pool, "v.entry_id = $1", entryID)
+}
+
+// Delete removes one of an entry's viewings. Ratings given after it stay but
+// are no longer attached to a viewing. Returns false if there is no such viewing.
+func (r *ViewingRepository) Delete(ctx context.Context, entryID, id uuid.UUID) (bool, error) {
+	var found bool
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			tag, err := tx.Exec(ctx, `DELETE FROM viewings WHERE id = $1 AND entry_id = $2`, id, entryID)
+			if err != nil || tag.RowsAffected() == 0 {
+				return err
+			}
+			found = true
+			return syncWatchedAt(ctx, tx, entryID)
+		}, viewingScope(entryID))
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete viewing: %w", err)
+	}
+	return found, nil
+}
+
+// DeleteLatest removes an entry's most recent viewing, undoing the last time
+// it was marked watched while keeping earlier ones. Returns false if the
+// entry has no viewings.
+func (r *ViewingRepository) DeleteLatest(ctx context.Context, entryID uuid.UUID) (bool, error) {
+	var found bool
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			query := `
+				DELETE FROM viewings WHERE id = (
+					SELECT id FROM viewings
+					WHERE entry_id = $1
+					ORDER BY watched_on DESC, created_at DESC, id DESC
+					LIMIT 1
+				)`
+			tag, err := tx.Exec(ctx, query, entryID)
+			if err != nil || tag.RowsAffected() == 0 {
+				return err
+			}
+			found = true
+			return syncWatchedAt(ctx, tx, entryID)
+		}, viewingScope(entryID))
+	})
+	if err != nil {
+		return false, fmt.Errorf("delete latest viewing: %w", err)
+	}
+	return found, nil
+}
+
+// AddAttendee records that a person was at one of an entry's viewings.
+// Returns false if the entry has no such viewing.
+func (r *ViewingRepository) AddAttendee(ctx context.Context, entryID, id, personID uuid.UUID) (bool, error) {
+	var found bool
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			query := `SELECT EXISTS (SELECT 1 FROM viewings WHERE id = $1 AND entry_id = $2)`
+			if err := tx.QueryRow(ctx, query, id, entryID).Scan(&found); err != nil || !found {
+				return err
+			}
+			query = `
+				INSERT INTO viewing_attendees (viewing_id, person_id)
+				VALUES ($1, $2)
+				ON CONFLICT DO NOTHING`
+			_, err := tx.Exec(ctx, query, id, personID)
+			return err
+		}, viewingScope(entryID))
+	})
+	if err != nil {
+		return false, fmt.Errorf("add attendee: %w", err)
+	}
+	return found, nil
+}
+
+// attendedRating limits ratings r of entry e to the people who watched it, as
+// model.Entry.Attended does
+const attendedRating = `(e.watched_at IS NULL OR EXISTS (
+	SELECT 1 FROM viewings v JOIN viewing_attendees va ON va.viewing_id = v.id
+	WHERE v.entry_id = e.id AND va.person_id = r.person_id))`
+
+// SetAttendance records whether a person watched an entry. Marking them present
+// adds them to the latest viewing unless they attended one already; marking
+// them absent removes them from every viewing. Returns false if the entry has
+// no viewings.
+func (r *ViewingRepository) SetAttendance(ctx context.Context, entryID, personID uuid.UUID, present bool) (bool, error) {
+	var found bool
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		return audited(ctx, tx, func() error {
+			var latestID uuid.UUID
+			query := `
+				SELECT id FROM viewings
+				WHERE entry_id = $1
+				ORDER BY watched_on DESC, created_at DESC, id DESC
+				LIMIT 1`
+			if err := tx.QueryRow(ctx, query, entryID).Scan(&latestID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil
+				}
+				return err
+			}
+			found = true
+
+			if present {
+				query = `
+					INSERT INTO viewing_attendees (viewing_id, person_id)
+					SELECT $1, p.id FROM persons p
+					WHERE p.id = $2 AND NOT EXISTS (
+						SELECT 1 FROM viewing_attendees va JOIN viewings v ON v.id = va.viewing_id
+						WHERE v.entry_id = $3 AND va.person_id = p.id
+					)`
+				_, err := tx.Exec(ctx, query, latestID, personID, entryID)
+				return err
+			}
+
+			query = `
+				DELETE FROM viewing_attendees va
+				USING viewings v
+				WHERE va.viewing_id = v.id AND v.entry_id = $1 AND va.person_id = $2`
+			_, err := tx.Exec(ctx, query, entryID, personID)
+			return err
+		}, viewingScope(entryID))
+	})
+	if err != nil {
+		return false, fmt.Errorf("set attendance: %w", err)
+	}
+	return found, nil
+}
+
+// viewingScope covers every viewing of an entry for auditing
+func viewingScope(entryID uuid.UUID) auditScope {
+	return auditScope{entityType: model.AuditEntityViewing, where: "t.entry_id = $1", args: []any{entryID}}
+}
+
+// syncWatchedAt sets an entry's watched_at to its first viewing, or NULL if it has none
+func syncWatchedAt(ctx context.Context, tx pgx.Tx, entryID uuid.UUID) error {
+	query := `UPDATE entries SET watched_at = (SELECT MIN(watched_on) FROM viewings WHERE entry_id = $1) WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, entryID); err != nil {
+		return fmt.Errorf("sync watched date: %w", err)
+	}
+	return nil
+}
+
+// listViewings returns the viewings matching a condition on v, oldest first,
+// with their attendees in display order
+func listViewings(ctx context.Context, pool *pgxpool.Pool, where string, args ...any) ([]*model.Viewing, error) {
+	query := `
+		SELECT v.id, v.entry_id, v.watched_on, v.location, v.note, v.created_at,
+		       p.id, p.initial, p.name
+		FROM viewings v
+		LEFT JOIN viewing_attendees va ON va.viewing_id = v.id
+		LEFT JOIN persons p ON p.id = va.person_id
+		WHERE ` + where + `
+		ORDER BY v.watched_on, v.created_at, v.id, p.position`
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list viewings: %w", err)
+	}
+	defer rows.Close()
+
+	viewings := []*model.Viewing{}
+	var current *model.Viewing
+	for rows.Next() {
+		viewing := &model.Viewing{Attendees: []*model.Person{}}
+		var personID *uuid.UUID
+		var initial, name *string
+		if err := rows.Scan(
+			&viewing.ID,
+			&viewing.EntryID,
+			&viewing.WatchedOn,
+			&viewing.Location,
+			&viewing.Note,
+			&viewing.CreatedAt,
+			&personID,
+			&initial,
+			&name,
+		); err != nil {
+			return nil, fmt.Errorf("scan viewing: %w", err)
+		}
+		if current == nil || current.ID != viewing.ID {
+			current = viewing
+			viewings = append(viewings, current)
+		}
+		if personID != nil && initial != nil && name != nil {
+			current.Attendees = append(current.Attendees, &model.Person{ID: *personID, Initial: *initial, Name: *name})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate viewing rows: %w", err)
+	}
+
+	return viewings, nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WatchlistRepository handles database operations for the family watchlist
+type WatchlistRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewWatchlistRepository creates a new WatchlistRepository
+func NewWatchlistRepository(pool *pgxpool.Pool) *WatchlistRepository {
+	return &WatchlistRepository{pool: pool}
+}
+
+// List returns the suggestions still on the watchlist, most upvoted first,
+// then oldest first
+func (r *WatchlistRepository) List(ctx context.Context) ([]*model.Suggestion, error) {
+	return r.list(ctx, "s.promoted_at IS NULL")
+}
+
+// GetByID retrieves a suggestion, promoted or not. Returns nil if not found.
+func (r *WatchlistRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Suggestion, error) {
+	suggestions, err := r.list(ctx, "s.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
+		return nil, nil
+	}
+	return suggestions[0], nil
+}
+
+// Create puts a movie on the watchlist. Fails with a unique violation if the
+// movie is already on it.
+func (r *WatchlistRepository) Create(ctx context.Context, input model.CreateSuggestionInput) (*model.Suggestion, error) {
+	query := `
+		INSERT INTO watchlist_suggestions (movie_id, suggested_by_person_id, reason)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+
+	var id uuid.UUID
+	if err := r.pool.QueryRow(ctx, query, input.MovieID, input.SuggestedByPersonID, input.Reason).Scan(&id); err != nil {
+		return nil, fmt.Errorf("create suggestion: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Delete takes a suggestion off the watchlist. Returns false if it isn't on it.
+func (r *WatchlistRepository) Delete(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM watchlist_suggestions WHERE id = $1 AND promoted_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("delete suggestion: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Vote upvotes a suggestion on behalf of an active person. Voting twice is a
+// no-op. Returns false if the suggestion isn't on the watchlist.
+func (r *WatchlistRepository) Vote(ctx context.Context, id, personID uuid.UUID) (bool, error) {
+	query := `
+		INSERT INTO watchlist_votes (suggestion_id, person_id)
+		SELECT s.id, p.id
+		FROM watchlist_suggestions s, persons p
+		WHERE s.id = $1 AND s.promoted_at IS NULL AND p.id = $2 AND p.archived_at IS NULL
+		ON CONFLICT DO NOTHING`
+	if _, err := r.pool.Exec(ctx, query, id, personID); err != nil {
+		return false, fmt.Errorf("vote for suggestion: %w", err)
+	}
+	return r.isOpen(ctx, id)
+}
+
+// Unvote withdraws a person's upvote. Returns false if the suggestion isn't on the watchlist.
+func (r *WatchlistRepository) Unvote(ctx context.Context, id, personID uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM watchlist_votes v
+		USING watchlist_suggestions s
+		WHERE v.suggestion_id = s.id AND s.id = $1 AND s.promoted_at IS NULL AND v.person_id = $2`
+	if _, err := r.pool.Exec(ctx, query, id, personID); err != nil {
+		return false, fmt.Errorf("withdraw vote: %w", err)
+	}
+	return r.isOpen(ctx, id)
+}
+
+// MarkPromoted takes a suggestion off the watchlist, recording the entry it
+// became. Returns false if it had already left the watchlist.
+func (r *WatchlistRepository) MarkPromoted(ctx context.Context, id, entryID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE watchlist_suggestions
+		SET promoted_at = NOW(), promoted_entry_id = $2
+		WHERE id = $1 AND promoted_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, id, entryID)
+	if err != nil {
+		return false, fmt.Errorf("mark suggestion promoted: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// isOpen reports whether a suggestion is still on the watchlist
+func (r *WatchlistRepository) isOpen(ctx context.Context, id uuid.UUID) (bool, error) {
+	var open bool
+	query := `SELECT EXISTS (SELECT 1 FROM watchlist_suggestions WHERE id = $1 AND promoted_at IS NULL)`
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&open); err != nil {
+		return false, fmt.Errorf("check suggestion: %w", err)
+	}
+	return open, nil
+}
+
+// list returns the suggestions matching a condition on s with their movie,
+// suggester and voters. Suggestions of deleted movies are left out.
+func (r *WatchlistRepository) list(ctx context.Context, where string, args ...any) ([]*model.Suggestion, error) {
+	query := `
+		SELECT s.id, s.movie_id, s.suggested_by_person_id, s.reason, s.created_at, s.promoted_at, s.promoted_entry_id,
+		       m.id, m.created_at, m.updated_at, m.title, m.release_year, m.poster_url, m.synopsis, m.runtime_minutes, m.tmdb_id, m.imdb_id, m.metadata_json,
+		       p.id, p.initial, p.name
+		FROM watchlist_suggestions s
+		JOIN movies m ON s.movie_id = m.id AND m.deleted_at IS NULL
+		LEFT JOIN persons p ON s.suggested_by_person_id = p.id
+		WHERE ` + where + `
+		ORDER BY (SELECT COUNT(*) FROM watchlist_votes v WHERE v.suggestion_id = s.id) DESC, s.created_at, s.id`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := []*model.Suggestion{}
+	for rows.Next() {
+		suggestion := &model.Suggestion{Voters: []*model.Person{}}
+		movie := &model.Movie{}
+		var personID *uuid.UUID
+		var initial, name *string
+		if err := rows.Scan(
+			&suggestion.ID,
+			&suggestion.MovieID,
+			&suggestion.SuggestedByPersonID,
+			&suggestion.Reason,
+			&suggestion.CreatedAt,
+			&suggestion.PromotedAt,
+			&suggestion.PromotedEntryID,
+			&movie.ID,
+			&movie.CreatedAt,
+			&movie.UpdatedAt,
+			&movie.Title,
+			&movie.ReleaseYear,
+			&movie.PosterURL,
+			&movie.Synopsis,
+			&movie.RuntimeMinutes,
+			&movie.TMDBId,
+			&movie.IMDBId,
+			&movie.MetadataJSON,
+			&personID,
+			&initial,
+			&name,
+		); err != nil {
+			return nil, fmt.Errorf("scan suggestion: %w", err)
+		}
+		suggestion.Movie = movie
+		if personID != nil && initial != nil && name != nil {
+			suggestion.SuggestedBy = &model.Person{ID: *personID, Initial: *initial, Name: *name}
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate suggestions: %w", err)
+	}
+
+	if err := r.loadVoters(ctx, suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// loadVoters fills in who upvoted each suggestion
+func (r *WatchlistRepository) loadVoters(ctx context.Context, suggestions []*model.Suggestion) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	byID := make(map[uuid.UUID]*model.Suggestion, len(suggestions))
+	ids := make([]uuid.UUID, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		byID[suggestion.ID] = suggestion
+		ids = append(ids, suggestion.ID)
+	}
+
+	query := `
+		SELECT v.suggestion_id, p.id, p.initial, p.name
+		FROM watchlist_votes v
+		JOIN persons p ON v.person_id = p.id
+		WHERE v.suggestion_id = ANY($1)
+		ORDER BY p.position`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("get suggestion voters: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var suggestionID uuid.UUID
+		person := &model.Person{}
+		if err := rows.Scan(&suggestionID, &person.ID, &person.Initial, &person.Name); err != nil {
+			return fmt.Errorf("scan voter: %w", err)
+		}
+		byID[suggestionID].Voters = append(byID[suggestionID].Voters, person)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate voters: %w", err)
+	}
+	return nil
+}
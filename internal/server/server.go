@@ -6,6 +6,8 @@ import (
 	"github.com/drywaters/seenema/internal/config"
 	"github.com/drywaters/seenema/internal/handler"
 	"github.com/drywaters/seenema/internal/middleware"
+	"github.com/drywaters/seenema/internal/model"
+	"github.com/drywaters/seenema/internal/openapi"
 	"github.com/drywaters/seenema/internal/repository"
 	"github.com/drywaters/seenema/internal/tmdb"
 	"github.com/go-chi/chi/v5"
@@ -14,12 +16,21 @@ import (
 
 // Server represents the HTTP server
 type Server struct {
-	cfg        *config.Config
-	movieRepo  *repository.MovieRepository
-	entryRepo  *repository.EntryRepository
-	personRepo *repository.PersonRepository
-	ratingRepo *repository.RatingRepository
-	tmdbClient *tmdb.Client
+	cfg           *config.Config
+	movieRepo     *repository.MovieRepository
+	entryRepo     *repository.EntryRepository
+	groupRepo     *repository.GroupRepository
+	personRepo    *repository.PersonRepository
+	ratingRepo    *repository.RatingRepository
+	sessionRepo   *repository.SessionRepository
+	apiTokenRepo  *repository.APITokenRepository
+	statsRepo     *repository.StatsRepository
+	trashRepo     *repository.TrashRepository
+	auditRepo     *repository.AuditRepository
+	viewingRepo   *repository.ViewingRepository
+	watchlistRepo *repository.WatchlistRepository
+	exportRepo    *repository.ExportRepository
+	tmdbClient    *tmdb.Client
 }
 
 // New creates a new Server
@@ -27,22 +38,64 @@ func New(
 	cfg *config.Config,
 	movieRepo *repository.MovieRepository,
 	entryRepo *repository.EntryRepository,
+	groupRepo *repository.GroupRepository,
 	personRepo *repository.PersonRepository,
 	ratingRepo *repository.RatingRepository,
+	sessionRepo *repository.SessionRepository,
+	apiTokenRepo *repository.APITokenRepository,
+	statsRepo *repository.StatsRepository,
+	trashRepo *repository.TrashRepository,
+	auditRepo *repository.AuditRepository,
+	viewingRepo *repository.ViewingRepository,
+	watchlistRepo *repository.WatchlistRepository,
+	exportRepo *repository.ExportRepository,
 	tmdbClient *tmdb.Client,
 ) *Server {
 	return &Server{
-		cfg:        cfg,
-		movieRepo:  movieRepo,
-		entryRepo:  entryRepo,
-		personRepo: personRepo,
-		ratingRepo: ratingRepo,
-		tmdbClient: tmdbClient,
+		cfg:           cfg,
+		movieRepo:     movieRepo,
+		entryRepo:     entryRepo,
+		groupRepo:     groupRepo,
+		personRepo:    personRepo,
+		ratingRepo:    ratingRepo,
+		sessionRepo:   sessionRepo,
+		apiTokenRepo:  apiTokenRepo,
+		statsRepo:     statsRepo,
+		trashRepo:     trashRepo,
+		auditRepo:     auditRepo,
+		viewingRepo:   viewingRepo,
+		watchlistRepo: watchlistRepo,
+		exportRepo:    exportRepo,
+		tmdbClient:    tmdbClient,
 	}
 }
 
+// rootStaticFiles are served from static/ at the site root
+var rootStaticFiles = []string{
+	"favicon.ico",
+	"apple-touch-icon.png",
+	"favicon-16x16.png",
+	"favicon-32x32.png",
+	"android-chrome-192x192.png",
+	"android-chrome-512x512.png",
+	"site.webmanifest",
+}
+
 // Router returns the configured chi router
 func (s *Server) Router() http.Handler {
+	return s.routes()
+}
+
+// CheckOpenAPI returns an error if the registered routes and the OpenAPI spec disagree
+func (s *Server) CheckOpenAPI() error {
+	ignore := []string{"/static/*"}
+	for _, file := range rootStaticFiles {
+		ignore = append(ignore, "/"+file)
+	}
+	return openapi.CheckRoutes(s.routes(), openapi.Spec(), ignore...)
+}
+
+func (s *Server) routes() *chi.Mux {
 	r := chi.NewRouter()
 
 	// Middleware
@@ -57,15 +110,7 @@ func (s *Server) Router() http.Handler {
 	r.Handle("/static/*", withCacheControl(staticCacheControl, http.StripPrefix("/static/", fileServer)))
 
 	// Root-level static files
-	for _, file := range []string{
-		"favicon.ico",
-		"apple-touch-icon.png",
-		"favicon-16x16.png",
-		"favicon-32x32.png",
-		"android-chrome-192x192.png",
-		"android-chrome-512x512.png",
-		"site.webmanifest",
-	} {
+	for _, file := range rootStaticFiles {
 		r.Get("/"+file, serveStaticFile("static/"+file))
 	}
 
@@ -76,50 +121,267 @@ func (s *Server) Router() http.Handler {
 		_, _ = w.Write([]byte("ok"))
 	})
 
+	// OpenAPI spec for every route below
+	r.Get(openapi.Path, openapi.ServeSpec)
+
 	// Auth handlers
-	authHandler := handler.NewAuthHandler(s.cfg.APIToken, s.cfg.SecureCookies)
+	authHandler := handler.NewAuthHandler(s.personRepo, s.sessionRepo, s.cfg.SecureCookies)
 	r.Get("/login", authHandler.LoginPage)
 	r.Post("/login", authHandler.Login)
 	r.Post("/logout", authHandler.Logout)
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(s.cfg.APIToken, s.cfg.SecureCookies))
+		r.Use(middleware.Auth(s.cfg.APIToken, s.sessionRepo, s.apiTokenRepo, s.cfg.SecureCookies))
+
+		dashboardHandler := handler.NewDashboardHandler(s.entryRepo, s.groupRepo, s.personRepo, s.cfg.PickerRotation)
+		movieHandler := handler.NewMovieHandler(s.movieRepo, s.entryRepo, s.personRepo, s.tmdbClient, s.cfg.PickerRotation)
+		entryHandler := handler.NewEntryHandler(s.entryRepo, s.groupRepo, s.personRepo)
+		ratingHandler := handler.NewRatingHandler(s.ratingRepo, s.entryRepo, s.personRepo)
+		personHandler := handler.NewPersonHandler(s.personRepo)
+		sessionHandler := handler.NewSessionHandler(s.sessionRepo, s.cfg.SecureCookies)
+		apiTokenHandler := handler.NewAPITokenHandler(s.apiTokenRepo)
+		statsHandler := handler.NewStatsHandler(s.statsRepo)
+		searchHandler := handler.NewSearchHandler(s.entryRepo, s.groupRepo, s.personRepo)
+		libraryHandler := handler.NewLibraryHandler(s.entryRepo, s.personRepo)
+		groupHandler := handler.NewGroupHandler(s.groupRepo)
+		trashHandler := handler.NewTrashHandler(s.trashRepo, s.cfg.TrashRetention)
+		historyHandler := handler.NewHistoryHandler(s.auditRepo, s.personRepo)
+		viewingHandler := handler.NewViewingHandler(s.viewingRepo, s.entryRepo, s.personRepo)
+		watchlistHandler := handler.NewWatchlistHandler(s.watchlistRepo, s.movieRepo, s.entryRepo, s.groupRepo, s.personRepo, s.tmdbClient)
+		exportHandler := handler.NewExportHandler(s.exportRepo, s.personRepo)
+		importHandler := handler.NewImportHandler(s.movieRepo, s.entryRepo, s.ratingRepo, s.viewingRepo, s.groupRepo, s.personRepo, s.tmdbClient)
+
+		// Browser pages, partials and account management (not available to scoped API tokens)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RejectScopedTokens)
+
+			// Dashboard
+			r.Get("/", dashboardHandler.DashboardPage)
+			r.Get("/dashboard-content", dashboardHandler.DashboardContent)
+
+			// Movie detail page
+			r.Get("/movies/{id}", movieHandler.MovieDetailPage)
+
+			// Partials
+			r.Get("/partials/group/{num}", entryHandler.GroupPartial)
+			r.Get("/partials/rating-form/{entryId}/{personId}", ratingHandler.RatingForm)
+			r.Get("/partials/search-results", searchHandler.SearchResults)
+			r.Get("/partials/library", libraryHandler.LibraryRows)
+			r.Get("/partials/entry-history/{id}", historyHandler.EntryHistory)
 
-		// Dashboard
-		dashboardHandler := handler.NewDashboardHandler(s.entryRepo, s.personRepo)
-		r.Get("/", dashboardHandler.DashboardPage)
-		r.Get("/dashboard-content", dashboardHandler.DashboardContent)
+			// Search and browse
+			r.Get("/search", searchHandler.SearchPage)
+			r.Get("/library", libraryHandler.LibraryPage)
 
-		// Movie detail page
-		movieHandler := handler.NewMovieHandler(s.movieRepo, s.entryRepo, s.personRepo, s.tmdbClient)
-		r.Get("/movies/{id}", movieHandler.MovieDetailPage)
+			// Groups
+			r.Get("/groups", groupHandler.GroupsPage)
+
+			// Trash
+			r.Get("/trash", trashHandler.TrashPage)
+
+			// Watchlist
+			r.Get("/watchlist", watchlistHandler.WatchlistPage)
+
+			// Import
+			r.Get("/import", importHandler.ImportPage)
+
+			// Stats
+			r.Get("/stats", statsHandler.StatsPage)
+			r.Get("/stats/taste", statsHandler.TastePage)
+
+			// Settings
+			r.Get("/settings", personHandler.SettingsPage)
+
+			// Account
+			r.Post("/api/account/password", authHandler.ChangePassword)
+
+			// Sessions
+			r.Get("/settings/sessions", sessionHandler.SessionsPage)
+			r.Post("/api/sessions/revoke-all", sessionHandler.RevokeAll)
+			r.Delete("/api/sessions/{id}", sessionHandler.Revoke)
+
+			// API tokens
+			r.Get("/settings/tokens", apiTokenHandler.TokensPage)
+			r.Post("/api/tokens", apiTokenHandler.Create)
+			r.Delete("/api/tokens/{id}", apiTokenHandler.Revoke)
+		})
+
+		// Whole-collection downloads include every entry and score
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeEntriesRead), middleware.RequireScope(model.ScopeRatingsRead))
+			r.Get("/export.csv", exportHandler.ExportCSV)
+			r.Get("/export.json", exportHandler.ExportJSON)
+		})
+
+		// Imports add movies, entries, viewings and ratings
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeEntriesWrite), middleware.RequireScope(model.ScopeRatingsWrite))
+			r.Post("/api/import", importHandler.Import)
+		})
 
 		// TMDB API endpoints
-		r.Get("/api/tmdb/search", movieHandler.SearchTMDB)
-		r.Post("/api/tmdb/add", movieHandler.AddFromTMDB)
+		r.With(middleware.RequireScope(model.ScopeMoviesRead)).Get("/api/tmdb/search", movieHandler.SearchTMDB)
+		r.With(middleware.RequireScope(model.ScopeEntriesWrite)).Post("/api/tmdb/add", movieHandler.AddFromTMDB)
+		r.With(middleware.RequireScope(model.ScopeMoviesWrite)).Post("/api/movies/{id}/restore", trashHandler.RestoreMovie)
+
+		// Watchlist API endpoints
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeMoviesWrite))
+			r.Post("/api/watchlist", watchlistHandler.Suggest)
+			r.Delete("/api/watchlist/{id}", watchlistHandler.Withdraw)
+			r.Put("/api/watchlist/{id}/votes/{personId}", watchlistHandler.Vote)
+			r.Delete("/api/watchlist/{id}/votes/{personId}", watchlistHandler.Unvote)
+		})
+		r.With(middleware.RequireScope(model.ScopeEntriesWrite)).Post("/api/watchlist/{id}/promote", watchlistHandler.Promote)
 
 		// Entry API endpoints
-		entryHandler := handler.NewEntryHandler(s.entryRepo, s.personRepo)
-		r.Put("/api/entries/{id}", entryHandler.Update)
-		r.Delete("/api/entries/{id}", entryHandler.Delete)
-		r.Post("/api/entries/{id}/watched", entryHandler.MarkWatched)
-		r.Delete("/api/entries/{id}/watched", entryHandler.ClearWatched)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeEntriesWrite))
+			r.Put("/api/entries/{id}", entryHandler.Update)
+			r.Delete("/api/entries/{id}", entryHandler.Delete)
+			r.Post("/api/entries/{id}/watched", viewingHandler.MarkWatched)
+			r.Delete("/api/entries/{id}/watched", viewingHandler.ClearWatched)
+			r.Post("/api/entries/{id}/viewings", viewingHandler.AddViewing)
+			r.Delete("/api/entries/{id}/viewings/{viewingId}", viewingHandler.DeleteViewing)
+			r.Put("/api/entries/{id}/attendance/{personId}", viewingHandler.SetAttendance)
+			r.Post("/api/entries/{id}/move", entryHandler.Move)
+			r.Post("/api/entries/{id}/copy", entryHandler.Copy)
+			r.Post("/api/entries/{id}/restore", trashHandler.RestoreEntry)
+			r.Post("/api/groups/{num}/reorder", entryHandler.Reorder)
+		})
 
-		// Group partial and reordering
-		r.Get("/partials/group/{num}", entryHandler.GroupPartial)
-		r.Post("/api/groups/{num}/reorder", entryHandler.Reorder)
+		// Group management
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeEntriesWrite))
+			r.Post("/api/groups", groupHandler.Create)
+			r.Put("/api/groups/{num}", groupHandler.Update)
+			r.Post("/api/groups/{num}/current", groupHandler.SetCurrent)
+			r.Post("/api/groups/{num}/archive", groupHandler.Archive)
+			r.Delete("/api/groups/{num}/archive", groupHandler.Unarchive)
+		})
 
 		// Rating API endpoints
-		ratingHandler := handler.NewRatingHandler(s.ratingRepo, s.entryRepo, s.personRepo)
-		r.Post("/api/ratings", ratingHandler.SaveRating)
-		r.Delete("/api/ratings/{personId}/{entryId}", ratingHandler.DeleteRating)
-		r.Get("/partials/rating-form/{entryId}/{personId}", ratingHandler.RatingForm)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopeRatingsWrite))
+			r.Post("/api/ratings", ratingHandler.SaveRating)
+			r.Delete("/api/ratings/{personId}/{entryId}", ratingHandler.DeleteRating)
+			r.Post("/api/ratings/{personId}/{entryId}/restore", trashHandler.RestoreRating)
+		})
+
+		// Family member management
+		r.With(middleware.RequireScope(model.ScopePersonsRead)).Get("/api/persons", personHandler.List)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireScope(model.ScopePersonsWrite))
+			r.Post("/api/persons", personHandler.Create)
+			r.Post("/api/persons/reorder", personHandler.Reorder)
+			r.Put("/api/persons/{id}", personHandler.Update)
+			r.Post("/api/persons/{id}/archive", personHandler.Archive)
+			r.Delete("/api/persons/{id}/archive", personHandler.Unarchive)
+		})
+
+		// Versioned JSON API
+		r.Route("/api/v1", func(r chi.Router) {
+			s.apiRoutes(r, statsHandler, searchHandler, libraryHandler, historyHandler)
+		})
 	})
 
 	return r
 }
 
+// apiRoutes mounts the versioned JSON API
+func (s *Server) apiRoutes(r chi.Router, statsHandler *handler.StatsHandler, searchHandler *handler.SearchHandler, libraryHandler *handler.LibraryHandler, historyHandler *handler.HistoryHandler) {
+	api := handler.NewAPIHandler(s.movieRepo, s.entryRepo, s.groupRepo, s.personRepo, s.ratingRepo, s.trashRepo, s.viewingRepo, s.watchlistRepo, s.tmdbClient, s.cfg.PickerRotation, s.cfg.TrashRetention)
+
+	r.Use(middleware.NegotiateJSON)
+	r.NotFound(api.NotFound)
+	r.MethodNotAllowed(api.MethodNotAllowed)
+
+	// Movies
+	r.With(middleware.RequireScope(model.ScopeMoviesRead)).Get("/movies", api.ListMovies)
+	r.With(middleware.RequireScope(model.ScopeMoviesRead)).Get("/movies/search", api.SearchMovies)
+	r.With(middleware.RequireScope(model.ScopeMoviesRead)).Get("/movies/{id}", api.GetMovie)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeMoviesWrite))
+		r.Post("/movies", api.ImportMovie)
+		r.Delete("/movies/{id}", api.DeleteMovie)
+		r.Post("/movies/{id}/restore", api.RestoreMovie)
+	})
+
+	// Watchlist
+	r.With(middleware.RequireScope(model.ScopeMoviesRead)).Get("/watchlist", api.ListWatchlist)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeMoviesWrite))
+		r.Post("/watchlist", api.SuggestMovie)
+		r.Delete("/watchlist/{id}", api.WithdrawSuggestion)
+		r.Put("/watchlist/{id}/votes/{personId}", api.VoteForSuggestion)
+		r.Delete("/watchlist/{id}/votes/{personId}", api.UnvoteSuggestion)
+	})
+	r.With(middleware.RequireScope(model.ScopeEntriesWrite)).Post("/watchlist/{id}/promote", api.PromoteSuggestion)
+
+	// Entries and groups
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeEntriesRead))
+		r.Get("/entries/{id}", api.GetEntry)
+		r.Get("/search", searchHandler.Search)
+		r.Get("/library", libraryHandler.Library)
+		r.Get("/library/facets", libraryHandler.Facets)
+		r.Get("/groups", api.ListGroups)
+		r.Get("/groups/{num}", api.GetGroup)
+		r.Get("/groups/{num}/entries", api.ListGroupEntries)
+		r.Get("/groups/{num}/picks", api.ListGroupPicks)
+		r.Get("/groups/{num}/next-picker", api.GetNextPicker)
+		r.Get("/trash", api.ListTrash)
+		r.Get("/entries/{id}/history", historyHandler.History)
+		r.Get("/entries/{id}/viewings", api.ListViewings)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeEntriesWrite))
+		r.Post("/entries", api.CreateEntry)
+		r.Patch("/entries/{id}", api.UpdateEntry)
+		r.Delete("/entries/{id}", api.DeleteEntry)
+		r.Post("/entries/{id}/move", api.MoveEntry)
+		r.Post("/entries/{id}/copy", api.CopyEntry)
+		r.Post("/entries/{id}/restore", api.RestoreEntry)
+		r.Put("/entries/{id}/watched", api.SetWatched)
+		r.Delete("/entries/{id}/watched", api.ClearWatched)
+		r.Post("/entries/{id}/viewings", api.CreateViewing)
+		r.Delete("/entries/{id}/viewings/{viewingId}", api.DeleteViewing)
+		r.Put("/entries/{id}/attendance/{personId}", api.SetAttendance)
+		r.Post("/groups", api.CreateGroup)
+		r.Patch("/groups/{num}", api.UpdateGroup)
+		r.Put("/groups/{num}/order", api.ReorderGroup)
+	})
+
+	// Ratings
+	r.With(middleware.RequireScope(model.ScopeRatingsRead)).Get("/entries/{id}/ratings", api.ListRatings)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeRatingsWrite))
+		r.Put("/entries/{id}/ratings/{personId}", api.SetRating)
+		r.Delete("/entries/{id}/ratings/{personId}", api.DeleteRating)
+		r.Post("/entries/{id}/ratings/{personId}/restore", api.RestoreRating)
+	})
+
+	// Stats
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopeRatingsRead))
+		r.Get("/stats", statsHandler.Stats)
+		r.Get("/stats/taste", statsHandler.Taste)
+	})
+
+	// Persons
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopePersonsRead))
+		r.Get("/persons", api.ListPersons)
+		r.Get("/persons/{id}", api.GetPerson)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireScope(model.ScopePersonsWrite))
+		r.Post("/persons", api.CreatePerson)
+		r.Patch("/persons/{id}", api.UpdatePerson)
+	})
+}
+
 func serveStaticFile(path string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, path)
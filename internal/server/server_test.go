@@ -0,0 +1,16 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/drywaters/seenema/internal/config"
+)
+
+// TestRoutesMatchOpenAPI fails when a route is added without a spec entry,
+// or a spec entry is left behind for a removed route
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	srv := New(&config.Config{APIToken: "test"}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if err := srv.CheckOpenAPI(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -98,30 +98,58 @@ func (c *Client) Search(ctx context.Context, query string) (*SearchResponse, err
 		url.QueryEscape(query),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+	var result SearchResponse
+	if _, err := c.get(ctx, endpoint, &result); err != nil {
+		return nil, err
 	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
-	}
-	defer resp.Body.Close()
+	return &result, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB API error: %d - %s", resp.StatusCode, string(body))
+// SearchYear searches for movies by title first released in the given year
+func (c *Client) SearchYear(ctx context.Context, query string, year int) (*SearchResponse, error) {
+	if query == "" {
+		return &SearchResponse{}, nil
 	}
 
+	endpoint := fmt.Sprintf("%s/search/movie?api_key=%s&query=%s&primary_release_year=%d&include_adult=false",
+		baseURL,
+		c.apiKey,
+		url.QueryEscape(query),
+		year,
+	)
+
 	var result SearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if _, err := c.get(ctx, endpoint, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
+// FindByIMDbID looks a movie up by its IMDb ID, e.g. "tt0111161".
+// Returns nil if TMDB has no movie with that ID.
+func (c *Client) FindByIMDbID(ctx context.Context, imdbID string) (*SearchResult, error) {
+	endpoint := fmt.Sprintf("%s/find/%s?api_key=%s&external_source=imdb_id",
+		baseURL,
+		url.PathEscape(imdbID),
+		c.apiKey,
+	)
+
+	var result struct {
+		MovieResults []SearchResult `json:"movie_results"`
+	}
+	found, err := c.get(ctx, endpoint, &result)
+	if err != nil {
+		return nil, err
+	}
+	if !found || len(result.MovieResults) == 0 {
+		return nil, nil
+	}
+
+	return &result.MovieResults[0], nil
+}
+
 // GetMovie fetches detailed movie information by TMDB ID
 func (c *Client) GetMovie(ctx context.Context, tmdbID int) (*MovieDetails, error) {
 	endpoint := fmt.Sprintf("%s/movie/%d?api_key=%s",
@@ -130,32 +158,43 @@ func (c *Client) GetMovie(ctx context.Context, tmdbID int) (*MovieDetails, error
 		c.apiKey,
 	)
 
+	var result MovieDetails
+	found, err := c.get(ctx, endpoint, &result)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// get fetches endpoint and decodes the JSON response into out. Returns false
+// without an error if TMDB responds 404.
+func (c *Client) get(ctx context.Context, endpoint string, out any) (bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return false, fmt.Errorf("create request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		return false, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, nil
+		return false, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TMDB API error: %d - %s", resp.StatusCode, string(body))
+		return false, fmt.Errorf("TMDB API error: %d - %s", resp.StatusCode, string(body))
 	}
 
-	var result MovieDetails
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode response: %w", err)
 	}
 
-	return &result, nil
+	return true, nil
 }
 
 // PosterURL constructs the full URL for a poster image
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Persons are now managed from the settings page instead of the seed in 003.
+ALTER TABLE persons ADD COLUMN position INTEGER;
+ALTER TABLE persons ADD COLUMN archived_at TIMESTAMPTZ;
+ALTER TABLE persons ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Initialize positions from the previous display order (model.FamilyInitials),
+-- with any other initials after them alphabetically
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (
+        ORDER BY array_position(ARRAY['D', 'J', 'C', 'A'], initial::TEXT) NULLS LAST, initial
+    ) AS rn
+    FROM persons
+)
+UPDATE persons SET position = ranked.rn
+FROM ranked WHERE persons.id = ranked.id;
+
+ALTER TABLE persons ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE persons
+    ADD CONSTRAINT persons_position_unique UNIQUE (position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE persons DROP CONSTRAINT IF EXISTS persons_position_unique;
+ALTER TABLE persons DROP COLUMN IF EXISTS created_at;
+ALTER TABLE persons DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE persons DROP COLUMN IF EXISTS position;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Each person can sign in with their own password (bcrypt hash)
+ALTER TABLE persons ADD COLUMN password_hash TEXT;
+
+CREATE TABLE sessions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_hash  BYTEA NOT NULL UNIQUE, -- SHA-256 of the cookie value; the raw token is never stored
+    person_id   UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+-- Index for listing a person's sessions
+CREATE INDEX idx_sessions_person_id ON sessions(person_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sessions;
+ALTER TABLE persons DROP COLUMN IF EXISTS password_hash;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_tokens (
+    id                    UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                  TEXT NOT NULL,
+    token_hash            BYTEA NOT NULL UNIQUE, -- SHA-256 of the bearer token; the raw token is shown once
+    scopes                TEXT[] NOT NULL DEFAULT '{}',
+    created_by_person_id  UUID REFERENCES persons(id) ON DELETE SET NULL,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at            TIMESTAMPTZ, -- NULL = never expires
+    last_used_at          TIMESTAMPTZ
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_tokens;
+-- +goose StatementEnd
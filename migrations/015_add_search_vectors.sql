@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Full-text search: titles rank above genres, genres above synopses.
+-- Genre names come from the TMDB details stored in metadata_json.
+ALTER TABLE movies ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(jsonb_path_query_array(metadata_json, '$.genres[*].name'), '[]'::jsonb)), 'B') ||
+    setweight(to_tsvector('english', COALESCE(synopsis, '')), 'C')
+) STORED;
+
+ALTER TABLE entries ADD COLUMN notes_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(notes, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_movies_search_vector ON movies USING GIN (search_vector);
+CREATE INDEX idx_entries_notes_vector ON entries USING GIN (notes_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_entries_notes_vector;
+DROP INDEX IF EXISTS idx_movies_search_vector;
+ALTER TABLE entries DROP COLUMN IF EXISTS notes_vector;
+ALTER TABLE movies DROP COLUMN IF EXISTS search_vector;
+-- +goose StatementEnd
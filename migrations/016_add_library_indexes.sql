@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Support the library page's filters and keyset pagination
+CREATE INDEX idx_entries_added_at ON entries(added_at, id);
+CREATE INDEX idx_movies_release_year ON movies(release_year) WHERE release_year IS NOT NULL;
+CREATE INDEX idx_movies_runtime_minutes ON movies(runtime_minutes) WHERE runtime_minutes IS NOT NULL;
+CREATE INDEX idx_movies_genres ON movies USING GIN ((metadata_json->'genres') jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_movies_genres;
+DROP INDEX IF EXISTS idx_movies_runtime_minutes;
+DROP INDEX IF EXISTS idx_movies_release_year;
+DROP INDEX IF EXISTS idx_entries_added_at;
+-- +goose StatementEnd
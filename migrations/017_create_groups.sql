@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Groups used to exist only as entries.group_number; give them a table of their own
+-- so they can be named, dated, archived and exist before any movie is added.
+CREATE TABLE groups (
+    number       INTEGER PRIMARY KEY CHECK (number >= 1),
+    name         TEXT,
+    theme        TEXT,
+    description  TEXT,
+    starts_on    DATE,
+    ends_on      DATE,
+    is_current   BOOLEAN NOT NULL DEFAULT FALSE,
+    archived_at  TIMESTAMPTZ, -- NULL = active
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT groups_date_range CHECK (starts_on IS NULL OR ends_on IS NULL OR ends_on >= starts_on)
+);
+
+-- At most one group is current
+CREATE UNIQUE INDEX idx_groups_current ON groups(is_current) WHERE is_current;
+
+-- Backfill from existing entries; the highest group was the implicit current one
+INSERT INTO groups (number)
+SELECT DISTINCT group_number FROM entries;
+
+UPDATE groups SET is_current = TRUE
+WHERE number = (SELECT MAX(number) FROM groups);
+
+ALTER TABLE entries
+    ADD CONSTRAINT entries_group_number_fkey FOREIGN KEY (group_number) REFERENCES groups(number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE entries DROP CONSTRAINT IF EXISTS entries_group_number_fkey;
+DROP TABLE IF EXISTS groups;
+-- +goose StatementEnd
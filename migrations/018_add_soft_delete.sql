@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Deleting a movie, entry or rating moves it to the trash instead of removing it;
+-- trashed rows are purged once the retention period has passed.
+ALTER TABLE movies ADD COLUMN deleted_at TIMESTAMPTZ; -- NULL = active
+ALTER TABLE entries ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE ratings ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Trashed entries must not block re-adding the movie or renumbering the group
+ALTER TABLE entries DROP CONSTRAINT IF EXISTS entries_movie_group_unique;
+ALTER TABLE entries DROP CONSTRAINT IF EXISTS entries_group_position_unique;
+CREATE UNIQUE INDEX entries_movie_group_unique ON entries(movie_id, group_number) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX entries_group_position_unique ON entries(group_number, position) WHERE deleted_at IS NULL;
+
+-- Support the trash view and the purge job
+CREATE INDEX idx_movies_deleted_at ON movies(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_entries_deleted_at ON entries(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_ratings_deleted_at ON ratings(deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The trash can't be represented without the columns, so empty it first
+DELETE FROM movies WHERE deleted_at IS NOT NULL;
+DELETE FROM entries WHERE deleted_at IS NOT NULL;
+DELETE FROM ratings WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_ratings_deleted_at;
+DROP INDEX IF EXISTS idx_entries_deleted_at;
+DROP INDEX IF EXISTS idx_movies_deleted_at;
+
+DROP INDEX IF EXISTS entries_group_position_unique;
+DROP INDEX IF EXISTS entries_movie_group_unique;
+ALTER TABLE entries
+    ADD CONSTRAINT entries_movie_group_unique UNIQUE (movie_id, group_number);
+ALTER TABLE entries
+    ADD CONSTRAINT entries_group_position_unique UNIQUE (group_number, position);
+
+ALTER TABLE ratings DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE entries DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE movies DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
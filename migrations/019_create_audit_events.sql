@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per changed movie, entry, rating or person, written in the same
+-- transaction as the change. Snapshots are the row as JSON before and after
+-- (NULL when it didn't exist), minus search vectors, TMDB metadata and password hashes.
+CREATE TABLE audit_events (
+    id               BIGSERIAL PRIMARY KEY,
+    occurred_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    action           TEXT NOT NULL, -- create, update, delete, restore, move, reorder, ...
+    entity_type      TEXT NOT NULL, -- movie, entry, rating, person or viewing; checked since 023
+    entity_id        UUID NOT NULL,
+    entry_id         UUID,          -- The entry an entry, rating or viewing event belongs to; no FK so history outlives purges
+    actor_kind       TEXT NOT NULL, -- person, api_token, household_token or system
+    actor_name       TEXT NOT NULL, -- Person or token name at the time
+    actor_person_id  UUID REFERENCES persons(id) ON DELETE SET NULL,
+    actor_token_id   UUID REFERENCES api_tokens(id) ON DELETE SET NULL,
+    request_id       TEXT,
+    before           JSONB,
+    after            JSONB
+);
+
+CREATE INDEX idx_audit_events_entry ON audit_events(entry_id, occurred_at DESC) WHERE entry_id IS NOT NULL;
+CREATE INDEX idx_audit_events_entity ON audit_events(entity_type, entity_id, occurred_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every score a rating has had, oldest first. ratings.score stays the current
+-- score so reading it doesn't need this table.
+CREATE TABLE rating_revisions (
+    id          BIGSERIAL PRIMARY KEY,
+    rating_id   UUID NOT NULL REFERENCES ratings(id) ON DELETE CASCADE,
+    score       DECIMAL(3,1) NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rating_revisions_rating ON rating_revisions(rating_id, created_at);
+
+-- Existing ratings start their history with the score they have now
+INSERT INTO rating_revisions (rating_id, score, created_at)
+SELECT id, score, updated_at FROM ratings;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rating_revisions;
+-- +goose StatementEnd
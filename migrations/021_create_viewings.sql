@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Each time an entry was watched. entries.watched_at is kept as the date of
+-- the first viewing so existing filters, sorts and stats keep working.
+CREATE TABLE viewings (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id    UUID NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+    watched_on  DATE NOT NULL,
+    location    TEXT,          -- Where or on what, e.g. "Cinema" or "Netflix"
+    note        TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_viewings_entry ON viewings(entry_id, watched_on);
+
+-- Who was present at a viewing
+CREATE TABLE viewing_attendees (
+    viewing_id  UUID NOT NULL REFERENCES viewings(id) ON DELETE CASCADE,
+    person_id   UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    PRIMARY KEY (viewing_id, person_id)
+);
+
+CREATE INDEX idx_viewing_attendees_person ON viewing_attendees(person_id);
+
+-- A rating can say which viewing it was given after
+ALTER TABLE ratings ADD COLUMN viewing_id UUID REFERENCES viewings(id) ON DELETE SET NULL;
+
+-- Existing watched dates become one viewing each, attended by everyone active,
+-- which is what the app assumed until now; ratings attach to it
+INSERT INTO viewings (entry_id, watched_on, created_at)
+SELECT id, watched_at, added_at FROM entries WHERE watched_at IS NOT NULL;
+
+INSERT INTO viewing_attendees (viewing_id, person_id)
+SELECT v.id, p.id FROM viewings v CROSS JOIN persons p WHERE p.archived_at IS NULL;
+
+UPDATE ratings r SET viewing_id = v.id FROM viewings v WHERE v.entry_id = r.entry_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE ratings DROP COLUMN IF EXISTS viewing_id;
+DROP TABLE IF EXISTS viewing_attendees;
+DROP TABLE IF EXISTS viewings;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Movies someone suggested for the family, kept apart from the groups until
+-- they are promoted into one
+CREATE TABLE watchlist_suggestions (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    movie_id                UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    suggested_by_person_id  UUID REFERENCES persons(id) ON DELETE SET NULL,
+    reason                  TEXT,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    promoted_at             TIMESTAMPTZ,    -- NULL = still on the watchlist
+    promoted_entry_id       UUID REFERENCES entries(id) ON DELETE SET NULL
+);
+
+-- A movie can only be on the watchlist once at a time
+CREATE UNIQUE INDEX idx_watchlist_suggestions_open_movie ON watchlist_suggestions(movie_id) WHERE promoted_at IS NULL;
+
+CREATE TABLE watchlist_votes (
+    suggestion_id  UUID NOT NULL REFERENCES watchlist_suggestions(id) ON DELETE CASCADE,
+    person_id      UUID NOT NULL REFERENCES persons(id) ON DELETE CASCADE,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (suggestion_id, person_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS watchlist_votes;
+DROP TABLE IF EXISTS watchlist_suggestions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keep entity_type to the types model.AuditEntity* lists; add new ones here
+ALTER TABLE audit_events
+    ADD CONSTRAINT audit_events_entity_type_check
+    CHECK (entity_type IN ('movie', 'entry', 'rating', 'person', 'viewing'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE audit_events
+    DROP CONSTRAINT IF EXISTS audit_events_entity_type_check;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+// Package migrations embeds the goose SQL migrations so the binary can apply
+// them itself
+package migrations
+
+import "embed"
+
+// FS holds every migration, named NNN_description.sql
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,7 +1,13 @@
-// Drag and drop functionality for movie reordering within groups
+// Drag and drop functionality for movie reordering within groups, and for
+// moving movies between groups (hold Alt or Ctrl while dropping to copy)
 (function() {
     'use strict';
 
+    // The item being dragged and the grid it started in, shared by all grids
+    let draggedItem = null;
+    let sourceGrid = null;
+    let copyOnDrop = false;
+
     // Initialize drag and drop for all sortable grids
     function initDragDrop() {
         document.querySelectorAll('.sortable-grid').forEach(initGrid);
@@ -11,8 +17,6 @@
         const groupNum = grid.dataset.group;
         if (!groupNum) return;
 
-        let draggedItem = null;
-
         grid.querySelectorAll('.draggable-item').forEach(item => {
             if (item.dataset.dndBound === 'true') {
                 return;
@@ -41,10 +45,12 @@
             if (!targetItem || !grid.contains(targetItem)) return;
 
             draggedItem = targetItem;
+            sourceGrid = grid;
+            copyOnDrop = false;
             draggedItem.classList.add('dragging');
 
             if (e.dataTransfer) {
-                e.dataTransfer.effectAllowed = 'move';
+                e.dataTransfer.effectAllowed = 'copyMove';
                 e.dataTransfer.setData('text/plain', targetItem.dataset.entryId || '');
                 if (e.target.classList && e.target.classList.contains('drag-handle')) {
                     e.dataTransfer.setDragImage(targetItem, 20, 20);
@@ -55,21 +61,40 @@
         grid.addEventListener('dragend', function(e) {
             if (!draggedItem) return;
 
-            draggedItem.classList.remove('dragging');
+            const item = draggedItem;
+            const fromGrid = sourceGrid;
+            const toGrid = item.closest('.sortable-grid');
+            item.classList.remove('dragging');
             draggedItem = null;
+            sourceGrid = null;
+
+            if (!toGrid) return;
+            if (toGrid === fromGrid) {
+                // Save the new order
+                saveOrder(toGrid, toGrid.dataset.group);
+                return;
+            }
 
-            // Save the new order
-            saveOrder(grid, groupNum);
+            const position = Array.from(toGrid.querySelectorAll('.draggable-item')).indexOf(item) + 1;
+            placeEntry(item.dataset.entryId, fromGrid.dataset.group, toGrid.dataset.group, position, copyOnDrop);
         });
 
         grid.addEventListener('dragover', function(e) {
             if (!draggedItem) return;
 
             e.preventDefault();
-            e.dataTransfer.dropEffect = 'move';
+            copyOnDrop = grid !== sourceGrid && (e.altKey || e.ctrlKey);
+            e.dataTransfer.dropEffect = copyOnDrop ? 'copy' : 'move';
 
             const overItem = e.target.closest('.draggable-item');
-            if (!overItem || overItem === draggedItem) return;
+            if (!overItem) {
+                // Dropping into an empty group, or past the last card
+                if (!grid.querySelector('.draggable-item') && draggedItem.parentNode !== grid) {
+                    grid.appendChild(draggedItem);
+                }
+                return;
+            }
+            if (overItem === draggedItem) return;
 
             const rect = overItem.getBoundingClientRect();
             const midX = rect.left + rect.width / 2;
@@ -83,11 +108,6 @@
             }
         });
 
-        // Handle drop on the grid itself
-        grid.addEventListener('dragover', function(e) {
-            e.preventDefault();
-        });
-
         grid.addEventListener('drop', function(e) {
             e.preventDefault();
         });
@@ -109,19 +129,53 @@
                 throw new Error('Failed to save order');
             }
             // Show success toast via HTMX trigger
-            const event = new CustomEvent('showToast', {
-                detail: { message: 'Order updated!', type: 'success' }
-            });
-            document.body.dispatchEvent(event);
+            showToast('Order updated!', 'success');
         })
         .catch(error => {
             console.error('Error saving order:', error);
-            // Show error toast
-            const event = new CustomEvent('showToast', {
-                detail: { message: 'Failed to save order', type: 'error' }
-            });
-            document.body.dispatchEvent(event);
+            showToast('Failed to save order', 'error');
+        });
+    }
+
+    // placeEntry moves or copies an entry into another group, then reloads
+    // both group sections so counts and positions match the server
+    function placeEntry(entryId, fromGroup, toGroup, position, copy) {
+        fetch('/api/entries/' + entryId + (copy ? '/copy' : '/move'), {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ group_number: parseInt(toGroup, 10), position: position })
+        })
+        .then(response => {
+            if (!response.ok) {
+                return response.text().then(text => {
+                    throw new Error(text.trim() || 'Failed to move movie');
+                });
+            }
+            showToast(copy ? 'Movie copied!' : 'Movie moved!', 'success');
+        })
+        .catch(error => {
+            console.error('Error moving entry:', error);
+            showToast(error.message, 'error');
+        })
+        .finally(() => {
+            reloadGroup(fromGroup);
+            reloadGroup(toGroup);
+        });
+    }
+
+    function reloadGroup(groupNum) {
+        const section = document.getElementById('group-' + groupNum);
+        if (!section) return;
+        htmx.ajax('GET', '/partials/group/' + groupNum, { target: section, swap: 'outerHTML' });
+    }
+
+    function showToast(message, type) {
+        const event = new CustomEvent('showToast', {
+            detail: { message: message, type: type }
         });
+        document.body.dispatchEvent(event);
     }
 
     // Initialize on page load